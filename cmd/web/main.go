@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"github.com/src-hunter/internal/api/router"
 	"github.com/src-hunter/internal/database"
+	"github.com/src-hunter/internal/scheduler"
 	"github.com/src-hunter/pkg/config"
 	"github.com/src-hunter/pkg/logger"
 	"go.uber.org/zap"
+	"time"
 )
 
 func main() {
@@ -35,6 +39,20 @@ func main() {
 	asynqClient := asynq.NewClient(redisOpt)
 	defer asynqClient.Close()
 
+	// 启动定时扫描调度器，多实例部署时由 Redis 锁保证只有一个实例派发
+	if cfg.Scheduler.Enabled {
+		rdb := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer rdb.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		interval := time.Duration(cfg.Scheduler.PollInterval) * time.Second
+		go scheduler.NewScheduler(db, asynqClient, rdb, interval).Run(ctx)
+	}
+
 	r := router.SetupRouter(db, asynqClient)
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	logger.Logger.Info("Server is running on ", zap.String("addr", addr))
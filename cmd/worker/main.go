@@ -3,6 +3,8 @@ package main
 import (
 	"github.com/hibiken/asynq"
 	"github.com/src-hunter/internal/database"
+	"github.com/src-hunter/internal/enrich"
+	"github.com/src-hunter/internal/notify"
 	"github.com/src-hunter/internal/worker"
 	"github.com/src-hunter/pkg/config"
 	"github.com/src-hunter/pkg/logger"
@@ -33,18 +35,38 @@ func main() {
 			Concurrency: 50,
 			// 定义不同优先级的队列
 			Queues: map[string]int{
-				"critical": 6,
-				"default":  3,
-				"low":      1,
+				"critical":                6,
+				"default":                 3,
+				notify.QueueNotifications: 2,
+				"low":                     1,
 			},
+			// 通知投递使用指数退避，其余任务沿用默认策略
+			RetryDelayFunc: notify.RetryDelay,
 		},
 	)
 
 	mux := asynq.NewServeMux()
-	taskProcessor := worker.NewTaskProcessor(db, asynq.NewClient(asynq.RedisClientOpt{Addr: cfg.Redis.Addr}))
+	asynqClient := asynq.NewClient(asynq.RedisClientOpt{Addr: cfg.Redis.Addr})
+	defer asynqClient.Close()
+	taskProcessor := worker.NewTaskProcessor(db, asynqClient)
+	taskProcessor.Enricher = enrich.New(db, &cfg.Enrich)
+	defer taskProcessor.Enricher.Close()
 
 	mux.HandleFunc("discovery:subdomain:subfinder", taskProcessor.HandleWorkflowTask)
 	mux.HandleFunc("discovery:webrecon:httpx", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:portscan:nmap", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:portscan:masscan", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:portscan:naabu", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:dns:dnsx", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:tls:tlsx", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:crawl:katana", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:crawl:gospider", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:urls:gau", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("discovery:urls:waybackurls", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("vuln:scan:nuclei", taskProcessor.HandleWorkflowTask)
+	mux.HandleFunc("maintenance:project:delete", taskProcessor.HandleProjectDeleteTask)
+	mux.HandleFunc(notify.TaskTypeWebhookDeliver, notify.NewDeliverer(db).HandleDeliverTask)
+	mux.HandleFunc(notify.TaskTypeChatFlush, notify.NewChatDispatcher(db, asynqClient).HandleFlushTask)
 
 	logger.Logger.Info("Worker已启动，正在等待任务...")
 	if err := srv.Run(mux); err != nil {
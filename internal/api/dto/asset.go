@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// AssetListRequest 定义了资产列表的查询参数，IP归属信息来自离线数据库补充
+type AssetListRequest struct {
+	PaginationRequest
+	// ASN 为自治系统编号，多个值之间用逗号分隔，e.g., "AS13335,AS16509"
+	ASN string `form:"asn"`
+	// Country 为国家代码，多个值之间用逗号分隔，e.g., "CN,US"
+	Country string `form:"country"`
+	// Org 为IP所属组织，包含匹配
+	Org string `form:"org"`
+	// Cloud 为CDN或云服务商，多个值之间用逗号分隔，e.g., "cloudflare,aws"
+	Cloud string `form:"cloud"`
+	// CDN 为 true 时只返回位于CDN/WAF之后的资产，为 false 时排除
+	CDN *bool `form:"cdn"`
+	// CDNProvider 为CDN/WAF服务商，多个值之间用逗号分隔，e.g., "cloudflare,akamai"
+	CDNProvider string `form:"cdnProvider"`
+	// Tag 为标签名称，多个值之间用逗号分隔，打上任一标签即可
+	Tag string `form:"tag"`
+}
+
+// IPMetadataResponse 定义了资产IP的归属信息
+type IPMetadataResponse struct {
+	ASN           string    `json:"asn"`
+	Organization  string    `json:"organization"`
+	CountryCode   string    `json:"countryCode"`
+	City          string    `json:"city"`
+	CloudProvider string    `json:"cloudProvider"`
+	IsCDN         bool      `json:"isCdn"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// AssetResponse 定义了单个资产信息的标准API响应结构
+type AssetResponse struct {
+	ID           uint      `json:"id"`
+	IP           string    `json:"ip"`
+	Port         int       `json:"port"`
+	Protocol     string    `json:"protocol"`
+	Transport    string    `json:"transport"`
+	Product      string    `json:"product"`
+	Version      string    `json:"version"`
+	HostState    string    `json:"hostState"`
+	Title        string    `json:"title"`
+	WebServer    string    `json:"webServer"`
+	Technologies []string  `json:"technologies"`
+	Source       string    `json:"source"`
+	CDNProvider  string    `json:"cdnProvider"`
+	FirstSeenAt  time.Time `json:"firstSeenAt"`
+	LastSeenAt   time.Time `json:"lastSeenAt"`
+	IsGone       bool      `json:"isGone"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Tags         []string  `json:"tags,omitempty"`
+	// IPMetadata 为IP的归属信息，尚未补充时为空
+	IPMetadata *IPMetadataResponse `json:"ipMetadata,omitempty"`
+}
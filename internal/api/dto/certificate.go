@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// CertificateListRequest 定义了证书列表的查询参数
+type CertificateListRequest struct {
+	PaginationRequest
+	AssetID     uint   `form:"assetId"`
+	Host        string `form:"host"`
+	Fingerprint string `form:"fingerprint"`
+	// ExpiringWithin 只返回在指定天数内过期 (含已过期) 的证书，0 表示不过滤。
+	// 过滤时同一 (IP, 端口, 主机名) 只检查最近一次观测到的证书
+	ExpiringWithin int   `form:"expiringWithin" binding:"omitempty,min=0,max=3650"`
+	Mismatched     *bool `form:"mismatched"`
+	SelfSigned     *bool `form:"selfSigned"`
+}
+
+// CertificateIssueRequest 定义了证书问题列表的查询参数
+type CertificateIssueRequest struct {
+	PaginationRequest
+	// Days 为即将过期的判定窗口 (天)，默认30天
+	Days int `form:"days,default=30" binding:"min=0,max=3650"`
+}
+
+// CertificateResponse 定义了单个证书的标准API响应结构
+type CertificateResponse struct {
+	ID                uint      `json:"id"`
+	AssetID           uint      `json:"assetId,omitempty"`
+	IP                string    `json:"ip"`
+	Port              int       `json:"port"`
+	Host              string    `json:"host"`
+	FingerprintSHA256 string    `json:"fingerprintSha256"`
+	SubjectCN         string    `json:"subjectCn"`
+	SubjectDN         string    `json:"subjectDn"`
+	SANs              []string  `json:"sans"`
+	IssuerCN          string    `json:"issuerCn"`
+	IssuerDN          string    `json:"issuerDn"`
+	Serial            string    `json:"serial"`
+	NotBefore         time.Time `json:"notBefore"`
+	NotAfter          time.Time `json:"notAfter"`
+	DaysRemaining     int       `json:"daysRemaining"`
+	IsExpired         bool      `json:"isExpired"`
+	IsSelfSigned      bool      `json:"isSelfSigned"`
+	IsMismatched      bool      `json:"isMismatched"`
+	// Issues 为证书存在的问题 (expired, expiring, mismatched, self-signed)
+	Issues      []string  `json:"issues"`
+	FirstSeenAt time.Time `json:"firstSeenAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+}
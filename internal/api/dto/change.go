@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// ChangeListRequest 定义了变化检测的查询参数。
+// since 为 RFC3339 时间或日期 (2006-01-02)，from/to 为参与比较的工作流 (顶级任务) ID，均可选。
+type ChangeListRequest struct {
+	Since string `form:"since"`
+	From  uint   `form:"from"`
+	To    uint   `form:"to"`
+}
+
+// FieldChange 描述实体某个属性在两次运行之间的变化
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// EntityChange 描述一个新增、消失或属性发生变化的域名或资产
+type EntityChange struct {
+	Kind     string        `json:"kind"`
+	EntityID uint          `json:"entityId"`
+	Value    string        `json:"value"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+}
+
+// ChangeSummary 是变化数量的汇总
+type ChangeSummary struct {
+	NewDomains     int `json:"newDomains"`
+	RemovedDomains int `json:"removedDomains"`
+	NewAssets      int `json:"newAssets"`
+	RemovedAssets  int `json:"removedAssets"`
+	ChangedAssets  int `json:"changedAssets"`
+}
+
+// ChangeSetResponse 是两次工作流运行之间的变化集合。
+// FromWorkflowID 为0表示没有可比较的基线运行，此时本次运行观测到的所有实体都视为新增。
+type ChangeSetResponse struct {
+	ProjectID      uint           `json:"projectId"`
+	FromWorkflowID uint           `json:"fromWorkflowId"`
+	FromCreatedAt  *time.Time     `json:"fromCreatedAt,omitempty"`
+	ToWorkflowID   uint           `json:"toWorkflowId"`
+	ToCreatedAt    *time.Time     `json:"toCreatedAt,omitempty"`
+	Summary        ChangeSummary  `json:"summary"`
+	NewDomains     []EntityChange `json:"newDomains"`
+	RemovedDomains []EntityChange `json:"removedDomains"`
+	NewAssets      []EntityChange `json:"newAssets"`
+	RemovedAssets  []EntityChange `json:"removedAssets"`
+	ChangedAssets  []EntityChange `json:"changedAssets"`
+}
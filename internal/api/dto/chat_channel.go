@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// CreateChatChannelRequest 定义了创建聊天渠道的请求体结构
+type CreateChatChannelRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Provider string `json:"provider" binding:"required,oneof=slack discord telegram"`
+	// URL 对 Slack/Discord 为 incoming webhook 地址，对 Telegram 为 https://api.telegram.org/bot<token>
+	URL    string `json:"url" binding:"required,url"`
+	ChatID string `json:"chatId"`
+	// Template 为 Go text/template 格式的消息模板，为空时使用默认模板
+	Template    string `json:"template"`
+	MinInterval int    `json:"minInterval" binding:"min=0"` // 两次发送之间的最小间隔 (秒)，0 表示使用默认值
+	MaxItems    int    `json:"maxItems" binding:"min=0"`    // 每类条目最多列出的数量，0 表示使用默认值
+	IsEnabled   *bool  `json:"isEnabled"`                   // 未提供时默认启用
+}
+
+// UpdateChatChannelRequest 定义了更新聊天渠道的请求体结构，未提供的字段保持不变
+type UpdateChatChannelRequest struct {
+	Name        string  `json:"name"`
+	URL         string  `json:"url" binding:"omitempty,url"`
+	ChatID      *string `json:"chatId"`
+	Template    *string `json:"template"`
+	MinInterval *int    `json:"minInterval" binding:"omitempty,min=0"`
+	MaxItems    *int    `json:"maxItems" binding:"omitempty,min=0"`
+	IsEnabled   *bool   `json:"isEnabled"`
+}
+
+// ChatChannelResponse 定义了单个聊天渠道的标准API响应结构
+type ChatChannelResponse struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"projectId"`
+	Name        string    `json:"name"`
+	Provider    string    `json:"provider"`
+	URL         string    `json:"url"`
+	ChatID      string    `json:"chatId,omitempty"`
+	Template    string    `json:"template"`
+	MinInterval int       `json:"minInterval"`
+	MaxItems    int       `json:"maxItems"`
+	IsEnabled   bool      `json:"isEnabled"`
+	LastSentAt  time.Time `json:"lastSentAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
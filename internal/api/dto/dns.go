@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// DNSRecordListRequest 定义了DNS记录列表的查询参数
+type DNSRecordListRequest struct {
+	PaginationRequest
+	// Type 可用逗号分隔多个记录类型，e.g., "A,AAAA"
+	Type     string `form:"type"`
+	FQDN     string `form:"fqdn"`
+	DomainID uint   `form:"domainId"`
+	// Dangling 为 true 时只返回悬空的 CNAME (子域名接管候选)
+	Dangling *bool `form:"dangling"`
+}
+
+// DNSRecordResponse 定义了单条DNS记录的标准API响应结构
+type DNSRecordResponse struct {
+	ID              uint      `json:"id"`
+	DomainID        uint      `json:"domainId,omitempty"`
+	FQDN            string    `json:"fqdn"`
+	Type            string    `json:"type"`
+	Value           string    `json:"value"`
+	IsDangling      bool      `json:"isDangling"`
+	TakeoverService string    `json:"takeoverService,omitempty"`
+	FirstSeenAt     time.Time `json:"firstSeenAt"`
+	LastSeenAt      time.Time `json:"lastSeenAt"`
+}
@@ -2,11 +2,42 @@ package dto
 
 import "time"
 
+// DomainListRequest 定义了域名列表的查询参数
+type DomainListRequest struct {
+	PaginationRequest
+	// CDN 为 true 时只返回接入CDN/WAF的域名，为 false 时排除
+	CDN *bool `form:"cdn"`
+	// CDNProvider 为CDN/WAF服务商，多个值之间用逗号分隔，e.g., "cloudflare,akamai"
+	CDNProvider string `form:"cdnProvider"`
+	// Tag 为标签名称，多个值之间用逗号分隔，打上任一标签即可
+	Tag string `form:"tag"`
+}
+
 // DomainResponse 定义了单个域名信息的标准API响应结构
 type DomainResponse struct {
-	ID         uint      `json:"id"`
-	FQDN       string    `json:"fqdn"`
-	RootDomain string    `json:"rootDomain"`
-	Source     string    `json:"source"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID          uint      `json:"id"`
+	FQDN        string    `json:"fqdn"`
+	RootDomain  string    `json:"rootDomain"`
+	Source      string    `json:"source"`
+	CDNProvider string    `json:"cdnProvider"`
+	FirstSeenAt time.Time `json:"firstSeenAt"`
+	LastSeenAt  time.Time `json:"lastSeenAt"`
+	IsGone      bool      `json:"isGone"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Tags        []string  `json:"tags,omitempty"`
+}
+
+// RootDomainListRequest 定义了根域名聚合列表的查询参数
+type RootDomainListRequest struct {
+	PaginationRequest
+	// Sort 为排序方式：subdomains (子域名数量，默认)、assets (资产数量)、lastSeen (最后发现时间)、name (根域名)
+	Sort string `form:"sort,default=subdomains" binding:"oneof=subdomains assets lastSeen name"`
+}
+
+// RootDomainResponse 定义了单个根域名的聚合统计
+type RootDomainResponse struct {
+	RootDomain     string    `json:"rootDomain"`
+	SubdomainCount int64     `json:"subdomainCount"`
+	AssetCount     int64     `json:"assetCount"`
+	LastSeenAt     time.Time `json:"lastSeenAt"`
 }
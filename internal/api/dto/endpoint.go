@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// EndpointListRequest 定义了Web端点列表的查询参数，多个值之间用逗号分隔
+type EndpointListRequest struct {
+	PaginationRequest
+	// Extension 为文件扩展名 (不含点)，e.g., "js,php"
+	Extension string `form:"extension"`
+	// Status 为响应状态码，e.g., "200,403"
+	Status string `form:"status"`
+	// Param 为查询参数名，端点需要同时包含所有给定的参数，e.g., "id,redirect"
+	Param    string `form:"param"`
+	Host     string `form:"host"`
+	DomainID uint   `form:"domainId"`
+	Method   string `form:"method"`
+	Source   string `form:"source"`
+}
+
+// EndpointResponse 定义了单个Web端点的标准API响应结构
+type EndpointResponse struct {
+	ID            uint      `json:"id"`
+	DomainID      uint      `json:"domainId,omitempty"`
+	URL           string    `json:"url"`
+	Method        string    `json:"method"`
+	Host          string    `json:"host"`
+	Path          string    `json:"path"`
+	Extension     string    `json:"extension"`
+	Parameters    []string  `json:"parameters"`
+	StatusCode    int       `json:"statusCode"`
+	ContentType   string    `json:"contentType"`
+	ContentLength int64     `json:"contentLength"`
+	Source        string    `json:"source"`
+	FirstSeenAt   time.Time `json:"firstSeenAt"`
+	LastSeenAt    time.Time `json:"lastSeenAt"`
+}
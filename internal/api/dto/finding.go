@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+// FindingListRequest 定义了漏洞发现列表的查询参数
+type FindingListRequest struct {
+	PaginationRequest
+	// Severity 可用逗号分隔多个严重程度，e.g., "high,critical"
+	Severity   string `form:"severity"`
+	Status     string `form:"status" binding:"omitempty,oneof=open triaged false-positive fixed"`
+	TemplateID string `form:"templateId"`
+	Host       string `form:"host"`
+	AssetID    uint   `form:"assetId"`
+	DomainID   uint   `form:"domainId"`
+	// Tag 为标签名称，多个值之间用逗号分隔，打上任一标签即可
+	Tag string `form:"tag"`
+}
+
+// UpdateFindingRequest 定义了分诊单个漏洞发现的请求体结构
+type UpdateFindingRequest struct {
+	Status string `json:"status" binding:"required,oneof=open triaged false-positive fixed"`
+}
+
+// BulkUpdateFindingsRequest 定义了批量分诊漏洞发现的请求体结构
+type BulkUpdateFindingsRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=1000"`
+	Status string `json:"status" binding:"required,oneof=open triaged false-positive fixed"`
+}
+
+// FindingResponse 定义了单个漏洞发现的标准API响应结构。
+// 列表接口不返回请求/响应证据，需通过详情接口获取。
+type FindingResponse struct {
+	ID               uint      `json:"id"`
+	ProjectID        uint      `json:"projectId"`
+	AssetID          uint      `json:"assetId,omitempty"`
+	DomainID         uint      `json:"domainId,omitempty"`
+	Fingerprint      string    `json:"fingerprint"`
+	TemplateID       string    `json:"templateId"`
+	Name             string    `json:"name"`
+	Severity         string    `json:"severity"`
+	Type             string    `json:"type"`
+	Host             string    `json:"host"`
+	IP               string    `json:"ip"`
+	Port             int       `json:"port"`
+	MatchedAt        string    `json:"matchedAt"`
+	MatcherName      string    `json:"matcherName,omitempty"`
+	ExtractedResults []string  `json:"extractedResults"`
+	Request          string    `json:"request,omitempty"`
+	Response         string    `json:"response,omitempty"`
+	Status           string    `json:"status"`
+	FirstSeenAt      time.Time `json:"firstSeenAt"`
+	LastSeenAt       time.Time `json:"lastSeenAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	Tags             []string  `json:"tags,omitempty"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// ObservationResponse 是实体时间线中的一条观测记录
+type ObservationResponse struct {
+	ID           uint      `json:"id"`
+	WorkflowID   uint      `json:"workflowId"`
+	Title        string    `json:"title,omitempty"`
+	WebServer    string    `json:"webServer,omitempty"`
+	Technologies []string  `json:"technologies,omitempty"`
+	ObservedAt   time.Time `json:"observedAt"`
+}
+
+// TimelineResponse 是单个域名或资产的历史时间线，Observations 为分页后的观测记录 (按时间倒序)
+type TimelineResponse struct {
+	Kind         string      `json:"kind"`
+	EntityID     uint        `json:"entityId"`
+	Value        string      `json:"value"`
+	FirstSeenAt  time.Time   `json:"firstSeenAt"`
+	LastSeenAt   time.Time   `json:"lastSeenAt"`
+	IsGone       bool        `json:"isGone"`
+	Observations interface{} `json:"observations"`
+}
@@ -1,9 +1,43 @@
 package dto
 
+const (
+	// MaxPageSize 是偏移分页模式下允许的最大页大小
+	MaxPageSize = 100
+	// MaxCursorPageSize 是游标分页模式下允许的最大页大小，供导出类场景使用
+	MaxCursorPageSize = 1000
+)
+
 // PaginationRequest 定义了分页请求的通用参数
 type PaginationRequest struct {
 	Page     int `form:"page,default=1"`
 	PageSize int `form:"pageSize,default=10"`
+	// Mode 为 "cursor" 时使用基于 (created_at, id) 的游标分页，忽略 Page
+	Mode string `form:"mode" binding:"omitempty,oneof=offset cursor"`
+	// Cursor 是上一页返回的 nextCursor，为空表示第一页
+	Cursor string `form:"cursor"`
+	// WithTotal 表示游标分页时是否额外统计总数 (大数据量时较慢)
+	WithTotal bool `form:"withTotal"`
+}
+
+// IsCursor 判断请求是否使用游标分页
+func (r *PaginationRequest) IsCursor() bool {
+	return r.Mode == "cursor" || r.Cursor != ""
+}
+
+// Normalize 修正非法的分页参数，超过上限的页大小按上限处理
+func (r *PaginationRequest) Normalize() {
+	if r.Page <= 0 {
+		r.Page = 1
+	}
+	maxSize := MaxPageSize
+	if r.IsCursor() {
+		maxSize = MaxCursorPageSize
+	}
+	if r.PageSize <= 0 {
+		r.PageSize = 10
+	} else if r.PageSize > maxSize {
+		r.PageSize = maxSize
+	}
 }
 
 // PaginationResponse 定义了分页响应的通用结构
@@ -13,3 +47,12 @@ type PaginationResponse struct {
 	PageSize int         `json:"pageSize"`
 	List     interface{} `json:"list"`
 }
+
+// CursorPaginationResponse 定义了游标分页响应的通用结构
+type CursorPaginationResponse struct {
+	Total      *int64      `json:"total,omitempty"` // 仅在 withTotal=true 时返回
+	PageSize   int         `json:"pageSize"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	HasMore    bool        `json:"hasMore"`
+	List       interface{} `json:"list"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "github.com/src-hunter/internal/model"
+
+// ParserTestRequest 定义了试运行解析器的JSON请求体结构；
+// 也可以使用 multipart 表单上传，样例输出放在 file 字段，通用解析器配置放在 parserConfig 字段 (JSON字符串)
+type ParserTestRequest struct {
+	Output string `json:"output" binding:"required"`
+	// ParserConfig 仅在试运行通用解析器时需要
+	ParserConfig *model.GenericParserConfig `json:"parserConfig"`
+}
+
+// ParserEntityRef 定义了关系一端的实体引用
+type ParserEntityRef struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+// ParserRelationshipResponse 定义了解析出的实体之间的关系
+type ParserRelationshipResponse struct {
+	From ParserEntityRef `json:"from"`
+	To   ParserEntityRef `json:"to"`
+}
+
+// ParserTestResponse 定义了解析器试运行的结果，实体均未入库，因此没有ID
+type ParserTestResponse struct {
+	Parser        string                       `json:"parser"`
+	Counts        map[string]int               `json:"counts"`
+	Domains       []DomainResponse             `json:"domains"`
+	Assets        []AssetResponse              `json:"assets"`
+	Relationships []ParserRelationshipResponse `json:"relationships"`
+	Findings      []FindingResponse            `json:"findings"`
+	Endpoints     []EndpointResponse           `json:"endpoints"`
+	DNSRecords    []DNSRecordResponse          `json:"dnsRecords"`
+	Certificates  []CertificateResponse        `json:"certificates"`
+}
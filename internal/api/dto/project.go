@@ -7,6 +7,12 @@ type CreateProjectRequest struct {
 	Description string `json:"description"`
 }
 
+// UpdateProjectRequest 定义了更新项目的请求体结构，未提供的字段保持不变
+type UpdateProjectRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"` // 使用指针以允许将描述清空
+}
+
 // AddTargetsRequest 定义了为项目添加目标的API请求体结构
 type AddTargetsRequest struct {
 	Targets []struct {
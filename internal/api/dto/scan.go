@@ -7,8 +7,12 @@ type CreateScanRequest struct {
 	// 使用模板的唯一ID，而不是名字
 	ScanProfileID uint `json:"scanProfileId" binding:"required"`
 	// 接受原始字符串输入，而不是数据库ID
-	InitialInputs []string `json:"initialInputs" binding:"required,min=1,dive,required"`
-	Description   string   `json:"description"`
+	InitialInputs []string `json:"initialInputs" binding:"omitempty,dive,required"`
+	// UseActiveTargets 为 true 时使用项目中所有启用的目标作为输入
+	UseActiveTargets bool `json:"useActiveTargets"`
+	// TargetIDs 指定使用项目中的部分目标作为输入，不能与 UseActiveTargets 同时使用
+	TargetIDs   []uint `json:"targetIds" binding:"omitempty,dive,required"`
+	Description string `json:"description"`
 }
 
 // CreateScanProfileRequest 定义了创建扫描模板的请求体结构
@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+// CreateScheduleRequest 定义了创建扫描计划的请求体结构
+type CreateScheduleRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Description   string `json:"description"`
+	ScanProfileID uint   `json:"scanProfileId" binding:"required"`
+	// CronExpr 为标准5段cron表达式或 @daily 等描述符，可用 CRON_TZ=Asia/Shanghai 前缀指定时区
+	CronExpr         string `json:"cronExpr" binding:"required"`
+	UseActiveTargets bool   `json:"useActiveTargets"`
+	TargetIDs        []uint `json:"targetIds" binding:"omitempty,dive,required"`
+	IsEnabled        *bool  `json:"isEnabled"` // 未提供时默认启用
+}
+
+// UpdateScheduleRequest 定义了更新扫描计划的请求体结构，未提供的字段保持不变
+type UpdateScheduleRequest struct {
+	Name             string  `json:"name"`
+	Description      *string `json:"description"`
+	ScanProfileID    uint    `json:"scanProfileId"`
+	CronExpr         string  `json:"cronExpr"`
+	UseActiveTargets *bool   `json:"useActiveTargets"`
+	TargetIDs        []uint  `json:"targetIds" binding:"omitempty,dive,required"`
+	IsEnabled        *bool   `json:"isEnabled"`
+}
+
+// ScheduleResponse 定义了单个扫描计划的标准API响应结构
+type ScheduleResponse struct {
+	ID               uint      `json:"id"`
+	ProjectID        uint      `json:"projectId"`
+	ScanProfileID    uint      `json:"scanProfileId"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	CronExpr         string    `json:"cronExpr"`
+	UseActiveTargets bool      `json:"useActiveTargets"`
+	TargetIDs        []uint    `json:"targetIds"`
+	IsEnabled        bool      `json:"isEnabled"`
+	NextRunAt        time.Time `json:"nextRunAt"`
+	LastRunAt        time.Time `json:"lastRunAt"`
+	LastTaskID       uint      `json:"lastTaskId"`
+	LastRunStatus    string    `json:"lastRunStatus"`
+	LastRunMessage   string    `json:"lastRunMessage"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
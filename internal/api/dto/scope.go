@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// CreateScopeRuleRequest 定义了创建范围规则的请求体结构
+type CreateScopeRuleRequest struct {
+	Action      string `json:"action" binding:"required,oneof=include exclude"`
+	Type        string `json:"type" binding:"required,oneof=domain ip cidr regex port"`
+	Value       string `json:"value" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ScopeRuleResponse 定义了单条范围规则的标准API响应结构
+type ScopeRuleResponse struct {
+	ID          uint      `json:"id"`
+	Action      string    `json:"action"`
+	Type        string    `json:"type"`
+	Value       string    `json:"value"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ScopeCheckRequest 定义了范围检查的请求体结构，值可以是域名、IP、CIDR 或 host:port
+type ScopeCheckRequest struct {
+	Values []string `json:"values" binding:"required,min=1,max=1000,dive,required"`
+}
+
+// ScopeCheckResult 是单个值的范围检查结果
+type ScopeCheckResult struct {
+	Value   string `json:"value"`
+	InScope bool   `json:"inScope"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// OutOfScopeListRequest 定义了范围外条目列表的查询参数
+type OutOfScopeListRequest struct {
+	PaginationRequest
+	Kind         string `form:"kind" binding:"omitempty,oneof=domain asset input"`
+	ParentTaskID uint   `form:"parentTaskId"`
+}
+
+// OutOfScopeItemResponse 定义了单个范围外条目的标准API响应结构
+type OutOfScopeItemResponse struct {
+	ID           uint      `json:"id"`
+	TaskID       uint      `json:"taskId"`
+	ParentTaskID uint      `json:"parentTaskId"`
+	Kind         string    `json:"kind"`
+	Value        string    `json:"value"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// SearchRequest 定义了结构化搜索的请求参数
+type SearchRequest struct {
+	PaginationRequest
+	// Query 为搜索语句，例如: tech:nginx port:8443 title:"admin" -domain:*.cdn.example.com
+	Query string `form:"q" binding:"required"`
+	// Type 为搜索的实体类型，默认为资产
+	Type string `form:"type,default=asset" binding:"oneof=asset domain"`
+}
@@ -0,0 +1,109 @@
+package dto
+
+import "time"
+
+// CreateTagRequest 定义了创建标签的请求体结构
+type CreateTagRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Color       string `json:"color" binding:"max=20"`
+	Description string `json:"description"`
+}
+
+// UpdateTagRequest 定义了更新标签的请求体结构，未提供的字段保持不变
+type UpdateTagRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Color       *string `json:"color" binding:"omitempty,max=20"`
+	Description *string `json:"description"`
+}
+
+// TagResponse 定义了单个标签的标准API响应结构
+type TagResponse struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"projectId"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	Description string    `json:"description"`
+	UsageCount  int64     `json:"usageCount"` // 打上此标签的实体数量
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// TaggingRequest 定义了为一组实体添加或删除标签的请求体结构，添加时不存在的标签会自动创建
+type TaggingRequest struct {
+	EntityType string   `json:"entityType" binding:"required,oneof=domain asset finding"`
+	EntityIDs  []uint   `json:"entityIds" binding:"required,min=1,max=1000"`
+	Tags       []string `json:"tags" binding:"required,min=1,max=20,dive,required,max=100"`
+}
+
+// BulkTagRequest 定义了按搜索语句批量打标签的请求体结构，语法与搜索接口相同
+type BulkTagRequest struct {
+	// Query 为搜索语句，例如: title:"admin" port:8443
+	Query string `json:"query" binding:"required"`
+	// Type 为搜索的实体类型，默认为资产
+	Type string   `json:"type" binding:"omitempty,oneof=asset domain"`
+	Tags []string `json:"tags" binding:"required,min=1,max=20,dive,required,max=100"`
+}
+
+// TaggingResponse 定义了添加或删除标签的结果
+type TaggingResponse struct {
+	Matched int64 `json:"matched"` // 命中的实体数量
+	Changed int64 `json:"changed"` // 实际新增或删除的标签关联数量
+}
+
+// CreateNoteRequest 定义了为实体添加备注的请求体结构
+type CreateNoteRequest struct {
+	EntityType string `json:"entityType" binding:"required,oneof=domain asset finding"`
+	EntityID   uint   `json:"entityId" binding:"required"`
+	Author     string `json:"author" binding:"required,max=100"`
+	Content    string `json:"content" binding:"required"`
+}
+
+// UpdateNoteRequest 定义了修改备注内容的请求体结构
+type UpdateNoteRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// NoteListRequest 定义了备注列表的查询参数，不指定实体时返回项目下的全部备注
+type NoteListRequest struct {
+	PaginationRequest
+	EntityType string `form:"entityType" binding:"omitempty,oneof=domain asset finding"`
+	EntityID   uint   `form:"entityId"`
+	Author     string `form:"author"`
+}
+
+// NoteResponse 定义了单条备注的标准API响应结构
+type NoteResponse struct {
+	ID         uint      `json:"id"`
+	ProjectID  uint      `json:"projectId"`
+	EntityType string    `json:"entityType"`
+	EntityID   uint      `json:"entityId"`
+	Author     string    `json:"author"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// CreateTagRuleRequest 定义了创建自动打标签规则的请求体结构，
+// 例如 {"entityType": "asset", "field": "title", "pattern": "admin", "tag": "admin-panel"}
+type CreateTagRuleRequest struct {
+	EntityType string `json:"entityType" binding:"required,oneof=domain asset finding"`
+	Field      string `json:"field" binding:"required"`
+	// Pattern 为正则表达式，匹配时忽略大小写
+	Pattern string `json:"pattern" binding:"required,max=1024"`
+	// Tag 为命中时添加的标签名称，不存在时自动创建
+	Tag         string `json:"tag" binding:"required,max=100"`
+	IsEnabled   *bool  `json:"isEnabled"` // 未提供时默认启用
+	Description string `json:"description"`
+}
+
+// TagRuleResponse 定义了单条自动打标签规则的标准API响应结构
+type TagRuleResponse struct {
+	ID          uint      `json:"id"`
+	TagID       uint      `json:"tagId"`
+	Tag         string    `json:"tag"`
+	EntityType  string    `json:"entityType"`
+	Field       string    `json:"field"`
+	Pattern     string    `json:"pattern"`
+	IsEnabled   bool      `json:"isEnabled"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
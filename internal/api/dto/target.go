@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// TargetListRequest 定义了目标列表的查询参数
+type TargetListRequest struct {
+	PaginationRequest
+	Type     string `form:"type" binding:"omitempty,oneof=domain ip cidr"`
+	IsActive *bool  `form:"isActive"`
+}
+
+// UpdateTargetRequest 定义了更新目标的请求体结构，未提供的字段保持不变
+type UpdateTargetRequest struct {
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"isActive"`
+}
+
+// TargetResponse 定义了单个项目目标的标准API响应结构
+type TargetResponse struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"projectId"`
+	Value       string    `json:"value"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// 批量导入时每一行的处理结果
+const (
+	ImportStatusCreated   = "created"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusInvalid   = "invalid"
+)
+
+// TargetImportLine 描述了导入文件中一行的处理结果
+type TargetImportLine struct {
+	Line    int    `json:"line"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// TargetImportReport 是批量导入目标的结果报告
+type TargetImportReport struct {
+	Created   int                `json:"created"`
+	Duplicate int                `json:"duplicate"`
+	Invalid   int                `json:"invalid"`
+	Lines     []TargetImportLine `json:"lines"`
+}
@@ -6,6 +6,7 @@ type TaskResponse struct {
 	ID            uint      `json:"id"`
 	ProjectID     uint      `json:"projectId"`
 	ScanProfileID uint      `json:"scanProfileId"`
+	Type          string    `json:"type"`
 	Status        string    `json:"status"`
 	Result        string    `json:"result"`
 	FinishedAt    time.Time `json:"finishedAt"`
@@ -0,0 +1,60 @@
+package dto
+
+import "time"
+
+// CreateWebhookRequest 定义了创建Webhook订阅的请求体结构
+type CreateWebhookRequest struct {
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required,url"`
+	// Secret 为空时自动生成
+	Secret string `json:"secret"`
+	// Events 为订阅的事件类型，为空表示订阅所有事件
+	Events    []string `json:"events"`
+	IsEnabled *bool    `json:"isEnabled"` // 未提供时默认启用
+}
+
+// UpdateWebhookRequest 定义了更新Webhook订阅的请求体结构，未提供的字段保持不变
+type UpdateWebhookRequest struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url" binding:"omitempty,url"`
+	Secret    *string  `json:"secret"`
+	Events    []string `json:"events"`
+	IsEnabled *bool    `json:"isEnabled"`
+}
+
+// WebhookResponse 定义了单个Webhook订阅的标准API响应结构。
+// 密钥只在创建时返回一次，之后只返回是否已设置。
+type WebhookResponse struct {
+	ID        uint      `json:"id"`
+	ProjectID uint      `json:"projectId"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	HasSecret bool      `json:"hasSecret"`
+	Events    []string  `json:"events"`
+	IsEnabled bool      `json:"isEnabled"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDeliveryListRequest 定义了投递日志列表的查询参数
+type WebhookDeliveryListRequest struct {
+	PaginationRequest
+	Status string `form:"status" binding:"omitempty,oneof=pending retrying success failed"`
+	Event  string `form:"event"`
+}
+
+// WebhookDeliveryResponse 定义了单条投递日志的标准API响应结构
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id"`
+	SubscriptionID uint       `json:"subscriptionId"`
+	EventID        string     `json:"eventId"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseCode   int        `json:"responseCode"`
+	ResponseBody   string     `json:"responseBody,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AssetHandler struct {
+	DB *gorm.DB
+}
+
+func NewAssetHandler(db *gorm.DB) *AssetHandler {
+	return &AssetHandler{DB: db}
+}
+
+// GetAssetsByProject 分页获取指定项目下发现的所有资产
+func (h *AssetHandler) GetAssetsByProject(c *gin.Context) {
+	// 1. 解析项目ID
+	projectIDStr := c.Param("projectId")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	// 2. 绑定分页和过滤参数
+	var req dto.AssetListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	// 3. 查询当前页数据 (偏移分页或游标分页)
+	query := h.DB.Model(&model.Asset{}).Where("project_id = ?", projectID)
+	query = filterAssetsByCDN(filterAssetsByIPMetadata(query, &req), &req)
+	query = filterByTags(query, model.TagEntityAsset, "assets", req.Tag)
+	page, err := pagination.Find(query, &req.PaginationRequest, func(a model.Asset) (time.Time, uint) {
+		return a.CreatedAt, a.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	// 4. 将数据库模型转换为DTO，附上IP归属信息和标签后返回分页响应
+	list := toAssetResponses(page.Items)
+	if err := attachIPMetadata(h.DB, list); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	if err := attachAssetTags(h.DB, list); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, list))
+}
+
+// filterAssetsByIPMetadata 按IP归属信息过滤资产，条件通过 ip_metadata 子查询匹配
+func filterAssetsByIPMetadata(query *gorm.DB, req *dto.AssetListRequest) *gorm.DB {
+	var conds []string
+	var args []interface{}
+	if asns := splitList(req.ASN); len(asns) > 0 {
+		for i, asn := range asns {
+			asn = strings.ToUpper(asn)
+			if !strings.HasPrefix(asn, "AS") {
+				asn = "AS" + asn
+			}
+			asns[i] = asn
+		}
+		conds = append(conds, "im.asn IN ?")
+		args = append(args, asns)
+	}
+	if countries := splitList(req.Country); len(countries) > 0 {
+		for i := range countries {
+			countries[i] = strings.ToUpper(countries[i])
+		}
+		conds = append(conds, "im.country_code IN ?")
+		args = append(args, countries)
+	}
+	if req.Org != "" {
+		conds = append(conds, "im.organization ILIKE ?")
+		args = append(args, "%"+req.Org+"%")
+	}
+	if clouds := splitList(req.Cloud); len(clouds) > 0 {
+		for i := range clouds {
+			clouds[i] = strings.ToLower(clouds[i])
+		}
+		conds = append(conds, "im.cloud_provider IN ?")
+		args = append(args, clouds)
+	}
+	if len(conds) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM ip_metadata im WHERE im.ip = assets.ip AND "+strings.Join(conds, " AND ")+")", args...)
+	}
+	return query
+}
+
+// filterAssetsByCDN 按资产识别出的CDN/WAF服务商过滤
+func filterAssetsByCDN(query *gorm.DB, req *dto.AssetListRequest) *gorm.DB {
+	if req.CDN != nil {
+		if *req.CDN {
+			query = query.Where("assets.cdn_provider <> ''")
+		} else {
+			query = query.Where("assets.cdn_provider = ''")
+		}
+	}
+	if providers := splitList(req.CDNProvider); len(providers) > 0 {
+		for i := range providers {
+			providers[i] = strings.ToLower(providers[i])
+		}
+		query = query.Where("assets.cdn_provider IN ?", providers)
+	}
+	return query
+}
+
+// attachIPMetadata 为资产响应附上IP归属信息
+func attachIPMetadata(db *gorm.DB, assets []dto.AssetResponse) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	ips := make([]string, 0, len(assets))
+	for _, a := range assets {
+		ips = append(ips, a.IP)
+	}
+	var metadata []model.IPMetadata
+	if err := db.Where("ip IN ?", ips).Find(&metadata).Error; err != nil {
+		return err
+	}
+	byIP := make(map[string]*dto.IPMetadataResponse, len(metadata))
+	for _, m := range metadata {
+		byIP[m.IP] = &dto.IPMetadataResponse{
+			ASN:           m.ASN,
+			Organization:  m.Organization,
+			CountryCode:   m.CountryCode,
+			City:          m.City,
+			CloudProvider: m.CloudProvider,
+			IsCDN:         m.IsCDN,
+			UpdatedAt:     m.UpdatedAt,
+		}
+	}
+	for i := range assets {
+		assets[i].IPMetadata = byIP[assets[i].IP]
+	}
+	return nil
+}
+
+func toAssetResponses(assets []model.Asset) []dto.AssetResponse {
+	var assetDTOs []dto.AssetResponse
+	for _, asset := range assets {
+		assetDTOs = append(assetDTOs, dto.AssetResponse{
+			ID:           asset.ID,
+			IP:           asset.IP,
+			Port:         asset.Port,
+			Protocol:     asset.Protocol,
+			Transport:    asset.Transport,
+			Product:      asset.Product,
+			Version:      asset.Version,
+			HostState:    asset.HostState,
+			Title:        asset.Title,
+			WebServer:    asset.WebServer,
+			Technologies: asset.Technologies,
+			Source:       asset.Source,
+			CDNProvider:  asset.CDNProvider,
+			FirstSeenAt:  asset.CreatedAt,
+			LastSeenAt:   asset.LastSeenAt,
+			IsGone:       asset.IsGone,
+			CreatedAt:    asset.CreatedAt,
+		})
+	}
+	return assetDTOs
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultExpiringDays 是证书即将过期的默认判定窗口
+const defaultExpiringDays = 30
+
+// latestCertificateSQL 只保留同一 (IP, 端口, 主机名) 上最近一次观测到的证书。
+// 证书按指纹保存且不会过期删除，被替换掉的旧证书过期后不应再作为问题报告。
+const latestCertificateSQL = `NOT EXISTS (
+	SELECT 1 FROM certificates newer
+	WHERE newer.project_id = certificates.project_id AND newer.ip = certificates.ip
+		AND newer.port = certificates.port AND newer.host = certificates.host
+		AND newer.deleted_at IS NULL AND newer.last_seen_at > certificates.last_seen_at)`
+
+type CertificateHandler struct {
+	DB *gorm.DB
+}
+
+func NewCertificateHandler(db *gorm.DB) *CertificateHandler {
+	return &CertificateHandler{DB: db}
+}
+
+// GetCertificatesByProject 分页获取项目下观测到的TLS证书
+// @Router /projects/{projectId}/certificates [get]
+func (h *CertificateHandler) GetCertificatesByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CertificateListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Certificate{}).Where("project_id = ?", projectID)
+	if req.AssetID != 0 {
+		query = query.Where("asset_id = ?", req.AssetID)
+	}
+	if req.Host != "" {
+		query = query.Where("host = ?", strings.ToLower(req.Host))
+	}
+	if req.Fingerprint != "" {
+		query = query.Where("fingerprint_sha256 = ?", strings.ToLower(req.Fingerprint))
+	}
+	if req.ExpiringWithin > 0 {
+		query = query.Where("not_after < ?", time.Now().AddDate(0, 0, req.ExpiringWithin)).Where(latestCertificateSQL)
+	}
+	if req.Mismatched != nil {
+		query = query.Where("is_mismatched = ?", *req.Mismatched)
+	}
+	if req.SelfSigned != nil {
+		query = query.Where("is_self_signed = ?", *req.SelfSigned)
+	}
+
+	h.respondCertificates(c, query, &req.PaginationRequest, defaultExpiringDays)
+}
+
+// GetCertificateIssues 分页获取即将过期 (含已过期) 或与主机名不匹配的证书，
+// 同一 (IP, 端口, 主机名) 只检查最近一次观测到的证书
+// @Router /projects/{projectId}/certificates/issues [get]
+func (h *CertificateHandler) GetCertificateIssues(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CertificateIssueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Certificate{}).
+		Where("project_id = ?", projectID).
+		Where("not_after < ? OR is_mismatched = ?", time.Now().AddDate(0, 0, req.Days), true).
+		Where(latestCertificateSQL)
+
+	h.respondCertificates(c, query, &req.PaginationRequest, req.Days)
+}
+
+// respondCertificates 执行分页查询并返回证书列表，expiringDays 用于标注即将过期的问题
+func (h *CertificateHandler) respondCertificates(c *gin.Context, query *gorm.DB, req *dto.PaginationRequest, expiringDays int) {
+	page, err := pagination.Find(query, req, func(cert model.Certificate) (time.Time, uint) {
+		return cert.CreatedAt, cert.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	now := time.Now()
+	certificateDTOs := make([]dto.CertificateResponse, 0, len(page.Items))
+	for i := range page.Items {
+		certificateDTOs = append(certificateDTOs, toCertificateResponse(&page.Items[i], now, expiringDays))
+	}
+	response.Ok(c, pagination.Response(req, page, certificateDTOs))
+}
+
+func toCertificateResponse(cert *model.Certificate, now time.Time, expiringDays int) dto.CertificateResponse {
+	sans := cert.SANs
+	if sans == nil {
+		sans = model.JSONBArray{}
+	}
+	remaining := cert.NotAfter.Sub(now)
+	expired := remaining < 0
+
+	issues := []string{}
+	switch {
+	case expired:
+		issues = append(issues, "expired")
+	case remaining < time.Duration(expiringDays)*24*time.Hour:
+		issues = append(issues, "expiring")
+	}
+	if cert.IsMismatched {
+		issues = append(issues, "mismatched")
+	}
+	if cert.IsSelfSigned {
+		issues = append(issues, "self-signed")
+	}
+
+	return dto.CertificateResponse{
+		ID:                cert.ID,
+		AssetID:           cert.AssetID,
+		IP:                cert.IP,
+		Port:              cert.Port,
+		Host:              cert.Host,
+		FingerprintSHA256: cert.FingerprintSHA256,
+		SubjectCN:         cert.SubjectCN,
+		SubjectDN:         cert.SubjectDN,
+		SANs:              sans,
+		IssuerCN:          cert.IssuerCN,
+		IssuerDN:          cert.IssuerDN,
+		Serial:            cert.Serial,
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		DaysRemaining:     int(remaining.Hours() / 24),
+		IsExpired:         expired,
+		IsSelfSigned:      cert.IsSelfSigned,
+		IsMismatched:      cert.IsMismatched,
+		Issues:            issues,
+		FirstSeenAt:       cert.CreatedAt,
+		LastSeenAt:        cert.LastSeenAt,
+	}
+}
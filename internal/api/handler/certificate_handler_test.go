@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+)
+
+func TestCertificateIssuesOnlyCheckLatestCertificate(t *testing.T) {
+	db := testutil.DB(t)
+	project := newTestProject(t, db)
+	now := time.Now()
+	certs := []model.Certificate{
+		// 443 上过期的旧证书已被新证书替换
+		{IP: "192.0.2.1", Port: 443, Host: "a.example.com", FingerprintSHA256: "old", NotAfter: now.AddDate(0, 0, -100), LastSeenAt: now.AddDate(0, 0, -200)},
+		{IP: "192.0.2.1", Port: 443, Host: "a.example.com", FingerprintSHA256: "new", NotAfter: now.AddDate(1, 0, 0), LastSeenAt: now},
+		// 8443 上最近一次观测到的证书已过期
+		{IP: "192.0.2.1", Port: 8443, Host: "a.example.com", FingerprintSHA256: "stale", NotAfter: now.AddDate(0, 0, -1), LastSeenAt: now},
+	}
+	for i := range certs {
+		certs[i].ProjectID = project.ID
+		if err := db.Create(&certs[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var list []dto.CertificateResponse
+	page := dto.PaginationResponse{List: &list}
+	if code := serve(t, NewCertificateHandler(db).GetCertificateIssues, http.MethodGet, "/", nil, projectParams(project.ID), &page); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if len(list) != 1 || list[0].FingerprintSHA256 != "stale" || !list[0].IsExpired {
+		t.Errorf("证书问题 = %+v, want 只有 8443 上过期的证书", list)
+	}
+}
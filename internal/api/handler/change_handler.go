@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/changes"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+type ChangeHandler struct {
+	DB *gorm.DB
+}
+
+func NewChangeHandler(db *gorm.DB) *ChangeHandler {
+	return &ChangeHandler{DB: db}
+}
+
+// GetProjectChanges 比较项目的两次运行，返回新增、消失和属性变化的域名与资产。
+// 默认比较最近一次已完成运行 (to) 与 since 之前可比较的最近一次已完成运行 (from，见 changes.Baseline)；
+// 未指定 since 时与 to 的上一次运行比较。也可以用 from/to 显式指定两次运行。
+// @Router /projects/{projectId}/changes [get]
+func (h *ChangeHandler) GetProjectChanges(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.ChangeListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	var since time.Time
+	if req.Since != "" {
+		if since, err = parseSince(req.Since); err != nil {
+			response.BadRequest(c, "since 必须是 RFC3339 时间或 YYYY-MM-DD 日期", err)
+			return
+		}
+	}
+
+	// 1. 确定比较的目标运行
+	var to *model.Task
+	if req.To != 0 {
+		if to, err = h.loadWorkflow(c, uint(projectID), req.To); err != nil {
+			return
+		}
+	} else {
+		if to, err = changes.LatestCompleted(h.DB, uint(projectID), 0, time.Time{}); err != nil {
+			response.ServerError(c, err)
+			return
+		}
+		if to == nil || (!since.IsZero() && to.CreatedAt.Before(since)) {
+			// 没有 since 之后完成的运行，也就没有任何变化
+			response.Ok(c, dto.ChangeSetResponse{
+				ProjectID:      uint(projectID),
+				NewDomains:     []dto.EntityChange{},
+				RemovedDomains: []dto.EntityChange{},
+				NewAssets:      []dto.EntityChange{},
+				RemovedAssets:  []dto.EntityChange{},
+				ChangedAssets:  []dto.EntityChange{},
+			})
+			return
+		}
+	}
+
+	// 2. 确定基线运行
+	var from *model.Task
+	if req.From != 0 {
+		if from, err = h.loadWorkflow(c, uint(projectID), req.From); err != nil {
+			return
+		}
+	} else {
+		before := to.CreatedAt
+		if !since.IsZero() && since.Before(before) {
+			before = since
+		}
+		if from, err = changes.Baseline(h.DB, to, before); err != nil {
+			response.ServerError(c, err)
+			return
+		}
+	}
+
+	result, err := changes.Compare(h.DB, from, to)
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, toChangeSetResponse(result))
+}
+
+// GetWorkflowChanges 返回一次工作流运行相对上一次可比较的已完成运行的新发现汇总
+// @Router /tasks/{taskId}/changes [get]
+func (h *ChangeHandler) GetWorkflowChanges(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("taskId"))
+	if err != nil {
+		response.BadRequest(c, "无效的任务ID", err)
+		return
+	}
+
+	workflow, err := h.loadWorkflow(c, 0, uint(taskID))
+	if err != nil {
+		return
+	}
+	result, err := changes.Summarize(h.DB, workflow)
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, toChangeSetResponse(result))
+}
+
+// loadWorkflow 加载工作流顶级任务，失败时直接写入响应
+func (h *ChangeHandler) loadWorkflow(c *gin.Context, projectID, workflowID uint) (*model.Task, error) {
+	workflow, err := changes.LoadWorkflow(h.DB, projectID, workflowID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.NotFound(c)
+		case errors.Is(err, changes.ErrNotWorkflow):
+			response.BadRequest(c, err.Error(), err)
+		default:
+			response.ServerError(c, err)
+		}
+		return nil, err
+	}
+	return workflow, nil
+}
+
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+func toChangeSetResponse(set *changes.ChangeSet) dto.ChangeSetResponse {
+	return dto.ChangeSetResponse{
+		ProjectID:      set.ProjectID,
+		FromWorkflowID: set.FromWorkflowID,
+		FromCreatedAt:  set.FromCreatedAt,
+		ToWorkflowID:   set.ToWorkflowID,
+		ToCreatedAt:    set.ToCreatedAt,
+		Summary:        dto.ChangeSummary(set.Summary),
+		NewDomains:     toEntityChanges(set.NewDomains),
+		RemovedDomains: toEntityChanges(set.RemovedDomains),
+		NewAssets:      toEntityChanges(set.NewAssets),
+		RemovedAssets:  toEntityChanges(set.RemovedAssets),
+		ChangedAssets:  toEntityChanges(set.ChangedAssets),
+	}
+}
+
+func toEntityChanges(list []changes.EntityChange) []dto.EntityChange {
+	result := make([]dto.EntityChange, 0, len(list))
+	for _, change := range list {
+		var fields []dto.FieldChange
+		for _, field := range change.Changes {
+			fields = append(fields, dto.FieldChange(field))
+		}
+		result = append(result, dto.EntityChange{
+			Kind:     change.Kind,
+			EntityID: change.EntityID,
+			Value:    change.Value,
+			Changes:  fields,
+		})
+	}
+	return result
+}
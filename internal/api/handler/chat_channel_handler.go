@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/notify"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type ChatChannelHandler struct {
+	DB         *gorm.DB
+	Dispatcher *notify.ChatDispatcher
+}
+
+func NewChatChannelHandler(db *gorm.DB, asynqClient *asynq.Client) *ChatChannelHandler {
+	return &ChatChannelHandler{DB: db, Dispatcher: notify.NewChatDispatcher(db, asynqClient)}
+}
+
+// GetChatChannelsByProject 获取项目下的所有聊天渠道
+// @Router /projects/{projectId}/chat-channels [get]
+func (h *ChatChannelHandler) GetChatChannelsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var channels []model.ChatChannel
+	if err := h.DB.Where("project_id = ?", projectID).Order("id").Find(&channels).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	channelDTOs := make([]dto.ChatChannelResponse, 0, len(channels))
+	for i := range channels {
+		channelDTOs = append(channelDTOs, toChatChannelResponse(&channels[i]))
+	}
+	response.Ok(c, channelDTOs)
+}
+
+// CreateChatChannel 为项目创建一个聊天渠道，保存前会用示例数据试渲染模板
+// @Router /projects/{projectId}/chat-channels [post]
+func (h *ChatChannelHandler) CreateChatChannel(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateChatChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	var project model.Project
+	if err := h.DB.First(&project, uint(projectID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "项目ID不存在", err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	channel := model.ChatChannel{
+		ProjectID:   project.ID,
+		Name:        req.Name,
+		Provider:    req.Provider,
+		URL:         req.URL,
+		ChatID:      req.ChatID,
+		Template:    req.Template,
+		MinInterval: req.MinInterval,
+		MaxItems:    req.MaxItems,
+		IsEnabled:   req.IsEnabled == nil || *req.IsEnabled,
+	}
+	if err := notify.ValidateChatChannel(&channel); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+	if err := h.DB.Create(&channel).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "创建聊天渠道成功", toChatChannelResponse(&channel))
+}
+
+// GetChatChannelByID 根据ID获取单个聊天渠道
+// @Router /chat-channels/{id} [get]
+func (h *ChatChannelHandler) GetChatChannelByID(c *gin.Context) {
+	channel, ok := h.loadChatChannel(c)
+	if !ok {
+		return
+	}
+	response.Ok(c, toChatChannelResponse(channel))
+}
+
+// UpdateChatChannel 更新一个聊天渠道
+// @Router /chat-channels/{id} [put]
+func (h *ChatChannelHandler) UpdateChatChannel(c *gin.Context) {
+	channel, ok := h.loadChatChannel(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateChatChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	if req.Name != "" {
+		channel.Name = req.Name
+	}
+	if req.URL != "" {
+		channel.URL = req.URL
+	}
+	if req.ChatID != nil {
+		channel.ChatID = *req.ChatID
+	}
+	if req.Template != nil {
+		channel.Template = *req.Template
+	}
+	if req.MinInterval != nil {
+		channel.MinInterval = *req.MinInterval
+	}
+	if req.MaxItems != nil {
+		channel.MaxItems = *req.MaxItems
+	}
+	if req.IsEnabled != nil {
+		channel.IsEnabled = *req.IsEnabled
+	}
+	if err := notify.ValidateChatChannel(channel); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	if err := h.DB.Model(channel).Select("name", "url", "chat_id", "template", "min_interval", "max_items", "is_enabled").
+		Updates(channel).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "更新聊天渠道成功", toChatChannelResponse(channel))
+}
+
+// DeleteChatChannel 删除一个聊天渠道，尚未发送的摘要将被丢弃
+// @Router /chat-channels/{id} [delete]
+func (h *ChatChannelHandler) DeleteChatChannel(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的渠道ID", err)
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Delete(&model.ChatChannel{}, uint(id))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Unscoped().Where("channel_id = ?", id).Delete(&model.ChatDigestEntry{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "删除聊天渠道成功", nil)
+}
+
+// TestChatChannel 立即用示例数据向渠道发送一条测试消息
+// @Router /chat-channels/{id}/test [post]
+func (h *ChatChannelHandler) TestChatChannel(c *gin.Context) {
+	channel, ok := h.loadChatChannel(c)
+	if !ok {
+		return
+	}
+	if err := h.Dispatcher.SendTest(c.Request.Context(), channel); err != nil {
+		response.Fail(c, "测试消息发送失败: "+err.Error())
+		return
+	}
+	response.OkWithMessage(c, "测试消息发送成功", nil)
+}
+
+// loadChatChannel 解析URL中的渠道ID并查询渠道，失败时直接写入响应
+func (h *ChatChannelHandler) loadChatChannel(c *gin.Context) (*model.ChatChannel, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的渠道ID", err)
+		return nil, false
+	}
+	var channel model.ChatChannel
+	if err := h.DB.First(&channel, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &channel, true
+}
+
+func toChatChannelResponse(channel *model.ChatChannel) dto.ChatChannelResponse {
+	return dto.ChatChannelResponse{
+		ID:          channel.ID,
+		ProjectID:   channel.ProjectID,
+		Name:        channel.Name,
+		Provider:    channel.Provider,
+		URL:         channel.URL,
+		ChatID:      channel.ChatID,
+		Template:    channel.Template,
+		MinInterval: channel.MinInterval,
+		MaxItems:    channel.MaxItems,
+		IsEnabled:   channel.IsEnabled,
+		LastSentAt:  channel.LastSentAt,
+		CreatedAt:   channel.CreatedAt,
+	}
+}
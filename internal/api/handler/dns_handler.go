@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DNSHandler struct {
+	DB *gorm.DB
+}
+
+func NewDNSHandler(db *gorm.DB) *DNSHandler {
+	return &DNSHandler{DB: db}
+}
+
+// GetDNSRecordsByProject 分页获取项目下的DNS记录，dangling=true 时只返回子域名接管候选
+// @Router /projects/{projectId}/dns-records [get]
+func (h *DNSHandler) GetDNSRecordsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.DNSRecordListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.DNSRecord{}).Where("project_id = ?", projectID)
+	if types := splitList(strings.ToUpper(req.Type)); len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	if req.FQDN != "" {
+		query = query.Where("fqdn = ?", strings.ToLower(req.FQDN))
+	}
+	if req.DomainID != 0 {
+		query = query.Where("domain_id = ?", req.DomainID)
+	}
+	if req.Dangling != nil {
+		query = query.Where("is_dangling = ?", *req.Dangling)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(r model.DNSRecord) (time.Time, uint) {
+		return r.CreatedAt, r.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	recordDTOs := make([]dto.DNSRecordResponse, 0, len(page.Items))
+	for i := range page.Items {
+		recordDTOs = append(recordDTOs, toDNSRecordResponse(&page.Items[i]))
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, recordDTOs))
+}
+
+func toDNSRecordResponse(r *model.DNSRecord) dto.DNSRecordResponse {
+	return dto.DNSRecordResponse{
+		ID:              r.ID,
+		DomainID:        r.DomainID,
+		FQDN:            r.FQDN,
+		Type:            r.Type,
+		Value:           r.Value,
+		IsDangling:      r.IsDangling,
+		TakeoverService: r.TakeoverService,
+		FirstSeenAt:     r.CreatedAt,
+		LastSeenAt:      r.LastSeenAt,
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
 	"github.com/src-hunter/internal/api/response"
 	"github.com/src-hunter/internal/model"
 	"gorm.io/gorm"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type DomainHandler struct {
@@ -28,51 +31,99 @@ func (h *DomainHandler) GetDomainsByProject(c *gin.Context) {
 		return
 	}
 
-	// 2. 绑定分页参数
-	var req dto.PaginationRequest
+	// 2. 绑定分页和过滤参数
+	var req dto.DomainListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		response.BadRequest(c, "分页参数错误", err)
+		response.BadRequest(c, "查询参数错误", err)
 		return
 	}
-	if req.Page <= 0 {
-		req.Page = 1
+	req.Normalize()
+
+	// 3. 查询当前页数据 (偏移分页或游标分页)
+	query := h.DB.Model(&model.Domain{}).Where("project_id = ?", projectID)
+	if req.CDN != nil {
+		if *req.CDN {
+			query = query.Where("cdn_provider <> ''")
+		} else {
+			query = query.Where("cdn_provider = ''")
+		}
+	}
+	if providers := splitList(req.CDNProvider); len(providers) > 0 {
+		for i := range providers {
+			providers[i] = strings.ToLower(providers[i])
+		}
+		query = query.Where("cdn_provider IN ?", providers)
 	}
-	if req.PageSize <= 0 || req.PageSize > 100 {
-		req.PageSize = 10
+	query = filterByTags(query, model.TagEntityDomain, "domains", req.Tag)
+	page, err := pagination.Find(query, &req.PaginationRequest, func(d model.Domain) (time.Time, uint) {
+		return d.CreatedAt, d.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
 	}
 
-	// 3. 查询总数和当前页数据
-	var domains []model.Domain
-	var total int64
+	// 4. 将数据库模型转换为DTO，附上标签后返回分页响应
+	list := toDomainResponses(page.Items)
+	if err := attachDomainTags(h.DB, list); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, list))
+}
 
-	// 创建查询构建器，限定项目ID
-	query := h.DB.Model(&model.Domain{}).Where("project_id = ?", projectID)
+// rootDomainOrders 是根域名聚合列表支持的排序方式
+var rootDomainOrders = map[string]string{
+	"subdomains": "subdomain_count DESC, root_domain",
+	"assets":     "asset_count DESC, root_domain",
+	"lastSeen":   "last_seen_at DESC, root_domain",
+	"name":       "root_domain",
+}
 
-	if err := query.Count(&total).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			response.ServerError(c, err)
-		}
+// GetRootDomainsByProject 按根域名聚合指定项目下的域名，返回每个根域名的子域名数量和关联资产数量
+func (h *DomainHandler) GetRootDomainsByProject(c *gin.Context) {
+	// 1. 解析项目ID
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
 		return
 	}
 
-	offset := (req.Page - 1) * req.PageSize
-	if err := query.Offset(offset).Limit(req.PageSize).Order("created_at desc").Find(&domains).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			response.ServerError(c, err)
-		}
+	// 2. 绑定分页和排序参数，聚合查询只支持偏移分页
+	var req dto.RootDomainListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
 		return
 	}
+	req.Mode, req.Cursor = "", ""
+	req.Normalize()
 
-	// 4. 将数据库模型转换为DTO
-	var domainDTOs []dto.DomainResponse
-	for _, domain := range domains {
-		domainDTOs = append(domainDTOs, dto.DomainResponse{
-			ID:         domain.ID,
-			FQDN:       domain.FQDN,
-			RootDomain: domain.RootDomain,
-			Source:     domain.Source,
-			CreatedAt:  domain.CreatedAt,
-		})
+	// 3. 查询根域名总数
+	var total int64
+	if err := h.DB.Model(&model.Domain{}).Where("project_id = ?", projectID).
+		Distinct("root_domain").Count(&total).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	// 4. 按根域名分组统计当前页
+	var roots []dto.RootDomainResponse
+	err = h.DB.Table("domains AS d").
+		Select("d.root_domain, COUNT(DISTINCT d.id) AS subdomain_count, COUNT(DISTINCT a.id) AS asset_count, MAX(d.last_seen_at) AS last_seen_at").
+		Joins("LEFT JOIN asset_domain_mappings m ON m.domain_id = d.id").
+		Joins("LEFT JOIN assets a ON a.id = m.asset_id AND a.deleted_at IS NULL").
+		Where("d.project_id = ? AND d.deleted_at IS NULL", projectID).
+		Group("d.root_domain").
+		Order(rootDomainOrders[req.Sort]).
+		Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).
+		Scan(&roots).Error
+	if err != nil {
+		response.ServerError(c, err)
+		return
 	}
 
 	// 5. 返回分页响应
@@ -80,6 +131,24 @@ func (h *DomainHandler) GetDomainsByProject(c *gin.Context) {
 		Total:    total,
 		Page:     req.Page,
 		PageSize: req.PageSize,
-		List:     domainDTOs,
+		List:     roots,
 	})
 }
+
+func toDomainResponses(domains []model.Domain) []dto.DomainResponse {
+	var domainDTOs []dto.DomainResponse
+	for _, domain := range domains {
+		domainDTOs = append(domainDTOs, dto.DomainResponse{
+			ID:          domain.ID,
+			FQDN:        domain.FQDN,
+			RootDomain:  domain.RootDomain,
+			Source:      domain.Source,
+			CDNProvider: domain.CDNProvider,
+			FirstSeenAt: domain.CreatedAt,
+			LastSeenAt:  domain.LastSeenAt,
+			IsGone:      domain.IsGone,
+			CreatedAt:   domain.CreatedAt,
+		})
+	}
+	return domainDTOs
+}
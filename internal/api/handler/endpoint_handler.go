@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type EndpointHandler struct {
+	DB *gorm.DB
+}
+
+func NewEndpointHandler(db *gorm.DB) *EndpointHandler {
+	return &EndpointHandler{DB: db}
+}
+
+// GetEndpointsByProject 分页获取项目下的Web端点，支持按扩展名、状态码和参数名等过滤
+// @Router /projects/{projectId}/endpoints [get]
+func (h *EndpointHandler) GetEndpointsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.EndpointListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Endpoint{}).Where("project_id = ?", projectID)
+	if exts := splitList(req.Extension); len(exts) > 0 {
+		for i := range exts {
+			exts[i] = strings.TrimPrefix(strings.ToLower(exts[i]), ".")
+		}
+		query = query.Where("extension IN ?", exts)
+	}
+	if statuses := splitList(req.Status); len(statuses) > 0 {
+		codes := make([]int, 0, len(statuses))
+		for _, s := range statuses {
+			code, err := strconv.Atoi(s)
+			if err != nil {
+				response.BadRequest(c, fmt.Sprintf("无效的状态码 '%s'", s), err)
+				return
+			}
+			codes = append(codes, code)
+		}
+		query = query.Where("status_code IN ?", codes)
+	}
+	if params := splitList(req.Param); len(params) > 0 {
+		contains, _ := json.Marshal(params)
+		query = query.Where("parameters @> ?::jsonb", string(contains))
+	}
+	if req.Host != "" {
+		query = query.Where("host = ?", strings.ToLower(req.Host))
+	}
+	if req.DomainID != 0 {
+		query = query.Where("domain_id = ?", req.DomainID)
+	}
+	if req.Method != "" {
+		query = query.Where("method = ?", strings.ToUpper(req.Method))
+	}
+	if req.Source != "" {
+		query = query.Where("source = ?", req.Source)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(e model.Endpoint) (time.Time, uint) {
+		return e.CreatedAt, e.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	endpointDTOs := make([]dto.EndpointResponse, 0, len(page.Items))
+	for i := range page.Items {
+		endpointDTOs = append(endpointDTOs, toEndpointResponse(&page.Items[i]))
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, endpointDTOs))
+}
+
+// splitList 按逗号切分查询参数并去掉空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func toEndpointResponse(endpoint *model.Endpoint) dto.EndpointResponse {
+	params := endpoint.Parameters
+	if params == nil {
+		params = model.JSONBArray{}
+	}
+	return dto.EndpointResponse{
+		ID:            endpoint.ID,
+		DomainID:      endpoint.DomainID,
+		URL:           endpoint.URL,
+		Method:        endpoint.Method,
+		Host:          endpoint.Host,
+		Path:          endpoint.Path,
+		Extension:     endpoint.Extension,
+		Parameters:    params,
+		StatusCode:    endpoint.StatusCode,
+		ContentType:   endpoint.ContentType,
+		ContentLength: endpoint.ContentLength,
+		Source:        endpoint.Source,
+		FirstSeenAt:   endpoint.CreatedAt,
+		LastSeenAt:    endpoint.LastSeenAt,
+	}
+}
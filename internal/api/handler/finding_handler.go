@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FindingHandler struct {
+	DB *gorm.DB
+}
+
+func NewFindingHandler(db *gorm.DB) *FindingHandler {
+	return &FindingHandler{DB: db}
+}
+
+// GetFindingsByProject 分页获取项目下的漏洞发现，支持按严重程度、状态、模板、主机和关联实体过滤
+// @Router /projects/{projectId}/findings [get]
+func (h *FindingHandler) GetFindingsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.FindingListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Finding{}).Where("project_id = ?", projectID)
+	if severities := splitList(strings.ToLower(req.Severity)); len(severities) > 0 {
+		query = query.Where("severity IN ?", severities)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.TemplateID != "" {
+		query = query.Where("template_id = ?", req.TemplateID)
+	}
+	if req.Host != "" {
+		query = query.Where("host = ?", strings.ToLower(req.Host))
+	}
+	if req.AssetID != 0 {
+		query = query.Where("asset_id = ?", req.AssetID)
+	}
+	if req.DomainID != 0 {
+		query = query.Where("domain_id = ?", req.DomainID)
+	}
+	query = filterByTags(query, model.TagEntityFinding, "findings", req.Tag)
+	// 列表不返回体积较大的请求/响应证据
+	query = query.Omit("request", "response")
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(f model.Finding) (time.Time, uint) {
+		return f.CreatedAt, f.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	findingDTOs := make([]dto.FindingResponse, 0, len(page.Items))
+	for i := range page.Items {
+		findingDTOs = append(findingDTOs, toFindingResponse(&page.Items[i]))
+	}
+	if err := attachFindingTags(h.DB, findingDTOs); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, findingDTOs))
+}
+
+// GetFindingByID 获取单个漏洞发现，包含请求/响应证据
+// @Router /findings/{id} [get]
+func (h *FindingHandler) GetFindingByID(c *gin.Context) {
+	finding, ok := h.loadFinding(c)
+	if !ok {
+		return
+	}
+	findingDTOs := []dto.FindingResponse{toFindingResponse(finding)}
+	if err := attachFindingTags(h.DB, findingDTOs); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, findingDTOs[0])
+}
+
+// UpdateFinding 更新单个漏洞发现的分诊状态
+// @Router /findings/{id} [put]
+func (h *FindingHandler) UpdateFinding(c *gin.Context) {
+	finding, ok := h.loadFinding(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateFindingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	if err := h.DB.Model(finding).Update("status", req.Status).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	finding.Status = req.Status
+	response.OkWithMessage(c, "更新漏洞状态成功", toFindingResponse(finding))
+}
+
+// BulkUpdateFindings 批量更新项目下漏洞发现的分诊状态，返回实际更新的数量
+// @Router /projects/{projectId}/findings/status [put]
+func (h *FindingHandler) BulkUpdateFindings(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.BulkUpdateFindingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	result := h.DB.Model(&model.Finding{}).
+		Where("project_id = ? AND id IN ?", projectID, req.IDs).
+		Update("status", req.Status)
+	if result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	}
+	response.OkWithMessage(c, "批量更新漏洞状态成功", gin.H{"updated": result.RowsAffected})
+}
+
+// loadFinding 解析URL中的漏洞ID并查询漏洞发现，失败时直接写入响应
+func (h *FindingHandler) loadFinding(c *gin.Context) (*model.Finding, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的漏洞ID", err)
+		return nil, false
+	}
+	var finding model.Finding
+	if err := h.DB.First(&finding, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &finding, true
+}
+
+func toFindingResponse(finding *model.Finding) dto.FindingResponse {
+	extracted := finding.ExtractedResults
+	if extracted == nil {
+		extracted = model.JSONBArray{}
+	}
+	return dto.FindingResponse{
+		ID:               finding.ID,
+		ProjectID:        finding.ProjectID,
+		AssetID:          finding.AssetID,
+		DomainID:         finding.DomainID,
+		Fingerprint:      finding.Fingerprint,
+		TemplateID:       finding.TemplateID,
+		Name:             finding.Name,
+		Severity:         finding.Severity,
+		Type:             finding.Type,
+		Host:             finding.Host,
+		IP:               finding.IP,
+		Port:             finding.Port,
+		MatchedAt:        finding.MatchedAt,
+		MatcherName:      finding.MatcherName,
+		ExtractedResults: extracted,
+		Request:          finding.Request,
+		Response:         finding.Response,
+		Status:           finding.Status,
+		FirstSeenAt:      finding.CreatedAt,
+		LastSeenAt:       finding.LastSeenAt,
+		UpdatedAt:        finding.UpdatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+// newTestProject 在测试库中创建一个项目
+func newTestProject(t *testing.T, db *gorm.DB) model.Project {
+	t.Helper()
+	project := model.Project{Name: "handler-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+// serve 以指定的路径参数调用处理函数，把响应中的 data 解码到 data 中并返回HTTP状态码
+func serve(t *testing.T, handle gin.HandlerFunc, method, target string, body io.Reader, params gin.Params, data interface{}) int {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = params
+	c.Request = httptest.NewRequest(method, target, body)
+
+	handle(c)
+	if data != nil {
+		resp := struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("解析响应失败: %v, body = %s", err, w.Body.String())
+		}
+	}
+	return w.Code
+}
+
+// projectParams 返回只包含项目ID的路径参数
+func projectParams(projectID uint) gin.Params {
+	return gin.Params{{Key: "projectId", Value: strconv.FormatUint(uint64(projectID), 10)}}
+}
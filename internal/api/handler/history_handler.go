@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+type HistoryHandler struct {
+	DB *gorm.DB
+}
+
+func NewHistoryHandler(db *gorm.DB) *HistoryHandler {
+	return &HistoryHandler{DB: db}
+}
+
+// GetDomainTimeline 获取域名的历史时间线：每次运行何时观测到它
+// @Router /projects/{projectId}/domains/{domainId}/timeline [get]
+func (h *HistoryHandler) GetDomainTimeline(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+	domainID, err := strconv.Atoi(c.Param("domainId"))
+	if err != nil {
+		response.BadRequest(c, "无效的域名ID", err)
+		return
+	}
+
+	var domain model.Domain
+	if err := h.DB.Where("project_id = ?", projectID).First(&domain, uint(domainID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	h.respondTimeline(c, dto.TimelineResponse{
+		Kind:        model.ObservationKindDomain,
+		EntityID:    domain.ID,
+		Value:       domain.FQDN,
+		FirstSeenAt: domain.CreatedAt,
+		LastSeenAt:  domain.LastSeenAt,
+		IsGone:      domain.IsGone,
+	})
+}
+
+// GetAssetTimeline 获取资产的历史时间线：每次运行观测到的标题、Web服务器和技术栈
+// @Router /projects/{projectId}/assets/{assetId}/timeline [get]
+func (h *HistoryHandler) GetAssetTimeline(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+	assetID, err := strconv.Atoi(c.Param("assetId"))
+	if err != nil {
+		response.BadRequest(c, "无效的资产ID", err)
+		return
+	}
+
+	var asset model.Asset
+	if err := h.DB.Where("project_id = ?", projectID).First(&asset, uint(assetID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	h.respondTimeline(c, dto.TimelineResponse{
+		Kind:        model.ObservationKindAsset,
+		EntityID:    asset.ID,
+		Value:       asset.Address(),
+		FirstSeenAt: asset.CreatedAt,
+		LastSeenAt:  asset.LastSeenAt,
+		IsGone:      asset.IsGone,
+	})
+}
+
+// respondTimeline 分页查询实体的观测记录并写入响应
+func (h *HistoryHandler) respondTimeline(c *gin.Context, timeline dto.TimelineResponse) {
+	var req dto.PaginationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "分页参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Observation{}).Where("kind = ? AND entity_id = ?", timeline.Kind, timeline.EntityID)
+	page, err := pagination.Find(query, &req, func(o model.Observation) (time.Time, uint) {
+		return o.CreatedAt, o.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	observationDTOs := make([]dto.ObservationResponse, 0, len(page.Items))
+	for _, o := range page.Items {
+		observationDTOs = append(observationDTOs, dto.ObservationResponse{
+			ID:           o.ID,
+			WorkflowID:   o.WorkflowID,
+			Title:        o.Title,
+			WebServer:    o.WebServer,
+			Technologies: o.Technologies,
+			ObservedAt:   o.ObservedAt,
+		})
+	}
+	timeline.Observations = pagination.Response(&req, page, observationDTOs)
+	response.Ok(c, timeline)
+}
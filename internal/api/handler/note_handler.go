@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type NoteHandler struct {
+	DB *gorm.DB
+}
+
+func NewNoteHandler(db *gorm.DB) *NoteHandler {
+	return &NoteHandler{DB: db}
+}
+
+// GetNotesByProject 分页获取项目下的备注，可按实体或作者过滤
+// @Router /projects/{projectId}/notes [get]
+func (h *NoteHandler) GetNotesByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.NoteListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.Note{}).Where("project_id = ?", projectID)
+	if req.EntityType != "" {
+		query = query.Where("entity_type = ?", req.EntityType)
+	}
+	if req.EntityID != 0 {
+		query = query.Where("entity_id = ?", req.EntityID)
+	}
+	if req.Author != "" {
+		query = query.Where("author = ?", req.Author)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(n model.Note) (time.Time, uint) {
+		return n.CreatedAt, n.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	noteDTOs := make([]dto.NoteResponse, 0, len(page.Items))
+	for i := range page.Items {
+		noteDTOs = append(noteDTOs, toNoteResponse(&page.Items[i]))
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, noteDTOs))
+}
+
+// CreateNote 为项目下的域名、资产或漏洞发现添加一条备注
+// @Router /projects/{projectId}/notes [post]
+func (h *NoteHandler) CreateNote(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	author := strings.TrimSpace(req.Author)
+	if author == "" || strings.TrimSpace(req.Content) == "" {
+		response.BadRequest(c, "备注作者和内容不能为空", nil)
+		return
+	}
+	if !entitiesExist(c, h.DB, uint(projectID), req.EntityType, []uint{req.EntityID}) {
+		return
+	}
+
+	note := model.Note{
+		ProjectID:  uint(projectID),
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Author:     author,
+		Content:    req.Content,
+	}
+	if err := h.DB.Create(&note).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "添加备注成功", toNoteResponse(&note))
+}
+
+// UpdateNote 修改备注内容，作者和创建时间保持不变
+// @Router /notes/{id} [put]
+func (h *NoteHandler) UpdateNote(c *gin.Context) {
+	note, ok := h.loadNote(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		response.BadRequest(c, "备注内容不能为空", nil)
+		return
+	}
+
+	if err := h.DB.Model(note).Update("content", req.Content).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	note.Content = req.Content
+	response.OkWithMessage(c, "更新备注成功", toNoteResponse(note))
+}
+
+// DeleteNote 删除一条备注
+// @Router /notes/{id} [delete]
+func (h *NoteHandler) DeleteNote(c *gin.Context) {
+	note, ok := h.loadNote(c)
+	if !ok {
+		return
+	}
+	if err := h.DB.Delete(note).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "删除备注成功", nil)
+}
+
+// loadNote 解析URL中的备注ID并查询备注，失败时直接写入响应
+func (h *NoteHandler) loadNote(c *gin.Context) (*model.Note, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的备注ID", err)
+		return nil, false
+	}
+	var note model.Note
+	if err := h.DB.First(&note, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &note, true
+}
+
+func toNoteResponse(note *model.Note) dto.NoteResponse {
+	return dto.NoteResponse{
+		ID:         note.ID,
+		ProjectID:  note.ProjectID,
+		EntityType: note.EntityType,
+		EntityID:   note.EntityID,
+		Author:     note.Author,
+		Content:    note.Content,
+		CreatedAt:  note.CreatedAt,
+		UpdatedAt:  note.UpdatedAt,
+	}
+}
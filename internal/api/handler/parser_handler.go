@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxParserSampleSize 是试运行解析器时样例输出的大小上限
+const maxParserSampleSize = 10 << 20
+
+type ParserHandler struct{}
+
+func NewParserHandler() *ParserHandler {
+	return &ParserHandler{}
+}
+
+// GetParsers 列出所有可用的输出解析器及其期望的输入格式和产出的实体类型
+// @Router /parsers [get]
+func (h *ParserHandler) GetParsers(c *gin.Context) {
+	response.Ok(c, parser.List())
+}
+
+// TestParser 使用上传的样例输出试运行解析器，返回解析结果但不入库
+// @Router /parsers/{name}/test [post]
+func (h *ParserHandler) TestParser(c *gin.Context) {
+	name := c.Param("name")
+	if _, ok := parser.Lookup(name); !ok {
+		response.NotFound(c)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxParserSampleSize)
+	output, config, err := readParserSample(c)
+	if err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	step := model.WorkflowStep{Name: name, OutputParserType: name, ParserConfig: config}
+	p, err := parser.ForStep(&step)
+	if err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+	result, err := p.Parse(output)
+	if err != nil {
+		response.Fail(c, "解析失败: "+err.Error())
+		return
+	}
+	response.Ok(c, toParserTestResponse(name, result))
+}
+
+// readParserSample 从JSON请求体或 multipart 表单中读取样例输出和可选的通用解析器配置
+func readParserSample(c *gin.Context) ([]byte, *model.GenericParserConfig, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		var req dto.ParserTestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, nil, err
+		}
+		return []byte(req.Output), req.ParserConfig, nil
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, nil, errors.New("缺少样例输出文件 file")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	output, err := io.ReadAll(io.LimitReader(file, maxParserSampleSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var config *model.GenericParserConfig
+	if raw := c.PostForm("parserConfig"); raw != "" {
+		config = &model.GenericParserConfig{}
+		if err := json.Unmarshal([]byte(raw), config); err != nil {
+			return nil, nil, errors.New("parserConfig 不是合法的JSON")
+		}
+	}
+	return output, config, nil
+}
+
+func toParserTestResponse(name string, result *parser.ParseResult) dto.ParserTestResponse {
+	resp := dto.ParserTestResponse{
+		Parser: name,
+		Counts: map[string]int{
+			parser.EntityDomain:       len(result.Domains),
+			parser.EntityAsset:        len(result.Assets),
+			parser.EntityRelationship: len(result.Relationships),
+			parser.EntityFinding:      len(result.Findings),
+			parser.EntityEndpoint:     len(result.Endpoints),
+			parser.EntityDNSRecord:    len(result.DNSRecords),
+			parser.EntityCertificate:  len(result.Certificates),
+		},
+		Domains:       toDomainResponses(result.Domains),
+		Assets:        toAssetResponses(result.Assets),
+		Relationships: make([]dto.ParserRelationshipResponse, 0, len(result.Relationships)),
+		Findings:      make([]dto.FindingResponse, 0, len(result.Findings)),
+		Endpoints:     make([]dto.EndpointResponse, 0, len(result.Endpoints)),
+		DNSRecords:    make([]dto.DNSRecordResponse, 0, len(result.DNSRecords)),
+		Certificates:  make([]dto.CertificateResponse, 0, len(result.Certificates)),
+	}
+	if resp.Domains == nil {
+		resp.Domains = []dto.DomainResponse{}
+	}
+	if resp.Assets == nil {
+		resp.Assets = []dto.AssetResponse{}
+	}
+	for _, r := range result.Relationships {
+		resp.Relationships = append(resp.Relationships, dto.ParserRelationshipResponse{
+			From: dto.ParserEntityRef{Type: r.From.Type, Key: r.From.Key},
+			To:   dto.ParserEntityRef{Type: r.To.Type, Key: r.To.Key},
+		})
+	}
+	for i := range result.Findings {
+		resp.Findings = append(resp.Findings, toFindingResponse(&result.Findings[i]))
+	}
+	for i := range result.Endpoints {
+		resp.Endpoints = append(resp.Endpoints, toEndpointResponse(&result.Endpoints[i]))
+	}
+	for i := range result.DNSRecords {
+		resp.DNSRecords = append(resp.DNSRecords, toDNSRecordResponse(&result.DNSRecords[i]))
+	}
+	now := time.Now()
+	for i := range result.Certificates {
+		resp.Certificates = append(resp.Certificates, toCertificateResponse(&result.Certificates[i], now, defaultExpiringDays))
+	}
+	return resp
+}
@@ -1,24 +1,30 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/src-hunter/internal/api/dto"
 	"github.com/src-hunter/internal/api/response"
 	"github.com/src-hunter/internal/api/validator"
 	"github.com/src-hunter/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strconv"
 )
 
 type ProjectHandler struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	AsynqClient *asynq.Client
 }
 
-func NewProjectHandler(db *gorm.DB) *ProjectHandler {
+func NewProjectHandler(db *gorm.DB, asynqClient *asynq.Client) *ProjectHandler {
 	return &ProjectHandler{
-		DB: db,
+		DB:          db,
+		AsynqClient: asynqClient,
 	}
 }
 
@@ -33,6 +39,10 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		Description: req.Description,
 	}
 	if result := h.DB.Create(&project); result.Error != nil {
+		if isUniqueViolation(result.Error) {
+			response.BadRequest(c, "项目名称已存在", result.Error)
+			return
+		}
 		response.ServerError(c, result.Error)
 		return
 	}
@@ -72,19 +82,25 @@ func (h *ProjectHandler) AddTargetsToProject(c *gin.Context) {
 			return err
 		}
 
-		// 3.2 遍历并创建目标
+		// 3.2 遍历并创建目标，已存在的目标直接跳过
 		for _, t := range req.Targets {
 			target := model.ProjectTarget{
 				ProjectID:   uint(projectID),
-				Value:       t.Value,
+				Value:       validator.NormalizeTargetValue(t.Value),
 				Type:        t.Type,
 				Description: t.Description,
+				IsActive:    true,
 			}
-			if err := tx.Create(&target).Error; err != nil {
-				// 如果发生错误（比如唯一性冲突），则回滚整个事务
-				return fmt.Errorf("创建目标 '%s' 失败: %w", t.Value, err)
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "project_id"}, {Name: "value"}},
+				DoNothing: true,
+			}).Create(&target)
+			if result.Error != nil {
+				return fmt.Errorf("创建目标 '%s' 失败: %w", t.Value, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				createdTargets = append(createdTargets, target)
 			}
-			createdTargets = append(createdTargets, target)
 		}
 
 		// 事务将在函数成功返回时自动提交
@@ -95,7 +111,7 @@ func (h *ProjectHandler) AddTargetsToProject(c *gin.Context) {
 		if err.Error() == "project_not_found" {
 			response.BadRequest(c, "项目ID不存在", err)
 		} else {
-			// 其他所有错误（包括目标重复）都视为内部错误或冲突
+			// 其他所有错误都视为内部错误
 			response.ServerError(c, err)
 		}
 		return
@@ -193,3 +209,224 @@ func (h *ProjectHandler) GetProjectByID(c *gin.Context) {
 	// 4. 返回成功的响应
 	response.Ok(c, projectDTO)
 }
+
+// UpdateProject 更新项目的名称和描述
+// @Router /projects/{projectId} [put]
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	if project.Status == model.ProjectStatusDeleting {
+		response.Fail(c, "项目正在删除中，无法修改")
+		return
+	}
+
+	var req dto.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	// 按需更新字段
+	updates := make(map[string]interface{})
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if len(updates) > 0 {
+		if err := h.DB.Model(project).Updates(updates).Error; err != nil {
+			if isUniqueViolation(err) {
+				response.BadRequest(c, "项目名称已存在", err)
+				return
+			}
+			response.ServerError(c, err)
+			return
+		}
+	}
+
+	response.OkWithMessage(c, "更新项目成功", toProjectResponse(project))
+}
+
+// ArchiveProject 归档项目，归档后的项目将拒绝新的扫描
+// @Router /projects/{projectId}/archive [post]
+func (h *ProjectHandler) ArchiveProject(c *gin.Context) {
+	h.changeStatus(c, model.ProjectStatusActive, model.ProjectStatusArchived, "项目已归档")
+}
+
+// UnarchiveProject 取消归档，恢复项目为活跃状态
+// @Router /projects/{projectId}/unarchive [post]
+func (h *ProjectHandler) UnarchiveProject(c *gin.Context) {
+	h.changeStatus(c, model.ProjectStatusArchived, model.ProjectStatusActive, "项目已恢复为活跃状态")
+}
+
+func (h *ProjectHandler) changeStatus(c *gin.Context, from, to, msg string) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+	if project.Status == to {
+		response.OkWithMessage(c, msg, toProjectResponse(project))
+		return
+	}
+	if project.Status != from {
+		response.Fail(c, fmt.Sprintf("项目当前状态为 '%s'，无法变更为 '%s'", project.Status, to))
+		return
+	}
+	// 带上原状态作为条件，避免与并发的删除请求相互覆盖
+	result := h.DB.Model(&model.Project{}).
+		Where("id = ? AND status = ?", project.ID, from).
+		Update("status", to)
+	if result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		response.Fail(c, "项目状态已被其他操作修改，请刷新后重试")
+		return
+	}
+	project.Status = to
+	response.OkWithMessage(c, msg, toProjectResponse(project))
+}
+
+// projectDeleteTaskType 是项目级联删除任务的类型
+const projectDeleteTaskType = "maintenance:project:delete"
+
+// errProjectAlreadyDeleting 表示项目已有进行中的删除任务
+var errProjectAlreadyDeleting = errors.New("project_already_deleting")
+
+// DeleteProject 将项目标记为删除中，并派发后台任务分批级联删除其所有数据。
+// 上一次删除任务失败时可以重新发起删除。
+// @Router /projects/{projectId} [delete]
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	project, ok := h.loadProject(c)
+	if !ok {
+		return
+	}
+
+	// 1. 在事务中锁定项目，标记为删除中并记录删除任务。
+	// 删除任务本身也记录为一个 Task，用于追踪进度
+	var deleteTask model.Task
+	previousStatus := project.Status
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var locked model.Project
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&locked, project.ID).Error; err != nil {
+			return err
+		}
+		previousStatus = locked.Status
+		if locked.Status == model.ProjectStatusDeleting {
+			var last model.Task
+			err := tx.Where("project_id = ? AND type = ?", project.ID, projectDeleteTaskType).
+				Order("id DESC").Limit(1).Find(&last).Error
+			if err != nil {
+				return err
+			}
+			// 没有删除任务记录或上一次删除已失败时允许重新发起
+			if last.ID != 0 && last.Status != "failed" {
+				return errProjectAlreadyDeleting
+			}
+		} else if err := tx.Model(&model.Project{}).Where("id = ?", project.ID).
+			Update("status", model.ProjectStatusDeleting).Error; err != nil {
+			return err
+		}
+
+		payloadBytes, _ := json.Marshal(map[string]interface{}{
+			"project_id": project.ID,
+		})
+		deleteTask = model.Task{
+			ProjectID: project.ID,
+			Type:      projectDeleteTaskType,
+			Queue:     "low",
+			Status:    "pending",
+			Payload:   payloadBytes,
+		}
+		return tx.Create(&deleteTask).Error
+	})
+	if err != nil {
+		if errors.Is(err, errProjectAlreadyDeleting) {
+			response.Fail(c, "项目已在删除中")
+		} else {
+			response.ServerError(c, err)
+		}
+		return
+	}
+
+	// 2. 事务提交后再派发，保证 worker 执行时能看到删除中的状态和任务记录
+	taskPayload, _ := json.Marshal(map[string]interface{}{
+		"project_id": project.ID,
+		"task_id":    deleteTask.ID,
+	})
+	info, err := h.AsynqClient.Enqueue(asynq.NewTask(deleteTask.Type, taskPayload), asynq.Queue("low"))
+	if err != nil {
+		if revertErr := h.revertProjectDelete(project.ID, previousStatus, &deleteTask, err); revertErr != nil {
+			err = errors.Join(err, revertErr)
+		}
+		response.ServerError(c, err)
+		return
+	}
+	h.DB.Model(&deleteTask).Update("asynq_id", info.ID)
+
+	response.OkWithMessage(c, "项目删除任务已提交，将在后台分批执行", gin.H{
+		"taskId": deleteTask.ID,
+	})
+}
+
+// revertProjectDelete 在删除任务派发失败时把删除任务标记为失败，并恢复项目原来的状态。
+// 重新发起的删除 (原状态已是删除中) 保持删除中，之后可以再次发起。
+func (h *ProjectHandler) revertProjectDelete(projectID uint, previousStatus string, deleteTask *model.Task, cause error) error {
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(deleteTask).Updates(map[string]interface{}{
+			"status": "failed",
+			"result": fmt.Sprintf("派发删除任务失败: %v", cause),
+		}).Error; err != nil {
+			return err
+		}
+		if previousStatus == model.ProjectStatusDeleting {
+			return nil
+		}
+		return tx.Model(&model.Project{}).
+			Where("id = ? AND status = ?", projectID, model.ProjectStatusDeleting).
+			Update("status", previousStatus).Error
+	})
+}
+
+// loadProject 解析URL中的项目ID并查询项目，失败时直接写入响应
+func (h *ProjectHandler) loadProject(c *gin.Context) (*model.Project, bool) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return nil, false
+	}
+	var project model.Project
+	if err := h.DB.First(&project, uint(projectID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &project, true
+}
+
+func toProjectResponse(project *model.Project) dto.ProjectResponse {
+	return dto.ProjectResponse{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Status:      project.Status,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	}
+}
+
+// uniqueViolation 是 PostgreSQL 唯一约束冲突的错误码
+const uniqueViolation = "23505"
+
+// isUniqueViolation 判断错误是否为数据库唯一约束冲突
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+)
+
+func TestCreateScanRejectsActiveTargetsWithTargetIDs(t *testing.T) {
+	body := `{"projectId":1,"scanProfileId":1,"useActiveTargets":true,"targetIds":[3,4]}`
+	if code := serve(t, NewScanHandler(nil, nil).CreateScan, http.MethodPost, "/", strings.NewReader(body), nil, nil); code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", code)
+	}
+}
+
+func TestValidateScheduleRejectsActiveTargetsWithTargetIDs(t *testing.T) {
+	schedule := model.ScanSchedule{CronExpr: "@daily", UseActiveTargets: true, TargetIDs: []uint{3}}
+	err := NewScheduleHandler(nil).validateSchedule(&schedule)
+	if err == nil || !strings.Contains(err.Error(), "不能同时指定") {
+		t.Errorf("err = %v, want 不能同时指定", err)
+	}
+}
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/src-hunter/internal/api/dto"
 	"github.com/src-hunter/internal/api/response"
 	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +27,10 @@ func (h *ScanProfileHandler) CreateScanProfile(c *gin.Context) {
 		response.BadRequest(c, "请求参数错误", err)
 		return
 	}
+	if err := validateWorkflowSteps(req.WorkflowSteps); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
 
 	profile := model.ScanProfile{
 		Name:          req.Name,
@@ -88,6 +94,10 @@ func (h *ScanProfileHandler) UpdateScanProfile(c *gin.Context) {
 		response.BadRequest(c, "请求参数错误", err)
 		return
 	}
+	if err := validateWorkflowSteps(req.WorkflowSteps); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
 
 	// 按需更新字段
 	updates := make(map[string]interface{})
@@ -128,3 +138,19 @@ func (h *ScanProfileHandler) DeleteScanProfile(c *gin.Context) {
 
 	response.OkWithMessage(c, "删除成功", nil)
 }
+
+// validateWorkflowSteps 校验工作流步骤的扇出键和解析器配置，避免错误的模板在运行时才失败
+func validateWorkflowSteps(steps []model.WorkflowStep) error {
+	for i := range steps {
+		step := &steps[i]
+		switch step.FanOutKey {
+		case "", model.FanOutKeyFQDN, model.FanOutKeyHostPort:
+		default:
+			return fmt.Errorf("步骤 '%s' 的 fan_out_key '%s' 无效，可选值: %s, %s", step.Name, step.FanOutKey, model.FanOutKeyFQDN, model.FanOutKeyHostPort)
+		}
+		if err := parser.ValidateStep(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
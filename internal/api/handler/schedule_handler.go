@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/scheduler"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+type ScheduleHandler struct {
+	DB *gorm.DB
+}
+
+func NewScheduleHandler(db *gorm.DB) *ScheduleHandler {
+	return &ScheduleHandler{DB: db}
+}
+
+// GetSchedulesByProject 获取项目下的所有扫描计划
+// @Router /projects/{projectId}/schedules [get]
+func (h *ScheduleHandler) GetSchedulesByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var schedules []model.ScanSchedule
+	if err := h.DB.Where("project_id = ?", projectID).Order("id").Find(&schedules).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	scheduleDTOs := make([]dto.ScheduleResponse, 0, len(schedules))
+	for i := range schedules {
+		scheduleDTOs = append(scheduleDTOs, toScheduleResponse(&schedules[i]))
+	}
+	response.Ok(c, scheduleDTOs)
+}
+
+// CreateSchedule 为项目创建一个周期性扫描计划
+// @Router /projects/{projectId}/schedules [post]
+func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	schedule := model.ScanSchedule{
+		ProjectID:        uint(projectID),
+		ScanProfileID:    req.ScanProfileID,
+		Name:             req.Name,
+		Description:      req.Description,
+		CronExpr:         req.CronExpr,
+		UseActiveTargets: req.UseActiveTargets,
+		TargetIDs:        req.TargetIDs,
+		IsEnabled:        req.IsEnabled == nil || *req.IsEnabled,
+	}
+	if err := h.validateSchedule(&schedule); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	if err := h.DB.Create(&schedule).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "创建扫描计划成功", toScheduleResponse(&schedule))
+}
+
+// GetScheduleByID 根据ID获取单个扫描计划
+// @Router /schedules/{id} [get]
+func (h *ScheduleHandler) GetScheduleByID(c *gin.Context) {
+	schedule, ok := h.loadSchedule(c)
+	if !ok {
+		return
+	}
+	response.Ok(c, toScheduleResponse(schedule))
+}
+
+// UpdateSchedule 更新一个扫描计划，修改 cron 表达式或重新启用时会重新计算下一次执行时间
+// @Router /schedules/{id} [put]
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	schedule, ok := h.loadSchedule(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	// 先在副本上应用修改并整体校验，再写回数据库
+	wasEnabled, oldExpr := schedule.IsEnabled, schedule.CronExpr
+	if req.Name != "" {
+		schedule.Name = req.Name
+	}
+	if req.Description != nil {
+		schedule.Description = *req.Description
+	}
+	if req.ScanProfileID != 0 {
+		schedule.ScanProfileID = req.ScanProfileID
+	}
+	if req.CronExpr != "" {
+		schedule.CronExpr = req.CronExpr
+	}
+	if req.UseActiveTargets != nil {
+		schedule.UseActiveTargets = *req.UseActiveTargets
+	}
+	if req.TargetIDs != nil {
+		schedule.TargetIDs = req.TargetIDs
+	}
+	if req.IsEnabled != nil {
+		schedule.IsEnabled = *req.IsEnabled
+	}
+	nextRunAt := schedule.NextRunAt
+	if err := h.validateSchedule(schedule); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+	// validateSchedule 会重新计算 next_run_at；未修改 cron 表达式且未重新启用时保留原值
+	if schedule.CronExpr == oldExpr && (wasEnabled || !schedule.IsEnabled) {
+		schedule.NextRunAt = nextRunAt
+	}
+
+	if err := h.DB.Model(schedule).Select(
+		"name", "description", "scan_profile_id", "cron_expr", "use_active_targets",
+		"target_ids", "is_enabled", "next_run_at",
+	).Updates(schedule).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "更新扫描计划成功", toScheduleResponse(schedule))
+}
+
+// DeleteSchedule 删除一个扫描计划
+// @Router /schedules/{id} [delete]
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的计划ID", err)
+		return
+	}
+
+	if result := h.DB.Unscoped().Delete(&model.ScanSchedule{}, uint(id)); result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	} else if result.RowsAffected == 0 {
+		response.NotFound(c)
+		return
+	}
+	response.OkWithMessage(c, "删除扫描计划成功", nil)
+}
+
+// validateSchedule 校验计划引用的项目、模板和目标，并计算下一次执行时间
+func (h *ScheduleHandler) validateSchedule(schedule *model.ScanSchedule) error {
+	next, err := scheduler.NextRun(schedule.CronExpr, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = next
+
+	schedule.TargetIDs = dedupeIDs(schedule.TargetIDs)
+	if !schedule.UseActiveTargets && len(schedule.TargetIDs) == 0 {
+		return errors.New("请启用 useActiveTargets 或指定 targetIds")
+	}
+	if schedule.UseActiveTargets && len(schedule.TargetIDs) > 0 {
+		return errors.New("useActiveTargets 与 targetIds 不能同时指定，使用全部启用的目标时请将 targetIds 置空")
+	}
+
+	var project model.Project
+	if err := h.DB.First(&project, schedule.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("项目ID不存在")
+		}
+		return err
+	}
+	var profile model.ScanProfile
+	if err := h.DB.First(&profile, schedule.ScanProfileID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("扫描模板不存在")
+		}
+		return err
+	}
+	if len(schedule.TargetIDs) > 0 {
+		var count int64
+		if err := h.DB.Model(&model.ProjectTarget{}).
+			Where("project_id = ? AND id IN ?", schedule.ProjectID, []uint(schedule.TargetIDs)).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(schedule.TargetIDs) {
+			return fmt.Errorf("部分目标ID不存在或不属于项目 %d", schedule.ProjectID)
+		}
+	}
+	return nil
+}
+
+// dedupeIDs 去掉重复的ID，保留首次出现的顺序
+func dedupeIDs(ids model.JSONBUintArray) model.JSONBUintArray {
+	if len(ids) == 0 {
+		return ids
+	}
+	seen := make(map[uint]bool, len(ids))
+	unique := make(model.JSONBUintArray, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// loadSchedule 解析URL中的计划ID并查询计划，失败时直接写入响应
+func (h *ScheduleHandler) loadSchedule(c *gin.Context) (*model.ScanSchedule, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的计划ID", err)
+		return nil, false
+	}
+	var schedule model.ScanSchedule
+	if err := h.DB.First(&schedule, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &schedule, true
+}
+
+func toScheduleResponse(schedule *model.ScanSchedule) dto.ScheduleResponse {
+	return dto.ScheduleResponse{
+		ID:               schedule.ID,
+		ProjectID:        schedule.ProjectID,
+		ScanProfileID:    schedule.ScanProfileID,
+		Name:             schedule.Name,
+		Description:      schedule.Description,
+		CronExpr:         schedule.CronExpr,
+		UseActiveTargets: schedule.UseActiveTargets,
+		TargetIDs:        schedule.TargetIDs,
+		IsEnabled:        schedule.IsEnabled,
+		NextRunAt:        schedule.NextRunAt,
+		LastRunAt:        schedule.LastRunAt,
+		LastTaskID:       schedule.LastTaskID,
+		LastRunStatus:    schedule.LastRunStatus,
+		LastRunMessage:   schedule.LastRunMessage,
+		CreatedAt:        schedule.CreatedAt,
+	}
+}
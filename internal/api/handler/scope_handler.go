@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/scope"
+	"gorm.io/gorm"
+	"net"
+	"strconv"
+	"time"
+)
+
+type ScopeHandler struct {
+	DB *gorm.DB
+}
+
+func NewScopeHandler(db *gorm.DB) *ScopeHandler {
+	return &ScopeHandler{DB: db}
+}
+
+// GetScopeRules 获取项目的所有范围规则
+// @Router /projects/{projectId}/scope-rules [get]
+func (h *ScopeHandler) GetScopeRules(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var rules []model.ScopeRule
+	if err := h.DB.Where("project_id = ?", projectID).Order("id").Find(&rules).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	ruleDTOs := make([]dto.ScopeRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		ruleDTOs = append(ruleDTOs, toScopeRuleResponse(&r))
+	}
+	response.Ok(c, ruleDTOs)
+}
+
+// CreateScopeRule 为项目添加一条包含或排除规则
+// @Router /projects/{projectId}/scope-rules [post]
+func (h *ScopeHandler) CreateScopeRule(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateScopeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if err := scope.ValidateRule(req.Action, req.Type, req.Value); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	var project model.Project
+	if err := h.DB.First(&project, uint(projectID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "项目ID不存在", err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	rule := model.ScopeRule{
+		ProjectID:   project.ID,
+		Action:      req.Action,
+		Type:        req.Type,
+		Value:       req.Value,
+		Description: req.Description,
+	}
+	if err := h.DB.Create(&rule).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "创建范围规则成功", toScopeRuleResponse(&rule))
+}
+
+// DeleteScopeRule 删除项目的一条范围规则
+// @Router /projects/{projectId}/scope-rules/{ruleId} [delete]
+func (h *ScopeHandler) DeleteScopeRule(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+	ruleID, err := strconv.Atoi(c.Param("ruleId"))
+	if err != nil {
+		response.BadRequest(c, "无效的规则ID", err)
+		return
+	}
+
+	result := h.DB.Unscoped().Where("project_id = ?", projectID).Delete(&model.ScopeRule{}, uint(ruleID))
+	if result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	} else if result.RowsAffected == 0 {
+		response.NotFound(c)
+		return
+	}
+	response.OkWithMessage(c, "删除范围规则成功", nil)
+}
+
+// CheckScope 检查一组域名、IP、CIDR 或 host:port 是否在项目范围内，便于调试规则
+// @Router /projects/{projectId}/scope/check [post]
+func (h *ScopeHandler) CheckScope(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.ScopeCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	projectScope, err := scope.Load(h.DB, uint(projectID))
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	results := make([]dto.ScopeCheckResult, 0, len(req.Values))
+	for _, value := range req.Values {
+		var decision scope.Decision
+		if host, portStr, err := net.SplitHostPort(value); err == nil {
+			port, _ := strconv.Atoi(portStr)
+			if net.ParseIP(host) != nil {
+				decision = projectScope.CheckAsset(host, port, "")
+			} else if decision = projectScope.CheckPort(port); decision.InScope {
+				decision = projectScope.CheckHost(host)
+			}
+		} else {
+			decision = projectScope.CheckHost(value)
+		}
+		results = append(results, dto.ScopeCheckResult{Value: value, InScope: decision.InScope, Reason: decision.Reason})
+	}
+	response.Ok(c, results)
+}
+
+// GetOutOfScopeItems 分页获取工作流中被范围规则拦截的条目
+// @Router /projects/{projectId}/out-of-scope [get]
+func (h *ScopeHandler) GetOutOfScopeItems(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.OutOfScopeListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.OutOfScopeItem{}).Where("project_id = ?", projectID)
+	if req.Kind != "" {
+		query = query.Where("kind = ?", req.Kind)
+	}
+	if req.ParentTaskID != 0 {
+		query = query.Where("parent_task_id = ?", req.ParentTaskID)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(item model.OutOfScopeItem) (time.Time, uint) {
+		return item.CreatedAt, item.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	var itemDTOs []dto.OutOfScopeItemResponse
+	for _, item := range page.Items {
+		itemDTOs = append(itemDTOs, dto.OutOfScopeItemResponse{
+			ID:           item.ID,
+			TaskID:       item.TaskID,
+			ParentTaskID: item.ParentTaskID,
+			Kind:         item.Kind,
+			Value:        item.Value,
+			Reason:       item.Reason,
+			CreatedAt:    item.CreatedAt,
+		})
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, itemDTOs))
+}
+
+func toScopeRuleResponse(rule *model.ScopeRule) dto.ScopeRuleResponse {
+	return dto.ScopeRuleResponse{
+		ID:          rule.ID,
+		Action:      rule.Action,
+		Type:        rule.Type,
+		Value:       rule.Value,
+		Description: rule.Description,
+		CreatedAt:   rule.CreatedAt,
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/search"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+type SearchHandler struct {
+	DB *gorm.DB
+}
+
+func NewSearchHandler(db *gorm.DB) *SearchHandler {
+	return &SearchHandler{DB: db}
+}
+
+// Search 使用结构化查询语句在项目的域名或资产中搜索
+// @Router /projects/{projectId}/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	// 1. 解析项目ID
+	projectIDStr := c.Param("projectId")
+	projectID, err := strconv.Atoi(projectIDStr)
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req dto.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "搜索参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	// 3. 编译查询语句，语法错误直接返回给用户
+	entity := search.Entity(req.Type)
+	cond, err := search.Compile(req.Query, entity)
+	if err != nil {
+		var syntaxErr *search.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			response.BadRequest(c, syntaxErr.Error(), err)
+			return
+		}
+		response.BadRequest(c, "", err)
+		return
+	}
+
+	// 4. 按实体类型执行查询 (偏移分页或游标分页)，附上标签后返回分页响应
+	if entity == search.EntityDomain {
+		query := h.DB.Model(&model.Domain{}).Where("project_id = ?", projectID).Where(cond.SQL, cond.Args...)
+		page, err := pagination.Find(query, &req.PaginationRequest, func(d model.Domain) (time.Time, uint) {
+			return d.CreatedAt, d.ID
+		})
+		if err != nil {
+			if errors.Is(err, pagination.ErrInvalidCursor) {
+				response.BadRequest(c, err.Error(), err)
+				return
+			}
+			response.ServerError(c, err)
+			return
+		}
+		list := toDomainResponses(page.Items)
+		if err := attachDomainTags(h.DB, list); err != nil {
+			response.ServerError(c, err)
+			return
+		}
+		response.Ok(c, pagination.Response(&req.PaginationRequest, page, list))
+		return
+	}
+
+	query := h.DB.Model(&model.Asset{}).Where("project_id = ?", projectID).Where(cond.SQL, cond.Args...)
+	page, err := pagination.Find(query, &req.PaginationRequest, func(a model.Asset) (time.Time, uint) {
+		return a.CreatedAt, a.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+	list := toAssetResponses(page.Items)
+	if err := attachIPMetadata(h.DB, list); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	if err := attachAssetTags(h.DB, list); err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, list))
+}
+
+// GetSearchFields 返回所有可搜索字段及其语法说明，用于前端自动补全
+// @Router /search/fields [get]
+func (h *SearchHandler) GetSearchFields(c *gin.Context) {
+	response.Ok(c, search.Fields())
+}
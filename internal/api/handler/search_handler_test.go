@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+)
+
+func TestSearchPagination(t *testing.T) {
+	db := testutil.DB(t)
+	project := newTestProject(t, db)
+	for _, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		if err := db.Create(&model.Asset{ProjectID: project.ID, IP: ip, Port: 80, Transport: model.TransportTCP}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	search := func(query url.Values, data interface{}) {
+		t.Helper()
+		query.Set("q", "port:80")
+		if code := serve(t, NewSearchHandler(db).Search, http.MethodGet, "/?"+query.Encode(), nil, projectParams(project.ID), data); code != http.StatusOK {
+			t.Fatalf("status = %d", code)
+		}
+	}
+
+	// 超过上限的页大小按上限处理，而不是重置为默认值
+	var page dto.PaginationResponse
+	search(url.Values{"pageSize": {"500"}}, &page)
+	if page.Total != 3 || page.PageSize != dto.MaxPageSize || len(page.List.([]interface{})) != 3 {
+		t.Errorf("偏移分页 = total %d, pageSize %d, list %v", page.Total, page.PageSize, page.List)
+	}
+
+	var cursor dto.CursorPaginationResponse
+	search(url.Values{"mode": {"cursor"}, "pageSize": {"2"}}, &cursor)
+	if !cursor.HasMore || cursor.NextCursor == "" || len(cursor.List.([]interface{})) != 2 {
+		t.Fatalf("游标分页第一页 = %+v", cursor)
+	}
+	next := dto.CursorPaginationResponse{}
+	search(url.Values{"cursor": {cursor.NextCursor}, "pageSize": {"2"}}, &next)
+	if next.HasMore || len(next.List.([]interface{})) != 1 {
+		t.Errorf("游标分页第二页 = %+v", next)
+	}
+}
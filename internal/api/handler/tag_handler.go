@@ -0,0 +1,556 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/search"
+	"github.com/src-hunter/internal/tagging"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type TagHandler struct {
+	DB *gorm.DB
+}
+
+func NewTagHandler(db *gorm.DB) *TagHandler {
+	return &TagHandler{DB: db}
+}
+
+// GetTagsByProject 获取项目的所有标签及其使用次数
+// @Router /projects/{projectId}/tags [get]
+func (h *TagHandler) GetTagsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var tags []model.Tag
+	if err := h.DB.Where("project_id = ?", projectID).Order("name").Find(&tags).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	var counts []struct {
+		TagID uint
+		Count int64
+	}
+	if err := h.DB.Model(&model.Tagging{}).Select("tag_id, COUNT(*) AS count").
+		Where("project_id = ?", projectID).Group("tag_id").Scan(&counts).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	usage := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		usage[row.TagID] = row.Count
+	}
+
+	tagDTOs := make([]dto.TagResponse, 0, len(tags))
+	for i := range tags {
+		tagDTO := toTagResponse(&tags[i])
+		tagDTO.UsageCount = usage[tags[i].ID]
+		tagDTOs = append(tagDTOs, tagDTO)
+	}
+	response.Ok(c, tagDTOs)
+}
+
+// CreateTag 为项目创建一个标签
+// @Router /projects/{projectId}/tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	name := tagging.NormalizeName(req.Name)
+	if name == "" {
+		response.BadRequest(c, "标签名称不能为空", nil)
+		return
+	}
+	if !h.projectExists(c, uint(projectID)) {
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&model.Tag{}).Where("project_id = ? AND name = ?", projectID, name).Count(&count).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	if count > 0 {
+		response.BadRequest(c, "标签名称已存在", nil)
+		return
+	}
+
+	tag := model.Tag{
+		ProjectID:   uint(projectID),
+		Name:        name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+	if err := h.DB.Create(&tag).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "创建标签成功", toTagResponse(&tag))
+}
+
+// UpdateTag 更新标签的名称、颜色或描述
+// @Router /tags/{id} [put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	tag, ok := h.loadTag(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if req.Name != nil {
+		name := tagging.NormalizeName(*req.Name)
+		if name == "" {
+			response.BadRequest(c, "标签名称不能为空", nil)
+			return
+		}
+		var count int64
+		if err := h.DB.Model(&model.Tag{}).Where("project_id = ? AND name = ? AND id <> ?", tag.ProjectID, name, tag.ID).
+			Count(&count).Error; err != nil {
+			response.ServerError(c, err)
+			return
+		}
+		if count > 0 {
+			response.BadRequest(c, "标签名称已存在", nil)
+			return
+		}
+		tag.Name = name
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+	if req.Description != nil {
+		tag.Description = *req.Description
+	}
+
+	if err := h.DB.Model(tag).Select("name", "color", "description").Updates(tag).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "更新标签成功", toTagResponse(tag))
+}
+
+// DeleteTag 删除标签，同时删除它在所有实体上的关联和引用它的自动打标签规则
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	tag, ok := h.loadTag(c)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tag.ID).Delete(&model.Tagging{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("tag_id = ?", tag.ID).Delete(&model.TagRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(tag).Error
+	})
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "删除标签成功", nil)
+}
+
+// AddTags 为一组域名、资产或漏洞发现打上标签，不存在的标签会自动创建
+// @Router /projects/{projectId}/taggings [post]
+func (h *TagHandler) AddTags(c *gin.Context) {
+	projectID, req, ok := h.bindTagging(c)
+	if !ok {
+		return
+	}
+
+	var tagged int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := tagging.EnsureTags(tx, projectID, req.Tags)
+		if err != nil {
+			return err
+		}
+		tagged, err = tagging.Add(tx, projectID, tagIDs(tags), req.EntityType, req.EntityIDs)
+		return err
+	})
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "添加标签成功", dto.TaggingResponse{Matched: int64(len(req.EntityIDs)), Changed: tagged})
+}
+
+// RemoveTags 删除一组域名、资产或漏洞发现上的指定标签
+// @Router /projects/{projectId}/taggings [delete]
+func (h *TagHandler) RemoveTags(c *gin.Context) {
+	projectID, req, ok := h.bindTagging(c)
+	if !ok {
+		return
+	}
+
+	var tags []model.Tag
+	if err := h.DB.Where("project_id = ? AND name IN ?", projectID, req.Tags).Find(&tags).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	var removed int64
+	if len(tags) > 0 {
+		var err error
+		removed, err = tagging.Remove(h.DB, projectID, tagIDs(tags), req.EntityType, req.EntityIDs)
+		if err != nil {
+			response.ServerError(c, err)
+			return
+		}
+	}
+	response.OkWithMessage(c, "删除标签成功", dto.TaggingResponse{Matched: int64(len(req.EntityIDs)), Changed: removed})
+}
+
+// BulkTag 为搜索语句命中的全部域名或资产打上标签，不存在的标签会自动创建
+// @Router /projects/{projectId}/tags/bulk [post]
+func (h *TagHandler) BulkTag(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.BulkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if req.Type == "" {
+		req.Type = string(search.EntityAsset)
+	}
+
+	// 编译查询语句，语法错误直接返回给用户
+	entity := search.Entity(req.Type)
+	cond, err := search.Compile(req.Query, entity)
+	if err != nil {
+		var syntaxErr *search.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			response.BadRequest(c, syntaxErr.Error(), err)
+			return
+		}
+		response.BadRequest(c, "", err)
+		return
+	}
+	if !h.projectExists(c, uint(projectID)) {
+		return
+	}
+
+	var m interface{} = &model.Asset{}
+	if entity == search.EntityDomain {
+		m = &model.Domain{}
+	}
+	var ids []uint
+	if err := h.DB.Model(m).Where("project_id = ?", projectID).Where(cond.SQL, cond.Args...).
+		Pluck("id", &ids).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	var tagged int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := tagging.EnsureTags(tx, uint(projectID), req.Tags)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		tagged, err = tagging.Add(tx, uint(projectID), tagIDs(tags), req.Type, ids)
+		return err
+	})
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "批量添加标签成功", dto.TaggingResponse{Matched: int64(len(ids)), Changed: tagged})
+}
+
+// GetTagRules 获取项目的所有自动打标签规则
+// @Router /projects/{projectId}/tag-rules [get]
+func (h *TagHandler) GetTagRules(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var rules []model.TagRule
+	if err := h.DB.Where("project_id = ?", projectID).Order("id").Find(&rules).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	var tags []model.Tag
+	if err := h.DB.Where("project_id = ?", projectID).Find(&tags).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	names := make(map[uint]string, len(tags))
+	for _, t := range tags {
+		names[t.ID] = t.Name
+	}
+
+	ruleDTOs := make([]dto.TagRuleResponse, 0, len(rules))
+	for i := range rules {
+		ruleDTOs = append(ruleDTOs, toTagRuleResponse(&rules[i], names[rules[i].TagID]))
+	}
+	response.Ok(c, ruleDTOs)
+}
+
+// CreateTagRule 为项目添加一条自动打标签规则，之后每次入库都会对新保存的实体求值
+// @Router /projects/{projectId}/tag-rules [post]
+func (h *TagHandler) CreateTagRule(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateTagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if err := tagging.ValidateRule(req.EntityType, req.Field, req.Pattern); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+	if tagging.NormalizeName(req.Tag) == "" {
+		response.BadRequest(c, "标签名称不能为空", nil)
+		return
+	}
+	if !h.projectExists(c, uint(projectID)) {
+		return
+	}
+
+	var rule model.TagRule
+	var tag model.Tag
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		tags, err := tagging.EnsureTags(tx, uint(projectID), []string{req.Tag})
+		if err != nil {
+			return err
+		}
+		tag = tags[0]
+		rule = model.TagRule{
+			ProjectID:   uint(projectID),
+			TagID:       tag.ID,
+			EntityType:  req.EntityType,
+			Field:       req.Field,
+			Pattern:     req.Pattern,
+			IsEnabled:   req.IsEnabled == nil || *req.IsEnabled,
+			Description: req.Description,
+		}
+		return tx.Create(&rule).Error
+	})
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "创建自动打标签规则成功", toTagRuleResponse(&rule, tag.Name))
+}
+
+// DeleteTagRule 删除项目的一条自动打标签规则，已打上的标签保持不变
+// @Router /projects/{projectId}/tag-rules/{ruleId} [delete]
+func (h *TagHandler) DeleteTagRule(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+	ruleID, err := strconv.Atoi(c.Param("ruleId"))
+	if err != nil {
+		response.BadRequest(c, "无效的规则ID", err)
+		return
+	}
+
+	result := h.DB.Unscoped().Where("project_id = ?", projectID).Delete(&model.TagRule{}, uint(ruleID))
+	if result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	} else if result.RowsAffected == 0 {
+		response.NotFound(c)
+		return
+	}
+	response.OkWithMessage(c, "删除自动打标签规则成功", nil)
+}
+
+// GetTagRuleFields 返回每种实体的自动打标签规则可以匹配的字段
+// @Router /tag-rules/fields [get]
+func (h *TagHandler) GetTagRuleFields(c *gin.Context) {
+	response.Ok(c, tagging.Fields())
+}
+
+// bindTagging 解析项目ID和打标签请求，并确认实体都属于该项目，失败时直接写入响应
+func (h *TagHandler) bindTagging(c *gin.Context) (uint, *dto.TaggingRequest, bool) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return 0, nil, false
+	}
+
+	var req dto.TaggingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return 0, nil, false
+	}
+	for i := range req.Tags {
+		req.Tags[i] = tagging.NormalizeName(req.Tags[i])
+	}
+	if !entitiesExist(c, h.DB, uint(projectID), req.EntityType, req.EntityIDs) {
+		return 0, nil, false
+	}
+	return uint(projectID), &req, true
+}
+
+// loadTag 解析URL中的标签ID并查询标签，失败时直接写入响应
+func (h *TagHandler) loadTag(c *gin.Context) (*model.Tag, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的标签ID", err)
+		return nil, false
+	}
+	var tag model.Tag
+	if err := h.DB.First(&tag, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &tag, true
+}
+
+// projectExists 确认项目存在，失败时直接写入响应
+func (h *TagHandler) projectExists(c *gin.Context, projectID uint) bool {
+	var project model.Project
+	if err := h.DB.Select("id").First(&project, projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "项目ID不存在", err)
+			return false
+		}
+		response.ServerError(c, err)
+		return false
+	}
+	return true
+}
+
+// entitiesExist 确认一组实体都存在且属于指定项目，失败时直接写入响应
+func entitiesExist(c *gin.Context, db *gorm.DB, projectID uint, entityType string, ids []uint) bool {
+	table, ok := tagging.Table(entityType)
+	if !ok {
+		response.BadRequest(c, "不支持的实体类型", nil)
+		return false
+	}
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	var count int64
+	if err := db.Table(table).Where("project_id = ? AND id IN ? AND deleted_at IS NULL", projectID, ids).
+		Count(&count).Error; err != nil {
+		response.ServerError(c, err)
+		return false
+	}
+	if count != int64(len(unique)) {
+		response.BadRequest(c, "部分实体不存在或不属于该项目", nil)
+		return false
+	}
+	return true
+}
+
+// attachTags 为实体响应列表附上标签名称，id 返回响应对应的实体ID，set 负责写入标签
+func attachTags[T any](db *gorm.DB, entityType string, list []T, id func(*T) uint, set func(*T, []string)) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(list))
+	for i := range list {
+		ids = append(ids, id(&list[i]))
+	}
+	names, err := tagging.Names(db, entityType, ids)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		set(&list[i], names[ids[i]])
+	}
+	return nil
+}
+
+func attachDomainTags(db *gorm.DB, domains []dto.DomainResponse) error {
+	return attachTags(db, model.TagEntityDomain, domains,
+		func(d *dto.DomainResponse) uint { return d.ID },
+		func(d *dto.DomainResponse, tags []string) { d.Tags = tags })
+}
+
+func attachAssetTags(db *gorm.DB, assets []dto.AssetResponse) error {
+	return attachTags(db, model.TagEntityAsset, assets,
+		func(a *dto.AssetResponse) uint { return a.ID },
+		func(a *dto.AssetResponse, tags []string) { a.Tags = tags })
+}
+
+func attachFindingTags(db *gorm.DB, findings []dto.FindingResponse) error {
+	return attachTags(db, model.TagEntityFinding, findings,
+		func(f *dto.FindingResponse) uint { return f.ID },
+		func(f *dto.FindingResponse, tags []string) { f.Tags = tags })
+}
+
+// filterByTags 只保留打上任一指定标签的实体，table 为实体所在的数据表
+func filterByTags(query *gorm.DB, entityType, table, tags string) *gorm.DB {
+	names := splitList(tags)
+	if len(names) == 0 {
+		return query
+	}
+	return query.Where("EXISTS (SELECT 1 FROM taggings tg JOIN tags t ON t.id = tg.tag_id AND t.deleted_at IS NULL "+
+		"WHERE tg.entity_type = ? AND tg.entity_id = "+table+".id AND t.name IN ?)", entityType, names)
+}
+
+func tagIDs(tags []model.Tag) []uint {
+	ids := make([]uint, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func toTagResponse(tag *model.Tag) dto.TagResponse {
+	return dto.TagResponse{
+		ID:          tag.ID,
+		ProjectID:   tag.ProjectID,
+		Name:        tag.Name,
+		Color:       tag.Color,
+		Description: tag.Description,
+		CreatedAt:   tag.CreatedAt,
+	}
+}
+
+func toTagRuleResponse(rule *model.TagRule, tag string) dto.TagRuleResponse {
+	return dto.TagRuleResponse{
+		ID:          rule.ID,
+		TagID:       rule.TagID,
+		Tag:         tag,
+		EntityType:  rule.EntityType,
+		Field:       rule.Field,
+		Pattern:     rule.Pattern,
+		IsEnabled:   rule.IsEnabled,
+		Description: rule.Description,
+		CreatedAt:   rule.CreatedAt,
+	}
+}
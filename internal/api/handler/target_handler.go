@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/api/validator"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxImportFileSize 是批量导入文件的最大字节数
+	maxImportFileSize = 5 << 20
+	// maxImportLines 是单次批量导入允许的最大行数
+	maxImportLines = 10000
+	// importBatchSize 是导入时每批查询/写入数据库的目标数量
+	importBatchSize = 500
+)
+
+type TargetHandler struct {
+	DB *gorm.DB
+}
+
+func NewTargetHandler(db *gorm.DB) *TargetHandler {
+	return &TargetHandler{DB: db}
+}
+
+// GetTargetsByProject 分页获取项目下的目标，可按类型和启用状态过滤
+// @Router /projects/{projectId}/targets [get]
+func (h *TargetHandler) GetTargetsByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.TargetListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.ProjectTarget{}).Where("project_id = ?", projectID)
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.IsActive != nil {
+		query = query.Where("is_active = ?", *req.IsActive)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(t model.ProjectTarget) (time.Time, uint) {
+		return t.CreatedAt, t.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	var targetDTOs []dto.TargetResponse
+	for i := range page.Items {
+		targetDTOs = append(targetDTOs, toTargetResponse(&page.Items[i]))
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, targetDTOs))
+}
+
+// UpdateTarget 更新目标的描述或启用状态
+// @Router /projects/{projectId}/targets/{targetId} [put]
+func (h *TargetHandler) UpdateTarget(c *gin.Context) {
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTargetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+
+	// 按需更新字段
+	updates := make(map[string]interface{})
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if len(updates) > 0 {
+		if err := h.DB.Model(target).Updates(updates).Error; err != nil {
+			response.ServerError(c, err)
+			return
+		}
+	}
+
+	response.OkWithMessage(c, "更新目标成功", toTargetResponse(target))
+}
+
+// DeleteTarget 删除项目中的一个目标
+// @Router /projects/{projectId}/targets/{targetId} [delete]
+func (h *TargetHandler) DeleteTarget(c *gin.Context) {
+	target, ok := h.loadTarget(c)
+	if !ok {
+		return
+	}
+
+	// 使用硬删除，避免软删除的记录占用 (project_id, value) 唯一索引导致无法重新添加
+	if err := h.DB.Unscoped().Delete(target).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "删除目标成功", nil)
+}
+
+// ImportTargets 从文本或CSV文件批量导入目标。
+// 每行一个目标，CSV 格式为 "value[,description]"；类型自动识别，重复目标会被跳过。
+// 文件可以通过 multipart 的 file 字段上传，也可以直接作为请求体发送。
+// @Router /projects/{projectId}/targets/import [post]
+func (h *TargetHandler) ImportTargets(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var project model.Project
+	if err := h.DB.First(&project, uint(projectID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "项目ID不存在", err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	// 1. 读取上传内容
+	var reader io.Reader
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		if fileHeader.Size > maxImportFileSize {
+			response.BadRequest(c, fmt.Sprintf("导入文件不能超过 %d MB", maxImportFileSize>>20), nil)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			response.BadRequest(c, "无法读取上传的文件", err)
+			return
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = c.Request.Body
+	}
+	content, err := io.ReadAll(io.LimitReader(reader, maxImportFileSize+1))
+	if err != nil {
+		response.BadRequest(c, "无法读取导入内容", err)
+		return
+	}
+	if len(content) > maxImportFileSize {
+		response.BadRequest(c, fmt.Sprintf("导入文件不能超过 %d MB", maxImportFileSize>>20), nil)
+		return
+	}
+
+	// 2. 逐行解析并校验
+	lines, targets, err := parseTargetImport(content, uint(projectID))
+	if err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	// 3. 分批写入，已存在的目标标记为重复
+	created := make(map[string]bool)
+	for start := 0; start < len(targets); start += importBatchSize {
+		end := min(start+importBatchSize, len(targets))
+		batch := targets[start:end]
+
+		values := make([]string, 0, len(batch))
+		for _, t := range batch {
+			values = append(values, t.Value)
+		}
+		var existing []string
+		if err := h.DB.Unscoped().Model(&model.ProjectTarget{}).
+			Where("project_id = ? AND value IN ?", projectID, values).
+			Pluck("value", &existing).Error; err != nil {
+			response.ServerError(c, err)
+			return
+		}
+		existingSet := make(map[string]bool, len(existing))
+		for _, v := range existing {
+			existingSet[v] = true
+		}
+
+		var toCreate []model.ProjectTarget
+		for _, t := range batch {
+			if !existingSet[t.Value] {
+				toCreate = append(toCreate, t)
+			}
+		}
+		if len(toCreate) == 0 {
+			continue
+		}
+		// 并发导入时仍可能冲突，由 ON CONFLICT DO NOTHING 兜底，RETURNING 只返回实际插入的行
+		inserted, err := insertTargets(h.DB, toCreate)
+		if err != nil {
+			response.ServerError(c, err)
+			return
+		}
+		for _, v := range inserted {
+			created[v] = true
+		}
+	}
+
+	// 4. 汇总每一行的结果
+	report := dto.TargetImportReport{Lines: lines}
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		if line.Status == "" {
+			if created[line.Value] {
+				line.Status = dto.ImportStatusCreated
+				delete(created, line.Value)
+			} else {
+				line.Status = dto.ImportStatusDuplicate
+				line.Message = "目标已存在于项目中"
+			}
+		}
+		switch line.Status {
+		case dto.ImportStatusCreated:
+			report.Created++
+		case dto.ImportStatusDuplicate:
+			report.Duplicate++
+		case dto.ImportStatusInvalid:
+			report.Invalid++
+		}
+	}
+
+	response.OkWithMessage(c, "导入完成", report)
+}
+
+// insertTargets 插入目标并返回实际插入的目标值，与已有目标冲突的行被跳过。
+// gorm 在 ON CONFLICT DO NOTHING 时按切片元素回填 RETURNING 的结果，会跳过已有非零字段的元素，
+// 无法区分哪些行被插入，因此先生成 INSERT 语句，再把 RETURNING 的值单独读出。
+func insertTargets(db *gorm.DB, targets []model.ProjectTarget) ([]string, error) {
+	stmt := db.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "value"}},
+		DoNothing: true,
+	}, clause.Returning{Columns: []clause.Column{{Name: "value"}}}).Create(&targets).Statement
+	if stmt.Error != nil {
+		return nil, stmt.Error
+	}
+	var inserted []string
+	if err := db.Raw(stmt.SQL.String(), stmt.Vars...).Scan(&inserted).Error; err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// parseTargetImport 解析导入内容，返回每行的初步结果以及需要写入的目标 (已去除文件内重复)。
+// 空行和以 # 开头的注释行会被忽略，首行若为表头 (value) 也会被跳过。
+func parseTargetImport(content []byte, projectID uint) ([]dto.TargetImportLine, []model.ProjectTarget, error) {
+	r := csv.NewReader(strings.NewReader(string(content)))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.LazyQuotes = true
+
+	var lines []dto.TargetImportLine
+	var targets []model.ProjectTarget
+	seen := make(map[string]bool)
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			lines = append(lines, dto.TargetImportLine{Line: parseErr.Line, Status: dto.ImportStatusInvalid, Message: fmt.Sprintf("无法解析该行: %v", parseErr.Err)})
+			continue
+		}
+		lineNo, _ := r.FieldPos(0)
+		if len(lines) >= maxImportLines {
+			return nil, nil, fmt.Errorf("单次最多导入 %d 行", maxImportLines)
+		}
+
+		value := validator.NormalizeTargetValue(record[0])
+		if value == "" {
+			continue
+		}
+		if len(lines) == 0 && strings.EqualFold(value, "value") {
+			continue // 表头
+		}
+		description := ""
+		if len(record) > 1 {
+			description = strings.TrimSpace(record[1])
+		}
+
+		line := dto.TargetImportLine{Line: lineNo, Value: value}
+		targetType, err := validator.DetectTargetType(value)
+		switch {
+		case err != nil:
+			line.Status = dto.ImportStatusInvalid
+			line.Message = err.Error()
+		case seen[value]:
+			line.Type = targetType
+			line.Status = dto.ImportStatusDuplicate
+			line.Message = "与文件中前面的行重复"
+		default:
+			line.Type = targetType
+			seen[value] = true
+			targets = append(targets, model.ProjectTarget{
+				ProjectID:   projectID,
+				Value:       value,
+				Type:        targetType,
+				Description: description,
+				IsActive:    true,
+			})
+		}
+		lines = append(lines, line)
+	}
+	return lines, targets, nil
+}
+
+// loadTarget 解析URL中的项目ID和目标ID并查询目标，失败时直接写入响应
+func (h *TargetHandler) loadTarget(c *gin.Context) (*model.ProjectTarget, bool) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return nil, false
+	}
+	targetID, err := strconv.Atoi(c.Param("targetId"))
+	if err != nil {
+		response.BadRequest(c, "无效的目标ID", err)
+		return nil, false
+	}
+	var target model.ProjectTarget
+	if err := h.DB.Where("project_id = ?", projectID).First(&target, uint(targetID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &target, true
+}
+
+func toTargetResponse(target *model.ProjectTarget) dto.TargetResponse {
+	return dto.TargetResponse{
+		ID:          target.ID,
+		ProjectID:   target.ProjectID,
+		Value:       target.Value,
+		Type:        target.Type,
+		Description: target.Description,
+		IsActive:    target.IsActive,
+		CreatedAt:   target.CreatedAt,
+		UpdatedAt:   target.UpdatedAt,
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// importTargets 以请求体的方式调用导入接口，返回导入报告
+func importTargets(t *testing.T, db *gorm.DB, projectID uint, body string) dto.TargetImportReport {
+	t.Helper()
+	var report dto.TargetImportReport
+	if code := serve(t, NewTargetHandler(db).ImportTargets, http.MethodPost, "/", strings.NewReader(body), projectParams(projectID), &report); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	return report
+}
+
+func TestImportTargetsReportsCreatedAndDuplicate(t *testing.T) {
+	db := testutil.DB(t)
+	project := newTestProject(t, db)
+
+	report := importTargets(t, db, project.ID, "example.com\n192.0.2.1,办公网出口\nexample.com\n")
+	if report.Created != 2 || report.Duplicate != 1 || report.Invalid != 0 {
+		t.Fatalf("首次导入 = %+v, want 新建2 重复1", report)
+	}
+	var n int64
+	db.Model(&model.ProjectTarget{}).Where("project_id = ?", project.ID).Count(&n)
+	if n != 2 {
+		t.Errorf("目标数 = %d, want 2", n)
+	}
+
+	report = importTargets(t, db, project.ID, "example.com\n10.0.0.0/8\n")
+	if report.Created != 1 || report.Duplicate != 1 {
+		t.Fatalf("再次导入 = %+v, want 新建1 重复1", report)
+	}
+	for _, line := range report.Lines {
+		want := dto.ImportStatusCreated
+		if line.Value == "example.com" {
+			want = dto.ImportStatusDuplicate
+		}
+		if line.Status != want {
+			t.Errorf("第%d行 %s 的状态 = %s, want %s", line.Line, line.Value, line.Status, want)
+		}
+	}
+}
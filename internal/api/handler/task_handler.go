@@ -4,10 +4,12 @@ import (
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
 	"github.com/src-hunter/internal/api/response"
 	"github.com/src-hunter/internal/model"
 	"gorm.io/gorm"
 	"strconv"
+	"time"
 )
 
 type TaskHandler struct {
@@ -34,42 +36,30 @@ func (h *TaskHandler) GetTasksByProject(c *gin.Context) {
 		response.BadRequest(c, "分页参数错误", err)
 		return
 	}
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.PageSize <= 0 || req.PageSize > 100 {
-		req.PageSize = 10
-	}
-
-	// 3. 查询总数和当前页数据
-	var tasks []model.Task
-	var total int64
+	req.Normalize()
 
-	// 创建查询构建器，限定项目ID和只看父任务 (ParentTaskID = 0)
+	// 3. 查询当前页数据，限定项目ID和只看父任务 (ParentTaskID = 0)
 	query := h.DB.Model(&model.Task{}).Where("project_id = ? AND parent_task_id = ?", projectID, 0)
-
-	if err := query.Count(&total).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			response.ServerError(c, err)
-		}
-		return
-	}
-
-	offset := (req.Page - 1) * req.PageSize
-	if err := query.Offset(offset).Limit(req.PageSize).Order("created_at desc").Find(&tasks).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			response.ServerError(c, err)
+	page, err := pagination.Find(query, &req, func(t model.Task) (time.Time, uint) {
+		return t.CreatedAt, t.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
 		}
+		response.ServerError(c, err)
 		return
 	}
 
 	// 4. 将数据库模型转换为DTO
 	var taskDTOs []dto.TaskResponse
-	for _, task := range tasks {
+	for _, task := range page.Items {
 		taskDTOs = append(taskDTOs, dto.TaskResponse{
 			ID:            task.ID,
 			ProjectID:     task.ProjectID,
 			ScanProfileID: task.ScanProfileID,
+			Type:          task.Type,
 			Status:        task.Status,
 			Result:        task.Result,
 			FinishedAt:    task.FinishedAt,
@@ -78,10 +68,36 @@ func (h *TaskHandler) GetTasksByProject(c *gin.Context) {
 	}
 
 	// 5. 返回分页响应
-	response.Ok(c, dto.PaginationResponse{
-		Total:    total,
-		Page:     req.Page,
-		PageSize: req.PageSize,
-		List:     taskDTOs,
+	response.Ok(c, pagination.Response(&req, page, taskDTOs))
+}
+
+// GetTaskByID 获取单个任务的详情，可用于查询后台任务 (如项目删除) 的进度
+// @Router /tasks/{taskId} [get]
+func (h *TaskHandler) GetTaskByID(c *gin.Context) {
+	taskID, err := strconv.Atoi(c.Param("taskId"))
+	if err != nil {
+		response.BadRequest(c, "无效的任务ID", err)
+		return
+	}
+
+	var task model.Task
+	if err := h.DB.First(&task, uint(taskID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	response.Ok(c, dto.TaskResponse{
+		ID:            task.ID,
+		ProjectID:     task.ProjectID,
+		ScanProfileID: task.ScanProfileID,
+		Type:          task.Type,
+		Status:        task.Status,
+		Result:        task.Result,
+		FinishedAt:    task.FinishedAt,
+		CreatedAt:     task.CreatedAt,
 	})
 }
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/src-hunter/internal/api/dto"
+	"github.com/src-hunter/internal/api/pagination"
+	"github.com/src-hunter/internal/api/response"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/notify"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+type WebhookHandler struct {
+	DB        *gorm.DB
+	Deliverer *notify.Deliverer
+}
+
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{DB: db, Deliverer: notify.NewDeliverer(db)}
+}
+
+// GetWebhooksByProject 获取项目下的所有Webhook订阅
+// @Router /projects/{projectId}/webhooks [get]
+func (h *WebhookHandler) GetWebhooksByProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var subscriptions []model.WebhookSubscription
+	if err := h.DB.Where("project_id = ?", projectID).Order("id").Find(&subscriptions).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	webhookDTOs := make([]dto.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		webhookDTOs = append(webhookDTOs, toWebhookResponse(&subscriptions[i]))
+	}
+	response.Ok(c, webhookDTOs)
+}
+
+// CreateWebhook 为项目创建一个Webhook订阅，未提供密钥时自动生成并在响应中返回一次
+// @Router /projects/{projectId}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("projectId"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID", err)
+		return
+	}
+
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if err := notify.ValidateEvents(req.Events); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	var project model.Project
+	if err := h.DB.First(&project, uint(projectID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.BadRequest(c, "项目ID不存在", err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateSecret(); err != nil {
+			response.ServerError(c, err)
+			return
+		}
+	}
+	subscription := model.WebhookSubscription{
+		ProjectID: project.ID,
+		Name:      req.Name,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		IsEnabled: req.IsEnabled == nil || *req.IsEnabled,
+	}
+	if err := h.DB.Create(&subscription).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+
+	webhookDTO := toWebhookResponse(&subscription)
+	webhookDTO.Secret = subscription.Secret
+	response.OkWithMessage(c, "创建Webhook订阅成功", webhookDTO)
+}
+
+// GetWebhookByID 根据ID获取单个Webhook订阅
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhookByID(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+	response.Ok(c, toWebhookResponse(subscription))
+}
+
+// UpdateWebhook 更新一个Webhook订阅
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误", err)
+		return
+	}
+	if err := notify.ValidateEvents(req.Events); err != nil {
+		response.BadRequest(c, err.Error(), err)
+		return
+	}
+
+	if req.Name != "" {
+		subscription.Name = req.Name
+	}
+	if req.URL != "" {
+		subscription.URL = req.URL
+	}
+	if req.Secret != nil {
+		subscription.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		subscription.Events = req.Events
+	}
+	if req.IsEnabled != nil {
+		subscription.IsEnabled = *req.IsEnabled
+	}
+
+	if err := h.DB.Model(subscription).Select("name", "url", "secret", "events", "is_enabled").
+		Updates(subscription).Error; err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	response.OkWithMessage(c, "更新Webhook订阅成功", toWebhookResponse(subscription))
+}
+
+// DeleteWebhook 删除一个Webhook订阅，尚未完成的投递会在执行时被标记为失败
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的订阅ID", err)
+		return
+	}
+
+	if result := h.DB.Delete(&model.WebhookSubscription{}, uint(id)); result.Error != nil {
+		response.ServerError(c, result.Error)
+		return
+	} else if result.RowsAffected == 0 {
+		response.NotFound(c)
+		return
+	}
+	response.OkWithMessage(c, "删除Webhook订阅成功", nil)
+}
+
+// TestWebhook 立即向订阅的URL发送一条测试事件，返回本次投递的结果
+// @Router /webhooks/{id}/test [post]
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.Deliverer.SendTest(c.Request.Context(), subscription)
+	if err != nil {
+		response.ServerError(c, err)
+		return
+	}
+	if delivery.Status != model.DeliveryStatusSuccess {
+		response.Fail(c, "测试投递失败: "+delivery.Error)
+		return
+	}
+	response.OkWithMessage(c, "测试投递成功", toWebhookDeliveryResponse(delivery))
+}
+
+// GetWebhookDeliveries 分页获取Webhook订阅的投递日志
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	subscription, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookDeliveryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误", err)
+		return
+	}
+	req.Normalize()
+
+	query := h.DB.Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscription.ID)
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Event != "" {
+		query = query.Where("event = ?", req.Event)
+	}
+
+	page, err := pagination.Find(query, &req.PaginationRequest, func(d model.WebhookDelivery) (time.Time, uint) {
+		return d.CreatedAt, d.ID
+	})
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			response.BadRequest(c, err.Error(), err)
+			return
+		}
+		response.ServerError(c, err)
+		return
+	}
+
+	deliveryDTOs := make([]dto.WebhookDeliveryResponse, 0, len(page.Items))
+	for i := range page.Items {
+		deliveryDTOs = append(deliveryDTOs, toWebhookDeliveryResponse(&page.Items[i]))
+	}
+	response.Ok(c, pagination.Response(&req.PaginationRequest, page, deliveryDTOs))
+}
+
+// loadWebhook 解析URL中的订阅ID并查询订阅，失败时直接写入响应
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*model.WebhookSubscription, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的订阅ID", err)
+		return nil, false
+	}
+	var subscription model.WebhookSubscription
+	if err := h.DB.First(&subscription, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c)
+			return nil, false
+		}
+		response.ServerError(c, err)
+		return nil, false
+	}
+	return &subscription, true
+}
+
+// generateSecret 生成一个随机的签名密钥
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toWebhookResponse(subscription *model.WebhookSubscription) dto.WebhookResponse {
+	events := subscription.Events
+	if events == nil {
+		events = model.JSONBArray{}
+	}
+	return dto.WebhookResponse{
+		ID:        subscription.ID,
+		ProjectID: subscription.ProjectID,
+		Name:      subscription.Name,
+		URL:       subscription.URL,
+		HasSecret: subscription.Secret != "",
+		Events:    events,
+		IsEnabled: subscription.IsEnabled,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery *model.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseCode:   delivery.ResponseCode,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
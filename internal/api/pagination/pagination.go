@@ -0,0 +1,123 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/src-hunter/internal/api/dto"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor 表示客户端传入的游标无法解析
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// Cursor 是游标分页的位置，按 (created_at, id) 倒序排列
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// Encode 将游标编码为对客户端不透明的字符串
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode 解析客户端传回的游标字符串
+func Decode(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	tsStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.UnixMicro(ts), ID: uint(id)}, nil
+}
+
+// Page 是一次分页查询的结果
+type Page[T any] struct {
+	Items      []T
+	Total      *int64
+	NextCursor string
+	HasMore    bool
+}
+
+// Find 根据请求参数对 query 执行偏移分页或游标分页。
+// key 用于从记录中取出 (created_at, id) 以生成下一页游标。
+func Find[T any](query *gorm.DB, req *dto.PaginationRequest, key func(T) (time.Time, uint)) (*Page[T], error) {
+	page := &Page[T]{}
+
+	// 偏移分页总是返回总数；游标分页仅在显式要求时统计
+	if !req.IsCursor() || req.WithTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	if !req.IsCursor() {
+		offset := (req.Page - 1) * req.PageSize
+		if err := query.Session(&gorm.Session{}).Offset(offset).Limit(req.PageSize).Order("created_at desc").Find(&page.Items).Error; err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+
+	q := query.Session(&gorm.Session{})
+	if req.Cursor != "" {
+		cursor, err := Decode(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		q = q.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	// 多取一条用于判断是否还有下一页
+	if err := q.Order("created_at desc, id desc").Limit(req.PageSize + 1).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(page.Items) > req.PageSize {
+		page.Items = page.Items[:req.PageSize]
+		page.HasMore = true
+		createdAt, id := key(page.Items[len(page.Items)-1])
+		page.NextCursor = Cursor{CreatedAt: createdAt, ID: id}.Encode()
+	}
+	return page, nil
+}
+
+// Response 将分页结果和转换后的DTO列表组装成对应模式的响应结构
+func Response[T any](req *dto.PaginationRequest, page *Page[T], list interface{}) interface{} {
+	if req.IsCursor() {
+		return dto.CursorPaginationResponse{
+			Total:      page.Total,
+			PageSize:   req.PageSize,
+			NextCursor: page.NextCursor,
+			HasMore:    page.HasMore,
+			List:       list,
+		}
+	}
+	var total int64
+	if page.Total != nil {
+		total = *page.Total
+	}
+	return dto.PaginationResponse{
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		List:     list,
+	}
+}
@@ -21,11 +21,27 @@ func SetupRouter(db *gorm.DB, asynqClient *asynq.Client) *gin.Engine {
 	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
 	router.Use(cors.New(config))
 
-	projectHandler := handler.NewProjectHandler(db)
+	projectHandler := handler.NewProjectHandler(db, asynqClient)
 	scanHandler := handler.NewScanHandler(db, asynqClient)
 	scanProfileHandler := handler.NewScanProfileHandler(db)
 	taskHandler := handler.NewTaskHandler(db)
 	domainHandler := handler.NewDomainHandler(db)
+	assetHandler := handler.NewAssetHandler(db)
+	searchHandler := handler.NewSearchHandler(db)
+	targetHandler := handler.NewTargetHandler(db)
+	scopeHandler := handler.NewScopeHandler(db)
+	scheduleHandler := handler.NewScheduleHandler(db)
+	changeHandler := handler.NewChangeHandler(db)
+	historyHandler := handler.NewHistoryHandler(db)
+	webhookHandler := handler.NewWebhookHandler(db)
+	chatChannelHandler := handler.NewChatChannelHandler(db, asynqClient)
+	findingHandler := handler.NewFindingHandler(db)
+	endpointHandler := handler.NewEndpointHandler(db)
+	dnsHandler := handler.NewDNSHandler(db)
+	certificateHandler := handler.NewCertificateHandler(db)
+	parserHandler := handler.NewParserHandler()
+	tagHandler := handler.NewTagHandler(db)
+	noteHandler := handler.NewNoteHandler(db)
 
 	apiV1 := router.Group("/api/v1")
 	{
@@ -34,9 +50,98 @@ func SetupRouter(db *gorm.DB, asynqClient *asynq.Client) *gin.Engine {
 			projects.GET("", projectHandler.GetProjects)
 			projects.GET("/:projectId", projectHandler.GetProjectByID)
 			projects.POST("", projectHandler.CreateProject)
+			projects.PUT("/:projectId", projectHandler.UpdateProject)
+			projects.DELETE("/:projectId", projectHandler.DeleteProject)
+			projects.POST("/:projectId/archive", projectHandler.ArchiveProject)
+			projects.POST("/:projectId/unarchive", projectHandler.UnarchiveProject)
 			projects.POST("/:projectId/targets", projectHandler.AddTargetsToProject)
+			projects.GET("/:projectId/targets", targetHandler.GetTargetsByProject)
+			projects.POST("/:projectId/targets/import", targetHandler.ImportTargets)
+			projects.PUT("/:projectId/targets/:targetId", targetHandler.UpdateTarget)
+			projects.DELETE("/:projectId/targets/:targetId", targetHandler.DeleteTarget)
 			projects.GET("/:projectId/tasks", taskHandler.GetTasksByProject)
 			projects.GET("/:projectId/domains", domainHandler.GetDomainsByProject)
+			projects.GET("/:projectId/root-domains", domainHandler.GetRootDomainsByProject)
+			projects.GET("/:projectId/domains/:domainId/timeline", historyHandler.GetDomainTimeline)
+			projects.GET("/:projectId/assets", assetHandler.GetAssetsByProject)
+			projects.GET("/:projectId/assets/:assetId/timeline", historyHandler.GetAssetTimeline)
+			projects.GET("/:projectId/findings", findingHandler.GetFindingsByProject)
+			projects.GET("/:projectId/endpoints", endpointHandler.GetEndpointsByProject)
+			projects.GET("/:projectId/dns-records", dnsHandler.GetDNSRecordsByProject)
+			projects.GET("/:projectId/certificates", certificateHandler.GetCertificatesByProject)
+			projects.GET("/:projectId/certificates/issues", certificateHandler.GetCertificateIssues)
+			projects.PUT("/:projectId/findings/status", findingHandler.BulkUpdateFindings)
+			projects.GET("/:projectId/search", searchHandler.Search)
+			projects.GET("/:projectId/scope-rules", scopeHandler.GetScopeRules)
+			projects.POST("/:projectId/scope-rules", scopeHandler.CreateScopeRule)
+			projects.DELETE("/:projectId/scope-rules/:ruleId", scopeHandler.DeleteScopeRule)
+			projects.POST("/:projectId/scope/check", scopeHandler.CheckScope)
+			projects.GET("/:projectId/out-of-scope", scopeHandler.GetOutOfScopeItems)
+			projects.GET("/:projectId/schedules", scheduleHandler.GetSchedulesByProject)
+			projects.POST("/:projectId/schedules", scheduleHandler.CreateSchedule)
+			projects.GET("/:projectId/changes", changeHandler.GetProjectChanges)
+			projects.GET("/:projectId/webhooks", webhookHandler.GetWebhooksByProject)
+			projects.POST("/:projectId/webhooks", webhookHandler.CreateWebhook)
+			projects.GET("/:projectId/chat-channels", chatChannelHandler.GetChatChannelsByProject)
+			projects.POST("/:projectId/chat-channels", chatChannelHandler.CreateChatChannel)
+			projects.GET("/:projectId/tags", tagHandler.GetTagsByProject)
+			projects.POST("/:projectId/tags", tagHandler.CreateTag)
+			projects.POST("/:projectId/tags/bulk", tagHandler.BulkTag)
+			projects.POST("/:projectId/taggings", tagHandler.AddTags)
+			projects.DELETE("/:projectId/taggings", tagHandler.RemoveTags)
+			projects.GET("/:projectId/tag-rules", tagHandler.GetTagRules)
+			projects.POST("/:projectId/tag-rules", tagHandler.CreateTagRule)
+			projects.DELETE("/:projectId/tag-rules/:ruleId", tagHandler.DeleteTagRule)
+			projects.GET("/:projectId/notes", noteHandler.GetNotesByProject)
+			projects.POST("/:projectId/notes", noteHandler.CreateNote)
+		}
+		findings := apiV1.Group("/findings")
+		{
+			findings.GET("/:id", findingHandler.GetFindingByID)
+			findings.PUT("/:id", findingHandler.UpdateFinding)
+		}
+		webhooks := apiV1.Group("/webhooks")
+		{
+			webhooks.GET("/:id", webhookHandler.GetWebhookByID)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.POST("/:id/test", webhookHandler.TestWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+		}
+		chatChannels := apiV1.Group("/chat-channels")
+		{
+			chatChannels.GET("/:id", chatChannelHandler.GetChatChannelByID)
+			chatChannels.PUT("/:id", chatChannelHandler.UpdateChatChannel)
+			chatChannels.DELETE("/:id", chatChannelHandler.DeleteChatChannel)
+			chatChannels.POST("/:id/test", chatChannelHandler.TestChatChannel)
+		}
+		schedules := apiV1.Group("/schedules")
+		{
+			schedules.GET("/:id", scheduleHandler.GetScheduleByID)
+			schedules.PUT("/:id", scheduleHandler.UpdateSchedule)
+			schedules.DELETE("/:id", scheduleHandler.DeleteSchedule)
+		}
+		apiV1.GET("/search/fields", searchHandler.GetSearchFields)
+		tags := apiV1.Group("/tags")
+		{
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+		}
+		apiV1.GET("/tag-rules/fields", tagHandler.GetTagRuleFields)
+		notes := apiV1.Group("/notes")
+		{
+			notes.PUT("/:id", noteHandler.UpdateNote)
+			notes.DELETE("/:id", noteHandler.DeleteNote)
+		}
+		parsers := apiV1.Group("/parsers")
+		{
+			parsers.GET("", parserHandler.GetParsers)
+			parsers.POST("/:name/test", parserHandler.TestParser)
+		}
+		tasks := apiV1.Group("/tasks")
+		{
+			tasks.GET("/:taskId", taskHandler.GetTaskByID)
+			tasks.GET("/:taskId/changes", changeHandler.GetWorkflowChanges)
 		}
 		scans := apiV1.Group("/scans")
 		{
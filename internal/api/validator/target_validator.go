@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"strings"
 )
 
 // 定义一个正则表达式用于校验域名
@@ -31,3 +32,22 @@ func ValidateTargetValue(targetType, value string) error {
 	}
 	return nil
 }
+
+// NormalizeTargetValue 清理目标值中常见的多余内容: 首尾空白、域名的大小写和末尾的点
+func NormalizeTargetValue(value string) string {
+	value = strings.TrimSpace(value)
+	if net.ParseIP(value) == nil && !strings.Contains(value, "/") {
+		value = strings.TrimSuffix(strings.ToLower(value), ".")
+	}
+	return value
+}
+
+// DetectTargetType 自动识别目标值的类型 (ip, cidr, domain)
+func DetectTargetType(value string) (string, error) {
+	for _, targetType := range []string{"ip", "cidr", "domain"} {
+		if ValidateTargetValue(targetType, value) == nil {
+			return targetType, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' 不是合法的域名、IP或CIDR地址块", value)
+}
@@ -0,0 +1,133 @@
+// Package cdn 识别位于CDN/WAF之后的主机：IP段、CNAME 指向和HTTP响应头三种依据。
+// 对这些主机做端口扫描只会扫到边缘节点，工作流可以据此跳过端口扫描步骤。
+package cdn
+
+import (
+	_ "embed"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+//go:embed ranges.txt
+var builtinRangesData string
+
+var (
+	builtinOnce   sync.Once
+	builtinRanges Ranges
+)
+
+// Builtin 返回随程序发布的CDN/WAF节点IP段
+func Builtin() Ranges {
+	builtinOnce.Do(func() {
+		ranges, err := ParseRanges(strings.NewReader(builtinRangesData))
+		if err != nil {
+			panic(fmt.Sprintf("cdn: 内置IP段列表格式错误: %v", err))
+		}
+		builtinRanges = ranges
+	})
+	return builtinRanges
+}
+
+// MatchIP 判断IP是否落在内置的CDN节点IP段中，返回服务商名称
+func MatchIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	if r, ok := Builtin().Match(addr); ok && r.IsCDN {
+		return r.Provider
+	}
+	return ""
+}
+
+// cnameSuffixes 是CDN/WAF服务分配给接入域名的 CNAME 后缀
+var cnameSuffixes = []struct {
+	suffix   string
+	provider string
+}{
+	{".cdn.cloudflare.net", "cloudflare"},
+	{".akamaiedge.net", "akamai"},
+	{".akamai.net", "akamai"},
+	{".akamaized.net", "akamai"},
+	{".edgekey.net", "akamai"},
+	{".edgesuite.net", "akamai"},
+	{".cloudfront.net", "cloudfront"},
+	{".fastly.net", "fastly"},
+	{".fastlylb.net", "fastly"},
+	{".incapdns.net", "incapsula"},
+	{".impervadns.net", "incapsula"},
+	{".azureedge.net", "azure-cdn"},
+	{".azurefd.net", "azure-frontdoor"},
+	{".edgecastcdn.net", "edgecast"},
+	{".stackpathdns.com", "stackpath"},
+	{".stackpathcdn.com", "stackpath"},
+	{".cdn77.org", "cdn77"},
+	{".kxcdn.com", "keycdn"},
+	{".b-cdn.net", "bunnycdn"},
+	{".alikunlun.com", "alibaba-cdn"},
+	{".alikunlun.net", "alibaba-cdn"},
+	{".cdn.dnsv1.com", "tencent-cdn"},
+	{".tdnsv5.com", "tencent-cdn"},
+	{".wscdns.com", "wangsu"},
+	{".chinanetcenter.com", "wangsu"},
+	{".ccgslb.com", "chinacache"},
+	{".ccgslb.net", "chinacache"},
+	{".qiniudns.com", "qiniu"},
+}
+
+// MatchCNAME 判断 CNAME 目标是否指向CDN/WAF服务，返回服务商名称
+func MatchCNAME(target string) string {
+	target = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(target)), ".")
+	for _, s := range cnameSuffixes {
+		if strings.HasSuffix(target, s.suffix) {
+			return s.provider
+		}
+	}
+	return ""
+}
+
+// headerSignatures 是CDN/WAF节点在响应中加入的特征头。value 为空表示只要求头存在，否则要求值包含该字符串
+var headerSignatures = []struct {
+	header   string
+	value    string
+	provider string
+}{
+	{"cf-ray", "", "cloudflare"},
+	{"server", "cloudflare", "cloudflare"},
+	{"x-amz-cf-id", "", "cloudfront"},
+	{"x-amz-cf-pop", "", "cloudfront"},
+	{"via", "cloudfront", "cloudfront"},
+	{"server", "akamaighost", "akamai"},
+	{"x-akamai-transformed", "", "akamai"},
+	{"akamai-grn", "", "akamai"},
+	{"x-fastly-request-id", "", "fastly"},
+	{"x-iinfo", "", "incapsula"},
+	{"x-cdn", "incapsula", "incapsula"},
+	{"x-sucuri-id", "", "sucuri"},
+	{"server", "sucuri", "sucuri"},
+	{"x-azure-ref", "", "azure-frontdoor"},
+	{"server", "keycdn", "keycdn"},
+	{"server", "bunnycdn", "bunnycdn"},
+}
+
+// MatchHeaders 根据响应头判断响应是否经过CDN/WAF节点，返回服务商名称。
+// 头名称不区分大小写，'_' 视同 '-' (httpx 的JSON输出会替换头名称中的 '-')。
+func MatchHeaders(headers map[string]string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	normalized := make(map[string]string, len(headers))
+	for name, value := range headers {
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+		normalized[name] = strings.ToLower(value)
+	}
+	for _, sig := range headerSignatures {
+		value, ok := normalized[sig.header]
+		if ok && strings.Contains(value, sig.value) {
+			return sig.provider
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package cdn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Range 是一个属于CDN或云服务商的IP段
+type Range struct {
+	Prefix   netip.Prefix
+	Provider string
+	IsCDN    bool
+}
+
+// Ranges 是按前缀长度从长到短排列的IP段列表，匹配时返回最具体的IP段
+type Ranges []Range
+
+// LoadRanges 从本地文件加载IP段列表
+func LoadRanges(path string) (Ranges, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRanges(f)
+}
+
+// ParseRanges 解析IP段列表。每行格式为 "CIDR 服务商 [cdn|cloud]"，类型缺省为 cloud，
+// 空行和 # 开头的注释行会被忽略。
+func ParseRanges(r io.Reader) (Ranges, error) {
+	var ranges Ranges
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("第 %d 行格式错误，应为 \"CIDR 服务商 [cdn|cloud]\"", lineNo)
+		}
+		prefix, err := netip.ParsePrefix(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行的IP段 '%s' 不合法: %w", lineNo, fields[0], err)
+		}
+		rng := Range{Prefix: prefix.Masked(), Provider: strings.ToLower(fields[1])}
+		if len(fields) > 2 {
+			switch strings.ToLower(fields[2]) {
+			case "cdn":
+				rng.IsCDN = true
+			case "cloud":
+			default:
+				return nil, fmt.Errorf("第 %d 行的类型 '%s' 不合法，应为 cdn 或 cloud", lineNo, fields[2])
+			}
+		}
+		ranges = append(ranges, rng)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	ranges.sort()
+	return ranges, nil
+}
+
+// Merge 合并多个IP段列表，前面列表中的IP段在前缀长度相同时优先匹配
+func Merge(lists ...Ranges) Ranges {
+	var merged Ranges
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+	merged.sort()
+	return merged
+}
+
+func (rs Ranges) sort() {
+	sort.SliceStable(rs, func(i, j int) bool {
+		return rs[i].Prefix.Bits() > rs[j].Prefix.Bits()
+	})
+}
+
+// Match 返回包含该IP的最具体的IP段
+func (rs Ranges) Match(addr netip.Addr) (Range, bool) {
+	addr = addr.Unmap()
+	for _, r := range rs {
+		if r.Prefix.Contains(addr) {
+			return r, true
+		}
+	}
+	return Range{}, false
+}
@@ -0,0 +1,277 @@
+package changes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+// WorkflowStatusCompleted 是工作流顶级任务成功结束后的状态，只有完成的运行才作为比较基线
+const WorkflowStatusCompleted = "completed"
+
+// ErrNotWorkflow 表示指定的任务不是工作流的顶级任务
+var ErrNotWorkflow = errors.New("任务不是一个工作流")
+
+// FieldChange 描述实体某个属性在两次运行之间的变化
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// EntityChange 描述一个新增、消失或属性发生变化的域名或资产
+type EntityChange struct {
+	Kind     string        `json:"kind"`
+	EntityID uint          `json:"entityId"`
+	Value    string        `json:"value"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+}
+
+// Summary 是变化数量的汇总
+type Summary struct {
+	NewDomains     int `json:"newDomains"`
+	RemovedDomains int `json:"removedDomains"`
+	NewAssets      int `json:"newAssets"`
+	RemovedAssets  int `json:"removedAssets"`
+	ChangedAssets  int `json:"changedAssets"`
+}
+
+// ChangeSet 是两次工作流运行之间的变化集合。
+// FromWorkflowID 为0表示没有可比较的基线运行，此时本次运行观测到的所有实体都视为新增。
+type ChangeSet struct {
+	ProjectID      uint
+	FromWorkflowID uint
+	FromCreatedAt  *time.Time
+	ToWorkflowID   uint
+	ToCreatedAt    *time.Time
+	Summary        Summary
+	NewDomains     []EntityChange
+	RemovedDomains []EntityChange
+	NewAssets      []EntityChange
+	RemovedAssets  []EntityChange
+	ChangedAssets  []EntityChange
+}
+
+// LoadWorkflow 加载项目下的一个工作流顶级任务，projectID 为0时不校验所属项目
+func LoadWorkflow(db *gorm.DB, projectID, workflowID uint) (*model.Task, error) {
+	var task model.Task
+	if err := db.First(&task, workflowID).Error; err != nil {
+		return nil, err
+	}
+	if task.ParentTaskID != 0 || task.Type != "workflow" || (projectID != 0 && task.ProjectID != projectID) {
+		return nil, ErrNotWorkflow
+	}
+	return &task, nil
+}
+
+// LatestCompleted 查找项目中在 before 之前创建的最近一次已完成运行。
+// profileID 不为0时只匹配同一扫描模板的运行，使比较的两次运行具有相同的扫描范围。
+// 找不到时返回 nil, nil。
+func LatestCompleted(db *gorm.DB, projectID, profileID uint, before time.Time) (*model.Task, error) {
+	query := db.Where("project_id = ? AND parent_task_id = 0 AND type = ? AND status = ?", projectID, "workflow", WorkflowStatusCompleted)
+	if profileID != 0 {
+		query = query.Where("scan_profile_id = ?", profileID)
+	}
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	var task model.Task
+	if err := query.Order("created_at DESC, id DESC").First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// baselineCandidates 是查找输入相同的基线运行时最多检查的近期运行数量
+const baselineCandidates = 50
+
+// Baseline 查找在 before 之前与 workflow 可比较的最近一次已完成运行：扫描模板相同，
+// 且同为全量运行，或者 (部分运行时) 扫描的输入完全相同。找不到时返回 nil, nil。
+func Baseline(db *gorm.DB, workflow *model.Task, before time.Time) (*model.Task, error) {
+	query := db.Where("project_id = ? AND parent_task_id = 0 AND type = ? AND status = ? AND scan_profile_id = ?",
+		workflow.ProjectID, "workflow", WorkflowStatusCompleted, workflow.ScanProfileID).
+		Where("created_at < ?", before)
+	if workflow.IsFullRun {
+		query = query.Where("is_full_run = ?", true)
+		var task model.Task
+		if err := query.Order("created_at DESC, id DESC").First(&task).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &task, nil
+	}
+
+	// 部分运行只与扫描了同一组输入的运行比较，否则另一组目标的结果都会被当作新增或消失
+	inputs := inputSet(workflow)
+	var candidates []model.Task
+	if err := query.Order("created_at DESC, id DESC").Limit(baselineCandidates).Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		if inputSet(&candidates[i]) == inputs {
+			return &candidates[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// inputSet 返回工作流起始输入去重排序后拼接的字符串，用于判断两次运行的输入是否相同
+func inputSet(workflow *model.Task) string {
+	var payload struct {
+		Inputs []struct {
+			Value string `json:"value"`
+		} `json:"inputs"`
+	}
+	if err := json.Unmarshal(workflow.Payload, &payload); err != nil {
+		return ""
+	}
+	values := make([]string, 0, len(payload.Inputs))
+	for _, input := range payload.Inputs {
+		values = append(values, input.Value)
+	}
+	sort.Strings(values)
+	values = slices.Compact(values)
+	return strings.Join(values, "\n")
+}
+
+// Summarize 将一次运行与可比较的上一次已完成运行 (见 Baseline) 比较，得到该运行的"新发现"
+func Summarize(db *gorm.DB, workflow *model.Task) (*ChangeSet, error) {
+	previous, err := Baseline(db, workflow, workflow.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(db, previous, workflow)
+}
+
+// Compare 计算两次运行观测结果之间的差异，from 为 nil 时 to 中的所有实体都视为新增。
+// 与判定实体消失 (MarkGone) 一致，只有两次都是全量运行时才报告消失的域名和资产。
+func Compare(db *gorm.DB, from, to *model.Task) (*ChangeSet, error) {
+	result := &ChangeSet{
+		ProjectID:      to.ProjectID,
+		ToWorkflowID:   to.ID,
+		ToCreatedAt:    &to.CreatedAt,
+		NewDomains:     []EntityChange{},
+		RemovedDomains: []EntityChange{},
+		NewAssets:      []EntityChange{},
+		RemovedAssets:  []EntityChange{},
+		ChangedAssets:  []EntityChange{},
+	}
+
+	after, err := loadObservations(db, to.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := map[observationKey]model.Observation{}
+	if from != nil {
+		if from.ProjectID != to.ProjectID {
+			return nil, fmt.Errorf("工作流 %d 和 %d 不属于同一个项目", from.ID, to.ID)
+		}
+		result.FromWorkflowID = from.ID
+		result.FromCreatedAt = &from.CreatedAt
+		if before, err = loadObservations(db, from.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, obs := range after {
+		old, existed := before[key]
+		switch {
+		case !existed && key.kind == model.ObservationKindDomain:
+			result.NewDomains = append(result.NewDomains, toEntityChange(obs, nil))
+		case !existed:
+			result.NewAssets = append(result.NewAssets, toEntityChange(obs, nil))
+		case key.kind == model.ObservationKindAsset:
+			if fields := diffAsset(old, obs); len(fields) > 0 {
+				result.ChangedAssets = append(result.ChangedAssets, toEntityChange(obs, fields))
+			}
+		}
+	}
+	reportRemoved := from != nil && from.IsFullRun && to.IsFullRun
+	for key, obs := range before {
+		if _, ok := after[key]; ok || !reportRemoved {
+			continue
+		}
+		if key.kind == model.ObservationKindDomain {
+			result.RemovedDomains = append(result.RemovedDomains, toEntityChange(obs, nil))
+		} else {
+			result.RemovedAssets = append(result.RemovedAssets, toEntityChange(obs, nil))
+		}
+	}
+
+	for _, list := range [][]EntityChange{result.NewDomains, result.RemovedDomains, result.NewAssets, result.RemovedAssets, result.ChangedAssets} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Value < list[j].Value })
+	}
+	result.Summary = Summary{
+		NewDomains:     len(result.NewDomains),
+		RemovedDomains: len(result.RemovedDomains),
+		NewAssets:      len(result.NewAssets),
+		RemovedAssets:  len(result.RemovedAssets),
+		ChangedAssets:  len(result.ChangedAssets),
+	}
+	return result, nil
+}
+
+// SummaryText 将变化汇总格式化为一行可读文本，用于写入任务结果
+func SummaryText(summary Summary) string {
+	return fmt.Sprintf("新增域名 %d，消失域名 %d，新增资产 %d，消失资产 %d，变化资产 %d",
+		summary.NewDomains, summary.RemovedDomains, summary.NewAssets, summary.RemovedAssets, summary.ChangedAssets)
+}
+
+type observationKey struct {
+	kind     string
+	entityID uint
+}
+
+func loadObservations(db *gorm.DB, workflowID uint) (map[observationKey]model.Observation, error) {
+	var observations []model.Observation
+	if err := db.Where("workflow_id = ?", workflowID).Find(&observations).Error; err != nil {
+		return nil, fmt.Errorf("加载工作流 %d 的观测记录失败: %w", workflowID, err)
+	}
+	byKey := make(map[observationKey]model.Observation, len(observations))
+	for _, obs := range observations {
+		byKey[observationKey{kind: obs.Kind, entityID: obs.EntityID}] = obs
+	}
+	return byKey, nil
+}
+
+// diffAsset 比较资产的标题、Web服务器和技术栈，技术栈按集合比较，忽略顺序
+func diffAsset(old, cur model.Observation) []FieldChange {
+	var fields []FieldChange
+	if old.Title != cur.Title {
+		fields = append(fields, FieldChange{Field: "title", Old: old.Title, New: cur.Title})
+	}
+	if old.WebServer != cur.WebServer {
+		fields = append(fields, FieldChange{Field: "web_server", Old: old.WebServer, New: cur.WebServer})
+	}
+	if oldTech, newTech := joinSorted(old.Technologies), joinSorted(cur.Technologies); oldTech != newTech {
+		fields = append(fields, FieldChange{Field: "technologies", Old: oldTech, New: newTech})
+	}
+	return fields
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func toEntityChange(obs model.Observation, fields []FieldChange) EntityChange {
+	return EntityChange{
+		Kind:     obs.Kind,
+		EntityID: obs.EntityID,
+		Value:    obs.Value,
+		Changes:  fields,
+	}
+}
@@ -0,0 +1,109 @@
+package changes
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// runFixture 在测试库中创建已完成的工作流，并记录它观测到的域名
+type runFixture struct {
+	t       *testing.T
+	db      *gorm.DB
+	project model.Project
+	created time.Time
+}
+
+func newRunFixture(t *testing.T) *runFixture {
+	db := testutil.DB(t)
+	project := model.Project{Name: "changes-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &runFixture{t: t, db: db, project: project, created: time.Now().Add(-time.Hour)}
+}
+
+// run 创建一次运行，inputs 为起始输入，domains 为观测到的域名ID
+func (f *runFixture) run(full bool, inputs []string, domains ...uint) *model.Task {
+	f.t.Helper()
+	type input struct {
+		Value string `json:"value"`
+	}
+	payload := struct {
+		Inputs []input `json:"inputs"`
+	}{}
+	for _, v := range inputs {
+		payload.Inputs = append(payload.Inputs, input{Value: v})
+	}
+	data, _ := json.Marshal(payload)
+
+	f.created = f.created.Add(time.Minute)
+	task := model.Task{
+		ProjectID: f.project.ID, ScanProfileID: 1, Type: "workflow", Status: WorkflowStatusCompleted,
+		IsFullRun: full, Payload: data,
+	}
+	task.CreatedAt = f.created
+	if err := f.db.Create(&task).Error; err != nil {
+		f.t.Fatal(err)
+	}
+	for _, id := range domains {
+		obs := model.Observation{
+			ProjectID: f.project.ID, WorkflowID: task.ID, Kind: model.ObservationKindDomain,
+			EntityID: id, Value: "d" + strconv.Itoa(int(id)) + ".example.com", ObservedAt: f.created,
+		}
+		if err := f.db.Create(&obs).Error; err != nil {
+			f.t.Fatal(err)
+		}
+	}
+	return &task
+}
+
+func TestSummarizePartialRunComparesSameInputs(t *testing.T) {
+	f := newRunFixture(t)
+	runA := f.run(false, []string{"a.example.com"}, 1, 2)
+	f.run(false, []string{"b.example.com"}, 3)
+	cur := f.run(false, []string{"a.example.com"}, 2, 4)
+
+	set, err := Summarize(f.db, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.FromWorkflowID != runA.ID {
+		t.Errorf("基线 = %d, want 输入相同的运行 %d", set.FromWorkflowID, runA.ID)
+	}
+	if set.Summary != (Summary{NewDomains: 1}) {
+		t.Errorf("部分运行的变化 = %+v, want 只新增1个且不报告消失", set.Summary)
+	}
+
+	// 没有输入相同的运行时没有基线
+	other := f.run(false, []string{"c.example.com"}, 5)
+	if set, err = Summarize(f.db, other); err != nil {
+		t.Fatal(err)
+	}
+	if set.FromWorkflowID != 0 {
+		t.Errorf("基线 = %d, want 无", set.FromWorkflowID)
+	}
+}
+
+func TestSummarizeFullRunReportsRemoved(t *testing.T) {
+	f := newRunFixture(t)
+	fullA := f.run(true, []string{"a.example.com", "b.example.com"}, 1, 2, 3)
+	f.run(false, []string{"a.example.com"}, 1)
+	cur := f.run(true, []string{"a.example.com", "b.example.com", "c.example.com"}, 2, 3, 4)
+
+	set, err := Summarize(f.db, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.FromWorkflowID != fullA.ID {
+		t.Errorf("基线 = %d, want 上一次全量运行 %d", set.FromWorkflowID, fullA.ID)
+	}
+	if set.Summary != (Summary{NewDomains: 1, RemovedDomains: 1}) {
+		t.Errorf("全量运行的变化 = %+v, want 新增1 消失1", set.Summary)
+	}
+}
@@ -0,0 +1,45 @@
+package changes
+
+import (
+	"fmt"
+
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+// markGoneSQL 按本次全量运行的观测结果刷新实体的消失状态。
+// 只处理运行开始前就已存在的实体，运行期间由其他工作流新发现的实体保持不变。
+const markGoneSQL = `UPDATE %s t SET is_gone = NOT EXISTS (
+	SELECT 1 FROM observations o
+	WHERE o.workflow_id = @workflow AND o.kind = @kind AND o.entity_id = t.id)
+	WHERE t.project_id = @project AND t.created_at < @started AND t.deleted_at IS NULL`
+
+// MarkGone 在一次全量运行完成后，把未被观测到的域名和资产标记为已消失，重新出现的实体恢复为存活。
+// 某类实体在本次运行中完全没有观测记录时 (如模板不包含该类扫描步骤) 跳过该类，避免误判。
+func MarkGone(db *gorm.DB, workflow *model.Task) error {
+	tables := map[string]string{
+		model.ObservationKindDomain: "domains",
+		model.ObservationKindAsset:  "assets",
+	}
+	for kind, table := range tables {
+		var observed int64
+		if err := db.Model(&model.Observation{}).
+			Where("workflow_id = ? AND kind = ?", workflow.ID, kind).
+			Count(&observed).Error; err != nil {
+			return err
+		}
+		if observed == 0 {
+			continue
+		}
+		err := db.Exec(fmt.Sprintf(markGoneSQL, table), map[string]interface{}{
+			"workflow": workflow.ID,
+			"kind":     kind,
+			"project":  workflow.ProjectID,
+			"started":  workflow.CreatedAt,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("刷新 %s 的消失状态失败: %w", table, err)
+		}
+	}
+	return nil
+}
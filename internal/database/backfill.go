@@ -0,0 +1,125 @@
+package database
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/dnsname"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// normalizeDomains 把规范化入库之前保存的域名改为规范形式并补齐根域名。
+// 规范化后与已有记录重名的域名合并到已有记录：关联的资产、DNS记录、漏洞发现、端点、观测、标签和备注
+// 改为指向保留的记录，然后删除重复的记录。只处理名称可能不规范或根域名为空的行，
+// 新入库的域名都已规范化，所以第一次迁移之后不会再有需要处理的行。
+func normalizeDomains(db *gorm.DB) error {
+	var candidates []model.Domain
+	err := db.Unscoped().
+		Select("id", "project_id", "fqdn", "root_domain", "cdn_provider", "last_seen_at", "is_gone", "deleted_at").
+		Where("fqdn <> LOWER(fqdn) OR fqdn LIKE '%.' OR fqdn LIKE '.%' OR fqdn LIKE '*.%' OR fqdn <> TRIM(fqdn) OR root_domain = ''").
+		Order("id").Find(&candidates).Error
+	if err != nil {
+		return fmt.Errorf("failed to load domains to normalize: %w", err)
+	}
+
+	var renamed, merged int
+	for i := range candidates {
+		d := &candidates[i]
+		fqdn, ok := dnsname.Normalize(d.FQDN)
+		if !ok {
+			// 无法规范化的名称保持原样，只补齐根域名，保证按根域名聚合时不会漏掉
+			if d.RootDomain == "" {
+				if err := db.Unscoped().Model(&model.Domain{}).Where("id = ?", d.ID).
+					Update("root_domain", dnsname.Root(d.FQDN)).Error; err != nil {
+					return fmt.Errorf("failed to backfill root domain of %d: %w", d.ID, err)
+				}
+			}
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var keeper model.Domain
+			err := tx.Unscoped().Where("project_id = ? AND fqdn = ? AND id <> ?", d.ProjectID, fqdn, d.ID).
+				Limit(1).Find(&keeper).Error
+			if err != nil {
+				return err
+			}
+			if keeper.ID == 0 {
+				if fqdn != d.FQDN {
+					renamed++
+				}
+				return tx.Unscoped().Model(&model.Domain{}).Where("id = ?", d.ID).
+					Updates(map[string]interface{}{"fqdn": fqdn, "root_domain": dnsname.Root(fqdn)}).Error
+			}
+			merged++
+			return mergeDomain(tx, d, &keeper)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to normalize domain %d: %w", d.ID, err)
+		}
+	}
+
+	if renamed > 0 || merged > 0 {
+		logger.Logger.Info("已规范化历史域名", zap.Int("renamed", renamed), zap.Int("merged", merged))
+	}
+	return nil
+}
+
+// mergeDomain 把重复的域名 dup 合并到 keeper：引用 dup 的记录改为引用 keeper，
+// 带唯一约束的关联在 keeper 已有相同关联时丢弃 dup 的那一条，最后删除 dup。
+func mergeDomain(tx *gorm.DB, dup, keeper *model.Domain) error {
+	stmts := []struct {
+		sql  string
+		args []interface{}
+	}{
+		{"INSERT INTO asset_domain_mappings (asset_id, domain_id) SELECT asset_id, ? FROM asset_domain_mappings WHERE domain_id = ? ON CONFLICT DO NOTHING", []interface{}{keeper.ID, dup.ID}},
+		{"DELETE FROM asset_domain_mappings WHERE domain_id = ?", []interface{}{dup.ID}},
+		{"UPDATE dns_records SET domain_id = ? WHERE domain_id = ?", []interface{}{keeper.ID, dup.ID}},
+		{"UPDATE findings SET domain_id = ? WHERE domain_id = ?", []interface{}{keeper.ID, dup.ID}},
+		{"UPDATE endpoints SET domain_id = ? WHERE domain_id = ?", []interface{}{keeper.ID, dup.ID}},
+		{"DELETE FROM observations WHERE kind = 'domain' AND entity_id = ? AND workflow_id IN (SELECT workflow_id FROM observations WHERE kind = 'domain' AND entity_id = ?)", []interface{}{dup.ID, keeper.ID}},
+		{"UPDATE observations SET entity_id = ? WHERE kind = 'domain' AND entity_id = ?", []interface{}{keeper.ID, dup.ID}},
+		{"DELETE FROM taggings WHERE entity_type = 'domain' AND entity_id = ? AND tag_id IN (SELECT tag_id FROM taggings WHERE entity_type = 'domain' AND entity_id = ?)", []interface{}{dup.ID, keeper.ID}},
+		{"UPDATE taggings SET entity_id = ? WHERE entity_type = 'domain' AND entity_id = ?", []interface{}{keeper.ID, dup.ID}},
+		{"UPDATE notes SET entity_id = ? WHERE entity_type = 'domain' AND entity_id = ?", []interface{}{keeper.ID, dup.ID}},
+	}
+	for _, s := range stmts {
+		if err := tx.Exec(s.sql, s.args...).Error; err != nil {
+			return err
+		}
+	}
+
+	// 保留两者中较新的观测状态，任一条未被删除时保留的记录也不应处于删除状态
+	updates := map[string]interface{}{"root_domain": dnsname.Root(keeper.FQDN)}
+	if dup.LastSeenAt.After(keeper.LastSeenAt) {
+		updates["last_seen_at"] = dup.LastSeenAt
+		updates["is_gone"] = dup.IsGone
+	}
+	if keeper.CDNProvider == "" && dup.CDNProvider != "" {
+		updates["cdn_provider"] = dup.CDNProvider
+	}
+	if keeper.DeletedAt.Valid && !dup.DeletedAt.Valid {
+		updates["deleted_at"] = nil
+	}
+	if err := tx.Unscoped().Model(&model.Domain{}).Where("id = ?", keeper.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&model.Domain{}, dup.ID).Error
+}
+
+// migrateAssetTransport 把资产的唯一键从 (项目, IP, 端口) 改为 (项目, IP, 端口, 传输层协议)：
+// 之前未记录传输层协议的资产视为 tcp，新的唯一索引由 AutoMigrate 创建后删除旧索引。
+// 旧索引保证每个 IP:端口 只有一行，补齐传输层协议不会产生冲突。
+func migrateAssetTransport(db *gorm.DB) error {
+	if err := db.Exec("UPDATE assets SET transport = ? WHERE transport IS NULL OR transport = ''", model.TransportTCP).Error; err != nil {
+		return fmt.Errorf("failed to backfill asset transport: %w", err)
+	}
+	if err := db.Exec("UPDATE assets SET transport = LOWER(transport) WHERE transport <> LOWER(transport)").Error; err != nil {
+		return fmt.Errorf("failed to normalize asset transport: %w", err)
+	}
+	if err := db.Exec("DROP INDEX IF EXISTS idx_asset_unique_in_project").Error; err != nil {
+		return fmt.Errorf("failed to drop old asset index: %w", err)
+	}
+	return nil
+}
@@ -27,7 +27,16 @@ func InitDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	}
 	logger.Logger.Info("连接数据库成功")
 
-	err = db.AutoMigrate(
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	logger.Logger.Info("数据库迁移成功")
+	return db, nil
+}
+
+// Migrate 创建或更新所有表结构和额外的索引，并规范化之前保存的历史域名
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
 		&model.Project{},
 		&model.ProjectTarget{},
 		&model.Asset{},
@@ -37,12 +46,46 @@ func InitDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 		&model.Task{},
 		&model.ScanProfile{},
 		&model.TaskOutput{},
+		&model.ScopeRule{},
+		&model.OutOfScopeItem{},
+		&model.ScanSchedule{},
+		&model.Observation{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.ChatChannel{},
+		&model.ChatDigestEntry{},
+		&model.Finding{},
+		&model.Endpoint{},
+		&model.DNSRecord{},
+		&model.DNSWildcard{},
+		&model.Certificate{},
+		&model.Tag{},
+		&model.Tagging{},
+		&model.Note{},
+		&model.TagRule{},
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to migrate projects: %w", err)
+		return fmt.Errorf("failed to migrate projects: %w", err)
 	}
-	logger.Logger.Info("数据库迁移成功")
-	return db, nil
+
+	// 游标分页按 (created_at, id) 倒序扫描，为大表建立对应的复合索引
+	keysetIndexes := []string{
+		"CREATE INDEX IF NOT EXISTS idx_domains_keyset ON domains (project_id, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_assets_keyset ON assets (project_id, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_tasks_keyset ON tasks (project_id, parent_task_id, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_findings_keyset ON findings (project_id, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_endpoints_keyset ON endpoints (project_id, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_notes_keyset ON notes (project_id, created_at DESC, id DESC)",
+	}
+	for _, stmt := range keysetIndexes {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to create keyset index: %w", err)
+		}
+	}
+	if err := migrateAssetTransport(db); err != nil {
+		return err
+	}
+	return normalizeDomains(db)
 }
 
 func GetDB() *gorm.DB {
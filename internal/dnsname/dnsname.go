@@ -0,0 +1,54 @@
+// Package dnsname 规范化域名并按公共后缀列表 (Public Suffix List) 计算根域名，
+// 保证不同工具输出的同一域名 (大小写、末尾的点、通配符前缀、国际化域名) 入库时是同一条记录。
+package dnsname
+
+import (
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+	"net"
+	"strings"
+)
+
+// profile 按 UTS #46 把国际化域名转换为 punycode。
+// 不启用 STD3 规则，以保留 _dmarc、_domainkey 这类包含下划线的合法DNS名称。
+var profile = idna.New(
+	idna.MapForLookup(),
+	idna.StrictDomainName(false),
+	idna.BidiRule(),
+	idna.VerifyDNSLength(true),
+	idna.Transitional(false),
+)
+
+// Normalize 返回域名的规范形式：去掉首尾空白、末尾的点和通配符前缀 (*.)，
+// 转为小写，国际化域名转换为 punycode。名称不是合法域名 (包括IP地址) 时返回 false。
+func Normalize(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	name = strings.TrimRight(name, ".")
+	for strings.HasPrefix(name, "*.") {
+		name = name[2:]
+	}
+	name = strings.TrimLeft(name, ".")
+	if name == "" || strings.ContainsAny(name, " /:@*") || net.ParseIP(name) != nil {
+		return "", false
+	}
+
+	ascii, err := profile.ToASCII(name)
+	if err != nil {
+		return "", false
+	}
+	ascii = strings.ToLower(ascii)
+	if !strings.Contains(ascii, ".") {
+		return "", false
+	}
+	return ascii, true
+}
+
+// Root 返回域名按公共后缀列表计算出的根域名 (可注册域名)，如 a.b.example.co.uk 的根域名为 example.co.uk。
+// 域名本身就是公共后缀 (如 co.uk) 时返回域名本身。传入的域名应已规范化。
+func Root(fqdn string) string {
+	root, err := publicsuffix.EffectiveTLDPlusOne(fqdn)
+	if err != nil {
+		return fqdn
+	}
+	return root
+}
@@ -0,0 +1,56 @@
+package dnsname
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"www.example.com", "www.example.com", true},
+		{"  WWW.Example.COM.  ", "www.example.com", true},
+		{"*.example.com", "example.com", true},
+		{"*.*.api.example.com", "api.example.com", true},
+		{".example.com", "example.com", true},
+		{"_dmarc.example.com", "_dmarc.example.com", true},
+		{"münchen.de", "xn--mnchen-3ya.de", true},
+		{"例子.测试", "xn--fsqu00a.xn--0zwm56d", true},
+		{"xn--mnchen-3ya.de", "xn--mnchen-3ya.de", true},
+		{"", "", false},
+		{"localhost", "", false},
+		{"192.0.2.1", "", false},
+		{"2001:db8::1", "", false},
+		{"https://www.example.com/", "", false},
+		{"user@example.com", "", false},
+		{"www example.com", "", false},
+		{"a.*.example.com", "", false},
+		{"a..example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	tests := []struct {
+		fqdn string
+		want string
+	}{
+		{"example.com", "example.com"},
+		{"a.b.example.com", "example.com"},
+		{"www.example.co.uk", "example.co.uk"},
+		{"www.example.com.cn", "example.com.cn"},
+		{"foo.github.io", "foo.github.io"},
+		{"co.uk", "co.uk"},
+		{"com", "com"},
+	}
+	for _, tt := range tests {
+		if got := Root(tt.fqdn); got != tt.want {
+			t.Errorf("Root(%q) = %q, want %q", tt.fqdn, got, tt.want)
+		}
+	}
+}
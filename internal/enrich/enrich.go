@@ -0,0 +1,196 @@
+// Package enrich 使用离线数据为资产IP补充归属信息 (ASN、组织、国家、CDN/云服务商)，
+// 结果缓存在 IPMetadata 中，超过有效期后重新查询。
+package enrich
+
+import (
+	"errors"
+	"github.com/src-hunter/internal/cdn"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/pkg/config"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io/fs"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultTTL 是未配置有效期时IP信息的缓存时间
+const defaultTTL = 7 * 24 * time.Hour
+
+// 补充信息的数据来源
+const (
+	SourceGeoIP    = "geoip"
+	SourceIPRanges = "ip_ranges"
+)
+
+// Enricher 为IP补充归属信息
+type Enricher struct {
+	DB     *gorm.DB
+	GeoIP  *GeoIP
+	Ranges cdn.Ranges
+	TTL    time.Duration
+}
+
+// New 根据配置加载离线数据库和IP段列表。内置的CDN节点IP段总会加载，
+// 配置的文件不存在时跳过对应的补充，文件损坏时记录警告。
+func New(db *gorm.DB, cfg *config.EnrichConfig) *Enricher {
+	e := &Enricher{DB: db, TTL: defaultTTL, Ranges: cdn.Builtin()}
+	if cfg.TTLHours > 0 {
+		e.TTL = time.Duration(cfg.TTLHours) * time.Hour
+	}
+
+	geoIP, err := OpenGeoIP(existingPath(cfg.ASNDatabase), existingPath(cfg.GeoDatabase))
+	if err != nil {
+		logger.Logger.Warn("加载离线GeoIP数据库失败，跳过GeoIP补充", zap.Error(err))
+	} else if geoIP.asn != nil || geoIP.geo != nil {
+		e.GeoIP = geoIP
+	}
+
+	if path := existingPath(cfg.IPRanges); path != "" {
+		ranges, err := cdn.LoadRanges(path)
+		if err != nil {
+			logger.Logger.Warn("加载IP段列表失败，只使用内置的CDN节点IP段", zap.String("path", path), zap.Error(err))
+		} else {
+			e.Ranges = cdn.Merge(ranges, e.Ranges)
+		}
+	}
+	return e
+}
+
+// existingPath 文件存在时原样返回路径，否则返回空字符串
+func existingPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Logger.Warn("无法读取补充数据文件", zap.String("path", path), zap.Error(err))
+		}
+		return ""
+	}
+	return path
+}
+
+// Enabled 返回是否有可用的补充数据
+func (e *Enricher) Enabled() bool {
+	return e != nil && (e.GeoIP != nil || len(e.Ranges) > 0)
+}
+
+// EnrichAssets 为资产的IP补充归属信息，并据此标记CDN资产及其关联的域名
+func (e *Enricher) EnrichAssets(assets []model.Asset) error {
+	ips := make([]string, 0, len(assets))
+	ids := make([]uint, 0, len(assets))
+	for _, a := range assets {
+		ips = append(ips, a.IP)
+		if a.ID != 0 {
+			ids = append(ids, a.ID)
+		}
+	}
+	if err := e.EnrichIPs(ips); err != nil {
+		return err
+	}
+	return e.markCDN(ids)
+}
+
+// markCDN 把IP落在CDN节点IP段中的资产标记为CDN资产，再把CDN资产关联的域名标记为接入CDN。
+// 已有的标记 (如 httpx 按响应头识别的) 不会被覆盖。
+func (e *Enricher) markCDN(assetIDs []uint) error {
+	if e == nil || len(assetIDs) == 0 {
+		return nil
+	}
+	if err := e.DB.Exec(`UPDATE assets SET cdn_provider = im.cloud_provider FROM ip_metadata im
+		WHERE im.ip = assets.ip AND im.is_cdn AND assets.id IN ? AND assets.cdn_provider = ''`, assetIDs).Error; err != nil {
+		return err
+	}
+	return e.DB.Exec(`UPDATE domains SET cdn_provider = a.cdn_provider
+		FROM asset_domain_mappings m JOIN assets a ON a.id = m.asset_id
+		WHERE m.domain_id = domains.id AND a.id IN ? AND a.cdn_provider <> '' AND domains.cdn_provider = ''`, assetIDs).Error
+}
+
+// EnrichIPs 查询尚无归属信息或信息已过期的IP，并写入 IPMetadata
+func (e *Enricher) EnrichIPs(ips []string) error {
+	if !e.Enabled() {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(ips))
+	var candidates []string
+	for _, ip := range ips {
+		if ip != "" && !seen[ip] {
+			seen[ip] = true
+			candidates = append(candidates, ip)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var fresh []string
+	if err := e.DB.Model(&model.IPMetadata{}).
+		Where("ip IN ? AND updated_at > ?", candidates, time.Now().Add(-e.TTL)).
+		Pluck("ip", &fresh).Error; err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(fresh))
+	for _, ip := range fresh {
+		skip[ip] = true
+	}
+
+	var records []model.IPMetadata
+	for _, ip := range candidates {
+		if skip[ip] {
+			continue
+		}
+		if record, ok := e.lookup(ip); ok {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	return e.DB.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(&records, 500).Error
+}
+
+// lookup 查询单个IP的归属信息。查不到的IP也会记录，避免在有效期内重复查询。
+func (e *Enricher) lookup(ip string) (model.IPMetadata, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return model.IPMetadata{}, false
+	}
+	record := model.IPMetadata{IP: ip}
+	var sources []string
+
+	if e.GeoIP != nil {
+		info, found, err := e.GeoIP.Lookup(net.IP(addr.AsSlice()))
+		if err != nil {
+			logger.Logger.Warn("查询GeoIP数据库失败", zap.String("ip", ip), zap.Error(err))
+		} else {
+			sources = append(sources, SourceGeoIP)
+			if found {
+				record.ASN = info.ASN
+				record.Organization = info.Organization
+				record.CountryCode = info.CountryCode
+				record.City = info.City
+			}
+		}
+	}
+	if rng, ok := e.Ranges.Match(addr); ok {
+		record.CloudProvider = rng.Provider
+		record.IsCDN = rng.IsCDN
+		sources = append(sources, SourceIPRanges)
+	}
+	record.Source = strings.Join(sources, ",")
+	return record, true
+}
+
+// Close 释放已打开的数据库
+func (e *Enricher) Close() {
+	if e != nil && e.GeoIP != nil {
+		e.GeoIP.Close()
+	}
+}
@@ -0,0 +1,97 @@
+package enrich
+
+import (
+	"fmt"
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+)
+
+// asnRecord 对应 GeoLite2-ASN 数据库中的字段
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// geoRecord 对应 GeoLite2-City / GeoLite2-Country 数据库中的字段，国家库没有城市信息
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// GeoInfo 是从离线数据库查到的IP归属信息
+type GeoInfo struct {
+	ASN          string
+	Organization string
+	CountryCode  string
+	City         string
+}
+
+// GeoIP 查询本地 MaxMind 格式 (MMDB) 的ASN和地理位置数据库，任一数据库可以缺省
+type GeoIP struct {
+	asn *maxminddb.Reader
+	geo *maxminddb.Reader
+}
+
+// OpenGeoIP 打开ASN和地理位置数据库，路径为空表示不使用该数据库
+func OpenGeoIP(asnPath, geoPath string) (*GeoIP, error) {
+	g := &GeoIP{}
+	if asnPath != "" {
+		reader, err := maxminddb.Open(asnPath)
+		if err != nil {
+			return nil, fmt.Errorf("打开ASN数据库 %s 失败: %w", asnPath, err)
+		}
+		g.asn = reader
+	}
+	if geoPath != "" {
+		reader, err := maxminddb.Open(geoPath)
+		if err != nil {
+			g.Close()
+			return nil, fmt.Errorf("打开地理位置数据库 %s 失败: %w", geoPath, err)
+		}
+		g.geo = reader
+	}
+	return g, nil
+}
+
+// Lookup 查询IP的归属信息，两个数据库都没有记录时返回 false
+func (g *GeoIP) Lookup(ip net.IP) (GeoInfo, bool, error) {
+	var info GeoInfo
+	found := false
+	if g.asn != nil {
+		var record asnRecord
+		if err := g.asn.Lookup(ip, &record); err != nil {
+			return info, false, err
+		}
+		if record.Number != 0 {
+			info.ASN = fmt.Sprintf("AS%d", record.Number)
+			info.Organization = record.Organization
+			found = true
+		}
+	}
+	if g.geo != nil {
+		var record geoRecord
+		if err := g.geo.Lookup(ip, &record); err != nil {
+			return info, false, err
+		}
+		if record.Country.ISOCode != "" {
+			info.CountryCode = record.Country.ISOCode
+			info.City = record.City.Names["en"]
+			found = true
+		}
+	}
+	return info, found, nil
+}
+
+// Close 关闭已打开的数据库
+func (g *GeoIP) Close() {
+	if g.asn != nil {
+		g.asn.Close()
+	}
+	if g.geo != nil {
+		g.geo.Close()
+	}
+}
@@ -0,0 +1,128 @@
+package ingest
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityAsset, StageEntity, PersisterFunc(persistAssets))
+	RegisterRelationship(parser.EntityAsset, parser.EntityDomain, RelationshipPersisterFunc(persistAssetDomainLinks))
+}
+
+// assetUpsertAssignments 返回资产冲突时的更新语句：刷新最后发现时间，文本属性只在本次非空时覆盖
+func assetUpsertAssignments() clause.Set {
+	assignments := map[string]interface{}{
+		"last_seen_at": gorm.Expr("EXCLUDED.last_seen_at"),
+		"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+		"is_gone":      false,
+		"technologies": gorm.Expr("COALESCE(EXCLUDED.technologies, assets.technologies)"),
+	}
+	for _, column := range []string{"title", "web_server", "protocol", "product", "version", "host_state", "cdn_provider"} {
+		assignments[column] = gorm.Expr(fmt.Sprintf("COALESCE(NULLIF(EXCLUDED.%s, ''), assets.%s)", column, column))
+	}
+	return clause.Assignments(assignments)
+}
+
+// persistAssets 批量保存资产。不同工具只能识别资产的部分属性 (如 nmap 没有标题、httpx 没有产品版本)，
+// 更新时只覆盖本次识别到的非空属性。资产按 (IP, 端口, 传输层协议) 区分，未识别到传输层协议时视为 tcp。
+// 解析结果中保留本次识别到的属性，只回填数据库ID。
+// 按域名扇出的任务会把本次的资产关联到来源域名。
+func persistAssets(ctx *Context, result *parser.ParseResult) error {
+	for i := range result.Assets {
+		result.Assets[i].Transport = parser.NormalizeTransport(result.Assets[i].Transport)
+	}
+	result.Assets = dedupe(result.Assets, func(a *model.Asset) string { return parser.AssetKey(a.IP, a.Port, a.Transport) })
+	if len(result.Assets) == 0 {
+		return nil
+	}
+
+	for i := range result.Assets {
+		a := &result.Assets[i]
+		a.ProjectID = ctx.Task.ProjectID
+		a.CreatedAt = ctx.Now
+		a.UpdatedAt = ctx.Now
+		a.LastSeenAt = ctx.Now
+	}
+	saved, err := upsert(ctx, result.Assets, 500, clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "ip"}, {Name: "port"}, {Name: "transport"}},
+		DoUpdates: assetUpsertAssignments(),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityAsset, saved, func(a *model.Asset) time.Time { return a.CreatedAt })
+
+	ids := make(map[string]uint, len(saved))
+	for _, a := range saved {
+		ids[parser.AssetKey(a.IP, a.Port, a.Transport)] = a.ID
+	}
+	for i := range result.Assets {
+		a := &result.Assets[i]
+		a.ID = ids[parser.AssetKey(a.IP, a.Port, a.Transport)]
+	}
+
+	if ctx.ViaDomainID == 0 {
+		return nil
+	}
+	mappings := make([]model.AssetDomainMapping, 0, len(saved))
+	for _, a := range saved {
+		mappings = append(mappings, model.AssetDomainMapping{AssetID: a.ID, DomainID: ctx.ViaDomainID})
+	}
+	return createMappings(ctx, mappings)
+}
+
+// persistAssetDomainLinks 把解析器给出的资产与域名关联 (如 nmap 的主机名) 写入 AssetDomainMapping。
+// 被范围规则过滤掉的域名或资产对应的关联会被忽略。
+func persistAssetDomainLinks(ctx *Context, relationships []parser.Relationship) error {
+	assetKeys := make(map[string]bool)
+	var fqdns []string
+	var keys [][]interface{}
+	for _, rel := range relationships {
+		fqdns = append(fqdns, rel.To.Key)
+		if assetKeys[rel.From.Key] {
+			continue
+		}
+		ip, port, transport, ok := parser.SplitAssetKey(rel.From.Key)
+		if !ok {
+			continue
+		}
+		assetKeys[rel.From.Key] = true
+		keys = append(keys, []interface{}{ip, port, transport})
+	}
+	domains, err := domainIDs(ctx, fqdns)
+	if err != nil {
+		return err
+	}
+	assets, err := assetIDs(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	var mappings []model.AssetDomainMapping
+	for _, rel := range relationships {
+		assetID, ok1 := assets[rel.From.Key]
+		domainID, ok2 := domains[rel.To.Key]
+		if ok1 && ok2 {
+			mappings = append(mappings, model.AssetDomainMapping{AssetID: assetID, DomainID: domainID})
+		}
+	}
+	return createMappings(ctx, mappings)
+}
+
+// createMappings 写入资产与域名关联，已存在的关联保持不变，只统计新增的数量
+func createMappings(ctx *Context, mappings []model.AssetDomainMapping) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+	tx := ctx.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mappings)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	ctx.Record(parser.EntityRelationship, int(tx.RowsAffected), 0)
+	return nil
+}
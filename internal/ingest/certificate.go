@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityCertificate, StageDependent, PersisterFunc(persistCertificates))
+}
+
+// persistCertificates 保存解析出的TLS证书并关联到提供证书的 (tcp) 资产
+func persistCertificates(ctx *Context, result *parser.ParseResult) error {
+	result.Certificates = dedupe(result.Certificates, func(c *model.Certificate) string {
+		return parser.AssetKey(c.IP, c.Port, model.TransportTCP) + "|" + c.FingerprintSHA256
+	})
+	if len(result.Certificates) == 0 {
+		return nil
+	}
+
+	keys := make([][]interface{}, 0, len(result.Certificates))
+	for _, c := range result.Certificates {
+		keys = append(keys, []interface{}{c.IP, c.Port, model.TransportTCP})
+	}
+	assets, err := assetIDs(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Certificates {
+		c := &result.Certificates[i]
+		c.ProjectID = ctx.Task.ProjectID
+		c.CreatedAt = ctx.Now
+		c.UpdatedAt = ctx.Now
+		c.AssetID = assets[parser.AssetKey(c.IP, c.Port, model.TransportTCP)]
+		c.LastSeenAt = ctx.Now
+	}
+
+	saved, err := upsert(ctx, result.Certificates, 200, clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "ip"}, {Name: "port"}, {Name: "fingerprint_sha256"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"asset_id":      gorm.Expr("COALESCE(NULLIF(EXCLUDED.asset_id, 0), certificates.asset_id)"),
+			"host":          gorm.Expr("EXCLUDED.host"),
+			"is_mismatched": gorm.Expr("EXCLUDED.is_mismatched"),
+			"last_seen_at":  gorm.Expr("EXCLUDED.last_seen_at"),
+			"updated_at":    gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityCertificate, saved, func(c *model.Certificate) time.Time { return c.CreatedAt })
+	result.Certificates = saved
+	return nil
+}
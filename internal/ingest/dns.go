@@ -0,0 +1,60 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityDNSRecord, StageDependent, PersisterFunc(persistDNSRecords))
+}
+
+// persistDNSRecords 保存解析出的DNS记录，已存在的记录刷新悬空标记和最后发现时间
+func persistDNSRecords(ctx *Context, result *parser.ParseResult) error {
+	result.DNSRecords = dedupe(result.DNSRecords, func(r *model.DNSRecord) string { return r.FQDN + "|" + r.Type + "|" + r.Value })
+	if len(result.DNSRecords) == 0 {
+		return nil
+	}
+
+	var fqdns []string
+	seen := make(map[string]bool)
+	for _, r := range result.DNSRecords {
+		if !seen[r.FQDN] {
+			seen[r.FQDN] = true
+			fqdns = append(fqdns, r.FQDN)
+		}
+	}
+	domains, err := domainIDs(ctx, fqdns)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.DNSRecords {
+		r := &result.DNSRecords[i]
+		r.ProjectID = ctx.Task.ProjectID
+		r.CreatedAt = ctx.Now
+		r.UpdatedAt = ctx.Now
+		r.DomainID = domains[r.FQDN]
+		r.LastSeenAt = ctx.Now
+	}
+
+	saved, err := upsert(ctx, result.DNSRecords, 500, clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "fqdn"}, {Name: "type"}, {Name: "value"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"domain_id":        gorm.Expr("COALESCE(NULLIF(EXCLUDED.domain_id, 0), dns_records.domain_id)"),
+			"is_dangling":      gorm.Expr("EXCLUDED.is_dangling"),
+			"takeover_service": gorm.Expr("EXCLUDED.takeover_service"),
+			"last_seen_at":     gorm.Expr("EXCLUDED.last_seen_at"),
+			"updated_at":       gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityDNSRecord, saved, func(r *model.DNSRecord) time.Time { return r.CreatedAt })
+	result.DNSRecords = saved
+	return nil
+}
@@ -0,0 +1,48 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/dnsname"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityDomain, StageEntity, PersisterFunc(persistDomains))
+}
+
+// persistDomains 保存域名，已存在的域名刷新最后发现时间和根域名 (按公共后缀列表计算)，CDN服务商只在本次识别到时覆盖。
+// 保存后用库中的记录 (带ID) 替换解析结果中的域名，供后续步骤扇出和变化检测使用。
+func persistDomains(ctx *Context, result *parser.ParseResult) error {
+	result.Domains = dedupe(result.Domains, func(d *model.Domain) string { return d.FQDN })
+	if len(result.Domains) == 0 {
+		return nil
+	}
+
+	for i := range result.Domains {
+		d := &result.Domains[i]
+		d.ProjectID = ctx.Task.ProjectID
+		d.CreatedAt = ctx.Now
+		d.UpdatedAt = ctx.Now
+		d.LastSeenAt = ctx.Now
+		d.RootDomain = dnsname.Root(d.FQDN)
+	}
+	saved, err := upsert(ctx, result.Domains, 500, clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "fqdn"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"last_seen_at": gorm.Expr("EXCLUDED.last_seen_at"),
+			"is_gone":      gorm.Expr("EXCLUDED.is_gone"),
+			"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+			"root_domain":  gorm.Expr("EXCLUDED.root_domain"),
+			"cdn_provider": gorm.Expr("COALESCE(NULLIF(EXCLUDED.cdn_provider, ''), domains.cdn_provider)"),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityDomain, saved, func(d *model.Domain) time.Time { return d.CreatedAt })
+	result.Domains = saved
+	return nil
+}
@@ -0,0 +1,62 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityEndpoint, StageDependent, PersisterFunc(persistEndpoints))
+}
+
+// persistEndpoints 保存解析出的Web端点，按 (项目, 去重键) 去重。
+// 已存在的端点刷新最后发现时间，响应信息只在本次非空时覆盖 (历史URL工具不会请求目标)。
+func persistEndpoints(ctx *Context, result *parser.ParseResult) error {
+	result.Endpoints = dedupe(result.Endpoints, func(e *model.Endpoint) string { return e.URLKey })
+	if len(result.Endpoints) == 0 {
+		return nil
+	}
+
+	hostSet := make(map[string]bool)
+	var hosts []string
+	for _, e := range result.Endpoints {
+		if !hostSet[e.Host] {
+			hostSet[e.Host] = true
+			hosts = append(hosts, e.Host)
+		}
+	}
+	domains, err := domainIDs(ctx, hosts)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Endpoints {
+		e := &result.Endpoints[i]
+		e.ProjectID = ctx.Task.ProjectID
+		e.CreatedAt = ctx.Now
+		e.UpdatedAt = ctx.Now
+		e.DomainID = domains[e.Host]
+		e.LastSeenAt = ctx.Now
+	}
+
+	saved, err := upsert(ctx, result.Endpoints, 500, clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "url_key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"domain_id":      gorm.Expr("COALESCE(NULLIF(EXCLUDED.domain_id, 0), endpoints.domain_id)"),
+			"status_code":    gorm.Expr("COALESCE(NULLIF(EXCLUDED.status_code, 0), endpoints.status_code)"),
+			"content_type":   gorm.Expr("COALESCE(NULLIF(EXCLUDED.content_type, ''), endpoints.content_type)"),
+			"content_length": gorm.Expr("COALESCE(NULLIF(EXCLUDED.content_length, 0), endpoints.content_length)"),
+			"last_seen_at":   gorm.Expr("EXCLUDED.last_seen_at"),
+			"updated_at":     gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityEndpoint, saved, func(e *model.Endpoint) time.Time { return e.CreatedAt })
+	result.Endpoints = saved
+	return nil
+}
@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+func init() {
+	Register(parser.EntityFinding, StageDependent, PersisterFunc(persistFindings))
+}
+
+// persistFindings 保存解析出的漏洞发现，按 (项目, 指纹) 去重。
+// 已存在的发现刷新证据和最后发现时间；已修复的发现再次出现时重新打开，误报和已确认的保持原状态。
+func persistFindings(ctx *Context, result *parser.ParseResult) error {
+	result.Findings = dedupe(result.Findings, func(f *model.Finding) string { return f.Fingerprint })
+	if len(result.Findings) == 0 {
+		return nil
+	}
+
+	// 按主机名关联域名、按 (IP, 端口) 关联 tcp 资产
+	var hosts []string
+	var keys [][]interface{}
+	for _, f := range result.Findings {
+		if f.Host != "" {
+			hosts = append(hosts, f.Host)
+		}
+		if f.IP != "" && f.Port > 0 {
+			keys = append(keys, []interface{}{f.IP, f.Port, model.TransportTCP})
+		}
+	}
+	domains, err := domainIDs(ctx, hosts)
+	if err != nil {
+		return err
+	}
+	assets, err := assetIDs(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Findings {
+		f := &result.Findings[i]
+		f.ProjectID = ctx.Task.ProjectID
+		f.CreatedAt = ctx.Now
+		f.UpdatedAt = ctx.Now
+		f.TaskID = ctx.Task.ID
+		f.Status = model.FindingStatusOpen
+		f.LastSeenAt = ctx.Now
+		f.DomainID = domains[f.Host]
+		f.AssetID = assets[parser.AssetKey(f.IP, f.Port, model.TransportTCP)]
+	}
+
+	saved, err := upsert(ctx, result.Findings, 200, clause.OnConflict{
+		Columns: []clause.Column{{Name: "project_id"}, {Name: "fingerprint"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"name":              gorm.Expr("EXCLUDED.name"),
+			"severity":          gorm.Expr("EXCLUDED.severity"),
+			"extracted_results": gorm.Expr("EXCLUDED.extracted_results"),
+			"request":           gorm.Expr("EXCLUDED.request"),
+			"response":          gorm.Expr("EXCLUDED.response"),
+			"asset_id":          gorm.Expr("COALESCE(NULLIF(EXCLUDED.asset_id, 0), findings.asset_id)"),
+			"domain_id":         gorm.Expr("COALESCE(NULLIF(EXCLUDED.domain_id, 0), findings.domain_id)"),
+			"task_id":           gorm.Expr("EXCLUDED.task_id"),
+			"last_seen_at":      gorm.Expr("EXCLUDED.last_seen_at"),
+			"updated_at":        gorm.Expr("EXCLUDED.updated_at"),
+			"status": gorm.Expr("CASE WHEN findings.status = ? THEN ? ELSE findings.status END",
+				model.FindingStatusFixed, model.FindingStatusOpen),
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	record(ctx, parser.EntityFinding, saved, func(f *model.Finding) time.Time { return f.CreatedAt })
+	result.Findings = saved
+	return nil
+}
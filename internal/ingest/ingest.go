@@ -0,0 +1,137 @@
+// Package ingest 负责把解析器产出的实体和关系写入数据库。
+// 每种实体类型注册各自的持久化器，由持久化器负责该类型的去重键和更新规则，
+// 新增实体类型时只需注册持久化器，无需修改任务处理流程。
+package ingest
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+// 持久化阶段：同一阶段内按注册名称顺序执行，后面的阶段可以引用前面阶段写入的实体ID
+const (
+	// StageEntity 是独立实体 (域名、资产) 的阶段
+	StageEntity = 0
+	// StageDependent 是需要关联域名或资产ID的实体 (漏洞、端点、DNS记录、证书) 的阶段
+	StageDependent = 1
+)
+
+// Context 是一次持久化的上下文，DB 为本次入库的事务
+type Context struct {
+	DB   *gorm.DB
+	Task *model.Task
+	// ViaDomainID 是按域名扇出时当前输入对应的域名ID，本次发现的资产会关联到该域名
+	ViaDomainID uint
+	// Now 是本次入库的时间，精确到微秒以便与数据库返回的创建时间比较
+	Now     time.Time
+	summary Summary
+}
+
+// Record 记录一类实体本次新增和更新的数量
+func (ctx *Context) Record(entity string, created, updated int) {
+	if ctx.summary == nil {
+		ctx.summary = make(Summary)
+	}
+	c := ctx.summary[entity]
+	c.New += created
+	c.Updated += updated
+	ctx.summary[entity] = c
+}
+
+// isNew 判断 upsert 返回的行是否为本次新插入的：已存在的行保留原有的创建时间
+func (ctx *Context) isNew(createdAt time.Time) bool {
+	return createdAt.Equal(ctx.Now)
+}
+
+// Persister 保存解析结果中的一类实体
+type Persister interface {
+	Persist(ctx *Context, result *parser.ParseResult) error
+}
+
+// PersisterFunc 让普通函数可以作为 Persister 注册
+type PersisterFunc func(ctx *Context, result *parser.ParseResult) error
+
+func (f PersisterFunc) Persist(ctx *Context, result *parser.ParseResult) error {
+	return f(ctx, result)
+}
+
+// RelationshipPersister 保存一类实体之间的关系，两端实体都已在之前的阶段入库
+type RelationshipPersister interface {
+	Persist(ctx *Context, relationships []parser.Relationship) error
+}
+
+// RelationshipPersisterFunc 让普通函数可以作为 RelationshipPersister 注册
+type RelationshipPersisterFunc func(ctx *Context, relationships []parser.Relationship) error
+
+func (f RelationshipPersisterFunc) Persist(ctx *Context, relationships []parser.Relationship) error {
+	return f(ctx, relationships)
+}
+
+type registration struct {
+	entity    string
+	stage     int
+	persister Persister
+}
+
+var (
+	persisters    []registration
+	relationships = make(map[[2]string]RelationshipPersister)
+)
+
+// Register 注册一种实体类型的持久化器，重复注册会 panic
+func Register(entity string, stage int, p Persister) {
+	for _, r := range persisters {
+		if r.entity == entity {
+			panic(fmt.Sprintf("ingest: 实体类型 %s 的持久化器重复注册", entity))
+		}
+	}
+	persisters = append(persisters, registration{entity: entity, stage: stage, persister: p})
+	sort.SliceStable(persisters, func(i, j int) bool {
+		if persisters[i].stage != persisters[j].stage {
+			return persisters[i].stage < persisters[j].stage
+		}
+		return persisters[i].entity < persisters[j].entity
+	})
+}
+
+// RegisterRelationship 注册从 fromType 指向 toType 的关系的持久化器，重复注册会 panic
+func RegisterRelationship(fromType, toType string, p RelationshipPersister) {
+	key := [2]string{fromType, toType}
+	if _, exists := relationships[key]; exists {
+		panic(fmt.Sprintf("ingest: 关系 %s -> %s 的持久化器重复注册", fromType, toType))
+	}
+	relationships[key] = p
+}
+
+// persist 按阶段依次执行所有实体持久化器，最后按类型保存实体关系。任意一步失败即返回错误。
+func persist(ctx *Context, result *parser.ParseResult) error {
+	for _, r := range persisters {
+		if err := r.persister.Persist(ctx, result); err != nil {
+			return fmt.Errorf("保存%s失败: %w", entityLabel(r.entity), err)
+		}
+	}
+
+	grouped := make(map[[2]string][]parser.Relationship)
+	var order [][2]string
+	for _, rel := range result.Relationships {
+		key := [2]string{rel.From.Type, rel.To.Type}
+		if _, ok := grouped[key]; !ok {
+			order = append(order, key)
+		}
+		grouped[key] = append(grouped[key], rel)
+	}
+	for _, key := range order {
+		p, ok := relationships[key]
+		if !ok {
+			return fmt.Errorf("不支持的实体关系 %s -> %s", key[0], key[1])
+		}
+		if err := p.Persist(ctx, grouped[key]); err != nil {
+			return fmt.Errorf("保存实体关系 %s -> %s 失败: %w", key[0], key[1], err)
+		}
+	}
+	return nil
+}
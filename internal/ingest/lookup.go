@@ -0,0 +1,39 @@
+package ingest
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+)
+
+// domainIDs 按 FQDN 查询项目中已入库域名的ID
+func domainIDs(ctx *Context, fqdns []string) (map[string]uint, error) {
+	ids := make(map[string]uint, len(fqdns))
+	if len(fqdns) == 0 {
+		return ids, nil
+	}
+	var domains []model.Domain
+	if err := ctx.DB.Select("id", "fqdn").Where("project_id = ? AND fqdn IN ?", ctx.Task.ProjectID, fqdns).Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		ids[d.FQDN] = d.ID
+	}
+	return ids, nil
+}
+
+// assetIDs 按 (IP, 端口, 传输层协议) 查询项目中已入库资产的ID，键为 parser.AssetKey
+func assetIDs(ctx *Context, keys [][]interface{}) (map[string]uint, error) {
+	ids := make(map[string]uint, len(keys))
+	if len(keys) == 0 {
+		return ids, nil
+	}
+	var assets []model.Asset
+	if err := ctx.DB.Select("id", "ip", "port", "transport").
+		Where("project_id = ? AND (ip, port, transport) IN ?", ctx.Task.ProjectID, keys).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range assets {
+		ids[parser.AssetKey(a.IP, a.Port, a.Transport)] = a.ID
+	}
+	return ids, nil
+}
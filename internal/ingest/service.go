@@ -0,0 +1,154 @@
+package ingest
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Service 把解析结果在一个事务中写入数据库，不依赖任务队列，可以直接调用
+type Service struct {
+	DB *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{DB: db}
+}
+
+// Options 是一次入库的可选参数
+type Options struct {
+	// ViaDomainID 是按域名扇出时当前输入对应的域名ID
+	ViaDomainID uint
+}
+
+// Counts 是一类实体本次新增和更新的数量
+type Counts struct {
+	New     int `json:"new"`
+	Updated int `json:"updated"`
+}
+
+// Summary 按实体类型汇总一次入库的结果
+type Summary map[string]Counts
+
+// 汇总文本中实体类型的显示名称和顺序
+var entityLabels = []struct{ entity, label string }{
+	{parser.EntityDomain, "域名"},
+	{parser.EntityAsset, "资产"},
+	{parser.EntityFinding, "漏洞"},
+	{parser.EntityEndpoint, "端点"},
+	{parser.EntityDNSRecord, "DNS记录"},
+	{parser.EntityCertificate, "证书"},
+	{parser.EntityRelationship, "关联"},
+}
+
+func entityLabel(entity string) string {
+	for _, l := range entityLabels {
+		if l.entity == entity {
+			return l.label
+		}
+	}
+	return entity
+}
+
+// String 返回可读的汇总，如 "域名 新增2 更新3，资产 新增1"，没有任何变化时返回空字符串
+func (s Summary) String() string {
+	var entities []string
+	for entity := range s {
+		entities = append(entities, entity)
+	}
+	order := func(entity string) int {
+		for i, l := range entityLabels {
+			if l.entity == entity {
+				return i
+			}
+		}
+		return len(entityLabels)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if order(entities[i]) != order(entities[j]) {
+			return order(entities[i]) < order(entities[j])
+		}
+		return entities[i] < entities[j]
+	})
+
+	var parts []string
+	for _, entity := range entities {
+		c := s[entity]
+		if c.New == 0 && c.Updated == 0 {
+			continue
+		}
+		part := entityLabel(entity)
+		if c.New > 0 {
+			part += fmt.Sprintf(" 新增%d", c.New)
+		}
+		if c.Updated > 0 {
+			part += fmt.Sprintf(" 更新%d", c.Updated)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "，")
+}
+
+// Ingest 在一个事务中保存解析结果，任意实体保存失败时全部回滚。
+// 成功后解析结果中的实体会带上数据库ID。
+func (s *Service) Ingest(task *model.Task, opts Options, result *parser.ParseResult) (Summary, error) {
+	ctx := &Context{
+		Task:        task,
+		ViaDomainID: opts.ViaDomainID,
+		Now:         time.Now().Truncate(time.Microsecond),
+		summary:     make(Summary),
+	}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		ctx.DB = tx
+		return persist(ctx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ctx.summary, nil
+}
+
+// upsert 分批插入或更新实体，通过 RETURNING 取回每一行在数据库中的最终状态
+func upsert[T any](ctx *Context, rows []T, batchSize int, onConflict clause.OnConflict) ([]T, error) {
+	saved := make([]T, 0, len(rows))
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+		batch := append([]T(nil), rows[start:end]...)
+		if err := ctx.DB.Clauses(onConflict, clause.Returning{}).Create(&batch).Error; err != nil {
+			return nil, err
+		}
+		saved = append(saved, batch...)
+	}
+	return saved, nil
+}
+
+// dedupe 按键去重，保留第一次出现的元素。同一条 upsert 语句中不能出现重复的冲突键。
+func dedupe[T any](items []T, key func(*T) string) []T {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for i := range items {
+		k := key(&items[i])
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, items[i])
+	}
+	return out
+}
+
+// record 统计 upsert 返回的行中新插入和更新的数量
+func record[T any](ctx *Context, entity string, saved []T, createdAt func(*T) time.Time) {
+	created := 0
+	for i := range saved {
+		if ctx.isNew(createdAt(&saved[i])) {
+			created++
+		}
+	}
+	ctx.Record(entity, created, len(saved)-created)
+}
@@ -0,0 +1,206 @@
+package ingest
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+)
+
+// newTestTask 在测试库中创建一个项目，返回属于该项目的任务
+func newTestTask(t *testing.T, db *gorm.DB) *model.Task {
+	t.Helper()
+	project := model.Project{Name: "ingest-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &model.Task{ProjectID: project.ID}
+}
+
+// withPersister 在测试期间额外注册一个持久化器
+func withPersister(t *testing.T, entity string, stage int, p Persister) {
+	t.Helper()
+	saved := persisters
+	persisters = append(append([]registration(nil), persisters...), registration{entity: entity, stage: stage, persister: p})
+	t.Cleanup(func() { persisters = saved })
+}
+
+func countRows(t *testing.T, db *gorm.DB, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestIngestRollsBackOnPersisterFailure(t *testing.T) {
+	db := testutil.DB(t)
+	task := newTestTask(t, db)
+	boom := errors.New("boom")
+	withPersister(t, "zz_failing", StageDependent, PersisterFunc(func(ctx *Context, result *parser.ParseResult) error {
+		return boom
+	}))
+
+	result := &parser.ParseResult{
+		Domains: []model.Domain{{FQDN: "a.example.com"}},
+		Assets:  []model.Asset{{IP: "192.0.2.1", Port: 80}},
+	}
+	if _, err := NewService(db).Ingest(task, Options{}, result); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if n := countRows(t, db, &model.Domain{}, "project_id = ?", task.ProjectID); n != 0 {
+		t.Errorf("失败后不应保留域名，got %d", n)
+	}
+	if n := countRows(t, db, &model.Asset{}, "project_id = ?", task.ProjectID); n != 0 {
+		t.Errorf("失败后不应保留资产，got %d", n)
+	}
+}
+
+func TestIngestCountsNewAndUpdated(t *testing.T) {
+	db := testutil.DB(t)
+	task := newTestTask(t, db)
+	service := NewService(db)
+
+	first := &parser.ParseResult{
+		Domains: []model.Domain{{FQDN: "a.example.com"}, {FQDN: "b.example.com"}, {FQDN: "a.example.com"}},
+		Assets:  []model.Asset{{IP: "192.0.2.1", Port: 80, Title: "Home"}},
+	}
+	summary, err := service.Ingest(task, Options{}, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summary[parser.EntityDomain]; got != (Counts{New: 2}) {
+		t.Errorf("首次入库域名 = %+v, want 新增2", got)
+	}
+	if got := summary[parser.EntityAsset]; got != (Counts{New: 1}) {
+		t.Errorf("首次入库资产 = %+v, want 新增1", got)
+	}
+	for _, d := range first.Domains {
+		if d.ID == 0 {
+			t.Errorf("入库后域名 %s 应带有ID", d.FQDN)
+		}
+	}
+
+	// 第二次入库的时间晚于第一次，已存在的行保留原有的创建时间
+	time.Sleep(time.Millisecond)
+	second := &parser.ParseResult{
+		Domains: []model.Domain{{FQDN: "a.example.com"}, {FQDN: "c.example.com"}},
+		Assets:  []model.Asset{{IP: "192.0.2.1", Port: 80}, {IP: "192.0.2.1", Port: 443}},
+	}
+	summary, err = service.Ingest(task, Options{}, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := summary[parser.EntityDomain]; got != (Counts{New: 1, Updated: 1}) {
+		t.Errorf("再次入库域名 = %+v, want 新增1 更新1", got)
+	}
+	if got := summary[parser.EntityAsset]; got != (Counts{New: 1, Updated: 1}) {
+		t.Errorf("再次入库资产 = %+v, want 新增1 更新1", got)
+	}
+	if got := summary.String(); got != "域名 新增1 更新1，资产 新增1 更新1" {
+		t.Errorf("汇总文本 = %q", got)
+	}
+
+	// 本次未识别到的属性不覆盖已有的值
+	var asset model.Asset
+	if err := db.Where("project_id = ? AND ip = ? AND port = ?", task.ProjectID, "192.0.2.1", 80).First(&asset).Error; err != nil {
+		t.Fatal(err)
+	}
+	if asset.Title != "Home" {
+		t.Errorf("Title = %q, 空值不应覆盖已有的标题", asset.Title)
+	}
+}
+
+func TestIngestMapsAssetsByIPAndPort(t *testing.T) {
+	db := testutil.DB(t)
+	task := newTestTask(t, db)
+	service := NewService(db)
+
+	// 同一IP上已有另一个端口的资产
+	if _, err := service.Ingest(task, Options{}, &parser.ParseResult{
+		Domains: []model.Domain{{FQDN: "a.example.com"}, {FQDN: "b.example.com"}},
+		Assets:  []model.Asset{{IP: "192.0.2.1", Port: 22}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var domains []model.Domain
+	db.Where("project_id = ?", task.ProjectID).Order("fqdn").Find(&domains)
+	if len(domains) != 2 {
+		t.Fatalf("domains = %v", domains)
+	}
+	viaDomain, linked := domains[0], domains[1]
+
+	// 按域名扇出时只关联本次发现的资产；解析器给出的关系按 (IP, 端口) 关联
+	result := &parser.ParseResult{
+		Domains: []model.Domain{{FQDN: linked.FQDN}},
+		Assets:  []model.Asset{{IP: "192.0.2.1", Port: 80}, {IP: "192.0.2.1", Port: 443}},
+	}
+	result.Relate(parser.EntityRef{Type: parser.EntityAsset, Key: parser.AssetKey("192.0.2.1", 443, "tcp")}, parser.DomainRef(linked.FQDN))
+	summary, err := service.Ingest(task, Options{ViaDomainID: viaDomain.ID}, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ports := func(domainID uint) []int {
+		var ports []int
+		db.Model(&model.Asset{}).
+			Joins("JOIN asset_domain_mappings m ON m.asset_id = assets.id").
+			Where("m.domain_id = ?", domainID).Order("assets.port").Pluck("assets.port", &ports)
+		return ports
+	}
+	if got := ports(viaDomain.ID); len(got) != 2 || got[0] != 80 || got[1] != 443 {
+		t.Errorf("来源域名关联的端口 = %v, want [80 443]", got)
+	}
+	if got := ports(linked.ID); len(got) != 1 || got[0] != 443 {
+		t.Errorf("关系指定的域名关联的端口 = %v, want [443]", got)
+	}
+	if got := summary[parser.EntityRelationship]; got != (Counts{New: 3}) {
+		t.Errorf("关联 = %+v, want 新增3", got)
+	}
+}
+
+func TestIngestKeepsTCPAndUDPOnSamePort(t *testing.T) {
+	db := testutil.DB(t)
+	task := newTestTask(t, db)
+	service := NewService(db)
+
+	result := &parser.ParseResult{
+		Assets: []model.Asset{
+			{IP: "192.0.2.53", Port: 53, Transport: "tcp", Product: "bind"},
+			{IP: "192.0.2.53", Port: 53, Transport: "UDP", Product: "dnsmasq"},
+			{IP: "192.0.2.53", Port: 80},
+		},
+	}
+	if _, err := service.Ingest(task, Options{}, result); err != nil {
+		t.Fatal(err)
+	}
+	var assets []model.Asset
+	db.Where("project_id = ?", task.ProjectID).Order("port, transport").Find(&assets)
+	if len(assets) != 3 {
+		t.Fatalf("assets = %+v, want 3", assets)
+	}
+	if assets[0].Transport != "tcp" || assets[0].Product != "bind" || assets[1].Transport != "udp" || assets[1].Product != "dnsmasq" {
+		t.Errorf("53 端口的资产 = %+v, %+v", assets[0], assets[1])
+	}
+	if assets[2].Transport != "tcp" {
+		t.Errorf("未识别传输层协议的资产 Transport = %q, want tcp", assets[2].Transport)
+	}
+
+	// 再次只扫描 udp 时不覆盖 tcp 资产的属性
+	if _, err := service.Ingest(task, Options{}, &parser.ParseResult{
+		Assets: []model.Asset{{IP: "192.0.2.53", Port: 53, Transport: "udp", Product: "unbound"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var tcp model.Asset
+	db.Where("project_id = ? AND port = 53 AND transport = ?", task.ProjectID, "tcp").First(&tcp)
+	if tcp.Product != "bind" {
+		t.Errorf("tcp 资产的产品 = %q, want bind", tcp.Product)
+	}
+}
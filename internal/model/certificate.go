@@ -0,0 +1,30 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Certificate 是在某个资产 (IP+端口) 上观测到的TLS证书。
+// 同一证书部署在多个资产上时每个资产各一条，同一项目内 (IP, 端口, 指纹) 唯一。
+type Certificate struct {
+	gorm.Model
+	ProjectID         uint   `gorm:"uniqueIndex:idx_certificate_unique;comment:所属项目ID"`
+	IP                string `gorm:"uniqueIndex:idx_certificate_unique;size:128;comment:提供此证书的IP"`
+	Port              int    `gorm:"uniqueIndex:idx_certificate_unique;comment:提供此证书的端口"`
+	FingerprintSHA256 string `gorm:"uniqueIndex:idx_certificate_unique;index;size:64;comment:证书的SHA256指纹"`
+	AssetID           uint   `gorm:"index;comment:关联的资产ID"`
+
+	Host         string     `gorm:"size:255;comment:探测时使用的主机名 (SNI)"`
+	SubjectCN    string     `gorm:"size:255;comment:主题通用名"`
+	SubjectDN    string     `gorm:"type:text;comment:完整的主题DN"`
+	SANs         JSONBArray `gorm:"type:jsonb;comment:主题备用名称"`
+	IssuerCN     string     `gorm:"size:255;comment:颁发者通用名"`
+	IssuerDN     string     `gorm:"type:text;comment:完整的颁发者DN"`
+	Serial       string     `gorm:"size:255;comment:序列号"`
+	NotBefore    time.Time  `gorm:"comment:生效时间"`
+	NotAfter     time.Time  `gorm:"index;comment:过期时间"`
+	IsSelfSigned bool       `gorm:"comment:是否为自签名证书"`
+	IsMismatched bool       `gorm:"index;comment:证书是否与探测的主机名不匹配"`
+	LastSeenAt   time.Time  `gorm:"index;comment:最后一次观测到此证书的时间"`
+}
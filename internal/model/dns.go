@@ -0,0 +1,39 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// DNS 记录类型
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+	DNSRecordMX    = "MX"
+	DNSRecordNS    = "NS"
+	DNSRecordTXT   = "TXT"
+)
+
+// DNSRecord 是域名的一条解析记录。同一项目内 (域名, 类型, 值) 唯一，
+// 首次发现时间即 CreatedAt，之后每次解析到只刷新 LastSeenAt。
+type DNSRecord struct {
+	gorm.Model
+	ProjectID uint   `gorm:"uniqueIndex:idx_dns_record_unique;comment:所属项目ID"`
+	FQDN      string `gorm:"uniqueIndex:idx_dns_record_unique;size:255;comment:完整域名"`
+	Type      string `gorm:"uniqueIndex:idx_dns_record_unique;size:10;comment:记录类型 (A, AAAA, CNAME, MX, NS, TXT)"`
+	Value     string `gorm:"uniqueIndex:idx_dns_record_unique;size:2048;comment:记录值"`
+	DomainID  uint   `gorm:"index;comment:关联的域名ID"`
+	// IsDangling 标记指向已不存在目标的 CNAME，是子域名接管的候选
+	IsDangling      bool      `gorm:"index;comment:是否为悬空的CNAME"`
+	TakeoverService string    `gorm:"size:100;comment:CNAME目标匹配到的易被接管的服务 (e.g., github-pages, heroku)"`
+	LastSeenAt      time.Time `gorm:"index;comment:最后一次解析到此记录的时间"`
+}
+
+// DNSWildcard 缓存根域名的泛解析检测结果，与项目无关，过期后重新检测
+type DNSWildcard struct {
+	RootDomain string     `gorm:"primaryKey;size:255"`
+	IsWildcard bool       `gorm:"comment:随机子域名是否能解析"`
+	IPs        JSONBArray `gorm:"type:jsonb;comment:随机子域名解析到的IP"`
+	CheckedAt  time.Time  `gorm:"comment:检测时间"`
+}
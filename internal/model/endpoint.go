@@ -0,0 +1,28 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Endpoint 是爬虫或历史URL工具发现的一个Web端点。
+// URL 经过规范化，同一项目内按 URLKey (方法 + 不含参数值的URL) 去重，
+// 只是参数值不同的URL视为同一个端点。
+type Endpoint struct {
+	gorm.Model
+	ProjectID uint   `gorm:"uniqueIndex:idx_endpoint_unique_in_project;comment:所属项目ID"`
+	URLKey    string `gorm:"uniqueIndex:idx_endpoint_unique_in_project;size:64;comment:去重键 (方法和不含参数值的规范化URL的哈希)"`
+	DomainID  uint   `gorm:"index;comment:关联的域名ID，主机不是项目内已知域名时为0"`
+
+	URL           string     `gorm:"type:text;comment:规范化后的URL"`
+	Method        string     `gorm:"size:10;comment:HTTP方法"`
+	Host          string     `gorm:"index;size:255;comment:主机名或IP"`
+	Path          string     `gorm:"type:text;comment:URL路径"`
+	Extension     string     `gorm:"index;size:20;comment:路径的文件扩展名 (不含点)"`
+	Parameters    JSONBArray `gorm:"type:jsonb;comment:查询参数名列表"`
+	StatusCode    int        `gorm:"index;comment:响应状态码，未请求过时为0"`
+	ContentType   string     `gorm:"size:255;comment:响应的Content-Type"`
+	ContentLength int64      `gorm:"comment:响应长度"`
+	Source        string     `gorm:"size:100;comment:发现来源 (e.g., katana, gau)"`
+	LastSeenAt    time.Time  `gorm:"index;comment:最后一次发现此端点的时间"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 漏洞发现的分诊状态
+const (
+	FindingStatusOpen          = "open"           // 新发现，尚未处理
+	FindingStatusTriaged       = "triaged"        // 已确认，等待修复
+	FindingStatusFalsePositive = "false-positive" // 误报，再次发现时保持不变
+	FindingStatusFixed         = "fixed"          // 已修复，再次发现时重新打开
+)
+
+// FindingStatuses 是所有合法的分诊状态
+var FindingStatuses = []string{FindingStatusOpen, FindingStatusTriaged, FindingStatusFalsePositive, FindingStatusFixed}
+
+// Finding 是漏洞扫描 (如 nuclei) 的一条发现。
+// 同一项目内按指纹去重：多次运行命中同一模板、同一位置只保留一条，刷新证据和最后发现时间。
+type Finding struct {
+	gorm.Model
+	ProjectID   uint   `gorm:"uniqueIndex:idx_finding_fingerprint;comment:所属项目ID"`
+	Fingerprint string `gorm:"uniqueIndex:idx_finding_fingerprint;size:64;comment:去重指纹 (模板ID、匹配器、匹配位置和提取结果的哈希)"`
+	AssetID     uint   `gorm:"index;comment:关联的资产ID，无法关联时为0"`
+	DomainID    uint   `gorm:"index;comment:关联的域名ID，无法关联时为0"`
+
+	TemplateID       string     `gorm:"index;size:255;comment:模板ID"`
+	Name             string     `gorm:"size:512;comment:模板名称"`
+	Severity         string     `gorm:"index;size:20;comment:严重程度 (info, low, medium, high, critical, unknown)"`
+	Type             string     `gorm:"size:50;comment:模板协议类型 (http, dns, network, ssl 等)"`
+	Host             string     `gorm:"index;size:255;comment:目标主机名或IP"`
+	IP               string     `gorm:"size:128;comment:目标IP"`
+	Port             int        `gorm:"comment:目标端口"`
+	MatchedAt        string     `gorm:"type:text;comment:命中的URL或地址"`
+	MatcherName      string     `gorm:"size:255;comment:命中的匹配器名称"`
+	ExtractedResults JSONBArray `gorm:"type:jsonb;comment:提取到的数据"`
+	Request          string     `gorm:"type:text;comment:请求证据"`
+	Response         string     `gorm:"type:text;comment:响应证据"`
+
+	Status     string    `gorm:"index;size:20;comment:分诊状态 (open, triaged, false-positive, fixed)"`
+	TaskID     uint      `gorm:"index;comment:最近一次发现此漏洞的任务ID"`
+	LastSeenAt time.Time `gorm:"index;comment:最后一次发现此漏洞的时间"`
+}
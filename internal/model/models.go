@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"time"
 )
@@ -19,6 +20,10 @@ func (j JSONBArray) Value() (driver.Value, error) {
 }
 
 func (j *JSONBArray) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
 	bytes, ok := value.([]byte)
 	if !ok {
 		return errors.New("type assertion to []byte failed")
@@ -30,11 +35,18 @@ func (j *JSONBArray) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// 项目状态
+const (
+	ProjectStatusActive   = "active"   // 正常，可以发起扫描
+	ProjectStatusArchived = "archived" // 已归档，拒绝新的扫描
+	ProjectStatusDeleting = "deleting" // 正在后台级联删除
+)
+
 type Project struct {
 	gorm.Model
 	Name        string `gorm:"unique;size:255;not null;comment:项目名称,必须唯一"`
 	Description string `gorm:"type:text;comment:项目描述"`
-	Status      string `gorm:"size:50;default:'active';index;comment:项目状态 (active, archived)"`
+	Status      string `gorm:"size:50;default:'active';index;comment:项目状态 (active, archived, deleting)"`
 }
 
 type ProjectTarget struct {
@@ -47,18 +59,30 @@ type ProjectTarget struct {
 	IsActive    bool   `gorm:"default:true;index;comment:是否启用对此目标的周期性扫描"`
 }
 
+// 资产的传输层协议
+const (
+	TransportTCP = "tcp"
+	TransportUDP = "udp"
+)
+
 type Asset struct {
 	gorm.Model
-	// 复合唯一索引：同一个项目下的 IP + Port 是唯一的
-	ProjectID    uint       `gorm:"uniqueIndex:idx_asset_unique_in_project;comment:所属项目ID"`
-	IP           string     `gorm:"uniqueIndex:idx_asset_unique_in_project;size:128;comment:IPv4或IPv6地址"`
-	Port         int        `gorm:"uniqueIndex:idx_asset_unique_in_project;comment:端口号"`
+	// 复合唯一索引：同一个项目下的 IP + Port + Transport 是唯一的，同一端口的 tcp 和 udp 是两个资产
+	ProjectID    uint       `gorm:"uniqueIndex:idx_asset_unique_transport;comment:所属项目ID"`
+	IP           string     `gorm:"uniqueIndex:idx_asset_unique_transport;size:128;comment:IPv4或IPv6地址"`
+	Port         int        `gorm:"uniqueIndex:idx_asset_unique_transport;comment:端口号"`
 	Title        string     `gorm:"type:text;comment:网页标题"`
 	WebServer    string     `gorm:"size:255;comment:Web服务器软件 (e.g., nginx, Apache)"`
 	Technologies JSONBArray `gorm:"type:jsonb;comment:使用的技术栈"`
 	Protocol     string     `gorm:"size:50;comment:应用层协议 (e.g., http, ssh)"`
+	Transport    string     `gorm:"uniqueIndex:idx_asset_unique_transport;size:10;default:'tcp';comment:传输层协议 (tcp, udp)"`
+	Product      string     `gorm:"size:255;comment:服务识别出的产品名 (e.g., OpenSSH)"`
+	Version      string     `gorm:"size:255;comment:服务识别出的产品版本"`
+	HostState    string     `gorm:"size:20;comment:端口扫描时主机的状态 (up, down)"`
 	Source       string     `gorm:"size:100;comment:发现来源 (e.g., nmap, masscan)"`
+	CDNProvider  string     `gorm:"size:100;index;comment:资产所在的CDN/WAF服务商，为空表示未识别到CDN"`
 	LastSeenAt   time.Time  `gorm:"index;comment:最后一次扫描到此资产存活的时间"`
+	IsGone       bool       `gorm:"index;comment:最近一次全量运行中未再观测到此资产"`
 }
 
 type Domain struct {
@@ -67,9 +91,20 @@ type Domain struct {
 	ProjectID uint   `gorm:"uniqueIndex:idx_domain_unique_in_project;comment:所属项目ID"`
 	FQDN      string `gorm:"uniqueIndex:idx_domain_unique_in_project;size:255;comment:完整域名"`
 
-	RootDomain string    `gorm:"index;size:255;comment:根域名, 用于聚合查询"`
-	Source     string    `gorm:"size:100;comment:发现来源 (e.g., subfinder)"`
-	LastSeenAt time.Time `gorm:"index;comment:最后一次解析到此域名的时间"`
+	RootDomain  string    `gorm:"index;size:255;comment:根域名, 用于聚合查询"`
+	Source      string    `gorm:"size:100;comment:发现来源 (e.g., subfinder)"`
+	CDNProvider string    `gorm:"size:100;index;comment:域名接入的CDN/WAF服务商 (按 CNAME 或解析IP识别)"`
+	LastSeenAt  time.Time `gorm:"index;comment:最后一次解析到此域名的时间"`
+	IsGone      bool      `gorm:"index;comment:最近一次全量运行中未再观测到此域名"`
+}
+
+// Address 返回资产的可读地址 IP:端口，udp 资产附加 /udp 以便与同一端口的 tcp 资产区分
+func (a *Asset) Address() string {
+	address := fmt.Sprintf("%s:%d", a.IP, a.Port)
+	if a.Transport == TransportUDP {
+		address += "/" + TransportUDP
+	}
+	return address
 }
 
 type AssetDomainMapping struct {
@@ -77,13 +112,17 @@ type AssetDomainMapping struct {
 	DomainID uint `gorm:"primaryKey"`
 }
 
+// IPMetadata 是IP的归属信息，由离线 GeoIP/ASN 数据库和本地IP段列表补充，超过有效期后重新查询
 type IPMetadata struct {
-	IP           string `gorm:"primaryKey;size:128"`
-	UpdatedAt    time.Time
-	ASN          string `gorm:"size:100;comment:自治系统编号"`
-	Organization string `gorm:"size:255;comment:所属组织"`
-	CountryCode  string `gorm:"size:10;comment:国家代码"`
-	Source       string `gorm:"size:100;comment:数据来源 (e.g., geoip)"`
+	IP            string `gorm:"primaryKey;size:128"`
+	UpdatedAt     time.Time
+	ASN           string `gorm:"size:100;index;comment:自治系统编号"`
+	Organization  string `gorm:"size:255;comment:所属组织"`
+	CountryCode   string `gorm:"size:10;index;comment:国家代码"`
+	City          string `gorm:"size:255;comment:城市"`
+	CloudProvider string `gorm:"size:100;index;comment:命中本地IP段列表的CDN或云服务商 (e.g., cloudflare, aws)"`
+	IsCDN         bool   `gorm:"index;comment:是否属于CDN节点的IP段"`
+	Source        string `gorm:"size:100;comment:数据来源 (e.g., geoip)"`
 }
 
 type Task struct {
@@ -100,6 +139,53 @@ type Task struct {
 	FinishedAt    time.Time `gorm:"comment:任务执行完毕时间"`
 
 	ParentTaskID    uint   `gorm:"index;comment:父任务ID，用于工作流"`
+	ProjectTargetID uint   `gorm:"index;comment:任务输入来源的项目目标ID，用于按目标归因结果"`
+	ScanScheduleID  uint   `gorm:"index;comment:触发此工作流的定时计划ID，手动发起时为0"`
+	IsFullRun       bool   `gorm:"comment:是否为覆盖项目所有启用目标的全量运行，用于判定实体是否消失"`
 	WorkflowStep    string `gorm:"size:100;comment:在工作流中所处的步骤名"`
 	PendingSubtasks int    `gorm:"default:0;comment:扇出任务的待处理子任务数量"`
 }
+
+// ScopeRule 是项目的范围规则，在项目目标 (ProjectTarget) 隐含的包含范围之上做补充
+type ScopeRule struct {
+	gorm.Model
+	ProjectID   uint   `gorm:"index;comment:所属项目ID"`
+	Action      string `gorm:"size:20;not null;comment:规则动作 (include, exclude)"`
+	Type        string `gorm:"size:20;not null;comment:规则类型 (domain, ip, cidr, regex, port)"`
+	Value       string `gorm:"size:1024;not null;comment:规则值 (e.g., *.example.com, 10.0.0.0/8, ^dev-, 8000-9000)"`
+	Description string `gorm:"type:text;comment:对此规则的描述"`
+}
+
+// OutOfScopeItem 记录工作流中因超出项目范围而被拦截的条目，便于审计而不是静默丢弃
+type OutOfScopeItem struct {
+	gorm.Model
+	ProjectID    uint   `gorm:"index;comment:所属项目ID"`
+	TaskID       uint   `gorm:"index;comment:拦截发生时的任务ID"`
+	ParentTaskID uint   `gorm:"index;comment:拦截发生时任务的父任务ID"`
+	Kind         string `gorm:"size:50;comment:条目类型 (domain, asset, input)"`
+	Value        string `gorm:"size:1024;comment:被拦截的值 (域名、IP:端口等)"`
+	Reason       string `gorm:"type:text;comment:被判定为范围外的原因"`
+}
+
+// 观测对象类型
+const (
+	ObservationKindDomain = "domain"
+	ObservationKindAsset  = "asset"
+)
+
+// Observation 记录一次工作流运行对某个域名或资产的观测及当时的属性值。
+// 同一工作流内对同一实体只保留一条；跨运行只追加不修改，构成实体的历史时间线，
+// 也用于在两次运行之间做变化检测。
+type Observation struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	ProjectID    uint       `gorm:"index;comment:所属项目ID"`
+	WorkflowID   uint       `gorm:"uniqueIndex:idx_observation_unique;comment:观测到此实体的工作流 (顶级任务) ID"`
+	Kind         string     `gorm:"uniqueIndex:idx_observation_unique;index:idx_observation_entity;size:20;comment:实体类型 (domain, asset)"`
+	EntityID     uint       `gorm:"uniqueIndex:idx_observation_unique;index:idx_observation_entity;comment:域名或资产ID"`
+	Value        string     `gorm:"size:1024;comment:实体的可读标识 (FQDN 或 IP:端口)"`
+	Title        string     `gorm:"type:text;comment:观测时的网页标题"`
+	WebServer    string     `gorm:"size:255;comment:观测时的Web服务器软件"`
+	Technologies JSONBArray `gorm:"type:jsonb;comment:观测时的技术栈"`
+	ObservedAt   time.Time  `gorm:"index;comment:观测时间"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// WebhookSubscription 是项目级的 Webhook 订阅，事件发生时向 URL 推送带 HMAC 签名的 JSON
+type WebhookSubscription struct {
+	gorm.Model
+	ProjectID uint   `gorm:"index;comment:所属项目ID"`
+	Name      string `gorm:"size:255;not null;comment:订阅名称"`
+	URL       string `gorm:"size:2048;not null;comment:接收事件的URL"`
+	Secret    string `gorm:"size:255;comment:用于 HMAC-SHA256 签名的密钥"`
+	// Events 为订阅的事件类型列表，为空表示订阅所有事件
+	Events    JSONBArray `gorm:"type:jsonb;comment:订阅的事件类型"`
+	IsEnabled bool       `gorm:"index;comment:是否启用此订阅"`
+}
+
+// 投递状态
+const (
+	DeliveryStatusPending  = "pending"  // 已入队，尚未尝试
+	DeliveryStatusRetrying = "retrying" // 投递失败，等待重试
+	DeliveryStatusSuccess  = "success"  // 接收方返回 2xx
+	DeliveryStatusFailed   = "failed"   // 重试耗尽或遇到不可重试的错误
+)
+
+// WebhookDelivery 记录一次事件投递及其最近一次尝试的结果
+type WebhookDelivery struct {
+	gorm.Model
+	ProjectID      uint       `gorm:"index;comment:所属项目ID"`
+	SubscriptionID uint       `gorm:"index;comment:Webhook订阅ID"`
+	EventID        string     `gorm:"size:64;index;comment:事件ID，同一事件投递给多个订阅时相同"`
+	Event          string     `gorm:"size:100;index;comment:事件类型"`
+	Payload        JSONB      `gorm:"type:jsonb;comment:投递的JSON请求体"`
+	Status         string     `gorm:"size:20;index;comment:投递状态 (pending, retrying, success, failed)"`
+	Attempts       int        `gorm:"comment:已尝试次数"`
+	ResponseCode   int        `gorm:"comment:最近一次尝试的HTTP状态码"`
+	ResponseBody   string     `gorm:"type:text;comment:最近一次尝试的响应体 (截断)"`
+	Error          string     `gorm:"type:text;comment:最近一次尝试的错误信息"`
+	DeliveredAt    *time.Time `gorm:"comment:投递成功的时间"`
+}
+
+// 聊天渠道类型
+const (
+	ChatProviderSlack    = "slack"
+	ChatProviderDiscord  = "discord"
+	ChatProviderTelegram = "telegram"
+)
+
+// ChatChannel 是项目级的聊天渠道，按各平台 incoming-webhook 的格式推送工作流摘要。
+// 多个工作流的结果会合并为一条摘要消息，两次发送之间至少间隔 MinInterval 秒。
+type ChatChannel struct {
+	gorm.Model
+	ProjectID uint   `gorm:"index;comment:所属项目ID"`
+	Name      string `gorm:"size:255;not null;comment:渠道名称"`
+	Provider  string `gorm:"size:20;not null;comment:渠道类型 (slack, discord, telegram)"`
+	// URL 对 Slack/Discord 为 incoming webhook 地址，对 Telegram 为 https://api.telegram.org/bot<token>
+	URL    string `gorm:"size:2048;not null;comment:推送地址"`
+	ChatID string `gorm:"size:255;comment:Telegram 的 chat_id"`
+	// Template 为 Go text/template 格式的消息模板，为空时使用渠道类型的默认模板
+	Template    string    `gorm:"type:text;comment:消息模板"`
+	MinInterval int       `gorm:"comment:两次发送之间的最小间隔 (秒)"`
+	MaxItems    int       `gorm:"comment:每类条目在一条消息中最多列出的数量"`
+	IsEnabled   bool      `gorm:"index;comment:是否启用此渠道"`
+	LastSentAt  time.Time `gorm:"comment:上一次发送摘要的时间"`
+}
+
+// ChatDigestEntry 是等待合并发送到聊天渠道的一次工作流结果
+type ChatDigestEntry struct {
+	gorm.Model
+	ProjectID  uint       `gorm:"index;comment:所属项目ID"`
+	ChannelID  uint       `gorm:"uniqueIndex:idx_digest_channel_workflow;comment:聊天渠道ID"`
+	WorkflowID uint       `gorm:"uniqueIndex:idx_digest_channel_workflow;comment:工作流 (顶级任务) ID，同一渠道中每个工作流只保留一条"`
+	Data       JSONB      `gorm:"type:jsonb;comment:该工作流的变化摘要"`
+	SentAt     *time.Time `gorm:"index;comment:随摘要发送的时间，为空表示尚未发送"`
+	ClaimedAt  *time.Time `gorm:"comment:被发送任务认领的时间，发送期间其他任务不会重复发送"`
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 // WorkflowStep 定义了工作流中的一个具体步骤
@@ -15,8 +16,51 @@ type WorkflowStep struct {
 	InputFrom        string `json:"input_from"`         // "initial" 或上一个步骤的Name, 表示输入来源
 	OutputParserType string `json:"output_parser_type"` // "subfinder_json", 指示用哪个解析器
 	ExecutionMode    string `json:"execution_mode,omitempty"`
+	// AcceptTypes 限定起始步骤接受的目标类型 (domain, ip, cidr)，为空表示全部接受
+	AcceptTypes []string `json:"accept_types,omitempty"`
+	// CIDRMode 决定起始步骤如何处理CIDR目标: "expand" (默认) 展开为单个IP, "keep" 原样传给工具 (如 masscan)
+	CIDRMode string `json:"cidr_mode,omitempty"`
+	// FanOutKey 决定并行步骤从上一步输出中取哪个字段作为每个子任务的输入:
+	// "fqdn" (默认) 按域名扇出, "host_port" 按资产的 IP:端口 扇出 (如 masscan/naabu 之后接 httpx)
+	FanOutKey string `json:"fan_out_key,omitempty"`
+	// SkipCDN 为 true 时，并行步骤跳过接入CDN/WAF的主机 (用于端口扫描步骤，扫描CDN边缘节点没有意义)；
+	// HTTP 探测等步骤不设置此项，CDN主机仍会被识别指纹
+	SkipCDN bool `json:"skip_cdn,omitempty"`
+	// ParserConfig 是 OutputParserType 为 "generic" 时通用解析器的配置
+	ParserConfig *GenericParserConfig `json:"parser_config,omitempty"`
 }
 
+// GenericParserConfig 描述如何把一个工具的输出映射为域名、资产和端点，无需为新工具编写解析器代码
+type GenericParserConfig struct {
+	// Format 为输出格式: json_array, json_lines, csv, regex
+	Format string `json:"format"`
+	// Root 为JSON格式下记录所在的路径 (e.g., "data.results")，为空表示文档 (或每一行) 本身就是记录
+	Root string `json:"root,omitempty"`
+	// Delimiter 为 csv 格式的分隔符，默认为逗号
+	Delimiter string `json:"delimiter,omitempty"`
+	// HasHeader 表示 csv 的第一行是表头，此时映射可以使用列名
+	HasHeader bool `json:"has_header,omitempty"`
+	// Pattern 为 regex 格式下逐行匹配的正则表达式，映射使用命名分组或分组序号
+	Pattern string `json:"pattern,omitempty"`
+	// Source 为写入实体的发现来源，默认为步骤名
+	Source   string         `json:"source,omitempty"`
+	Mappings []FieldMapping `json:"mappings"`
+}
+
+// FieldMapping 把输出中的一个字段映射到实体属性
+type FieldMapping struct {
+	// From 为JSON路径 (e.g., "host", "$.info.ip", "ports[0].port")、CSV列名或序号 (从0开始)、正则分组名或序号
+	From string `json:"from"`
+	// To 为目标属性，e.g., "domain.fqdn", "asset.ip", "asset.port", "endpoint.url"
+	To string `json:"to"`
+}
+
+// 并行步骤的扇出键
+const (
+	FanOutKeyFQDN     = "fqdn"
+	FanOutKeyHostPort = "host_port"
+)
+
 // WorkflowSteps 是 WorkflowStep 的切片，我们需要为它实现 GORM 的 Scanner/Valuer 接口
 type WorkflowSteps []WorkflowStep
 
@@ -49,3 +93,48 @@ type ScanProfile struct {
 	WorkflowSteps WorkflowSteps `gorm:"type:jsonb;not null"` // 使用JSONB存储工作流步骤数组
 	IsActive      bool          `gorm:"default:true"`
 }
+
+// JSONBUintArray 是一个可以处理 JSON 整数数组的自定义类型，用于存储ID列表
+type JSONBUintArray []uint
+
+func (j JSONBUintArray) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(j)
+}
+
+func (j *JSONBUintArray) Scan(value interface{}) error {
+	if value == nil {
+		*j = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	if len(bytes) == 0 {
+		*j = make(JSONBUintArray, 0)
+		return nil
+	}
+	return json.Unmarshal(bytes, j)
+}
+
+// ScanSchedule 是一个周期性执行的扫描计划
+type ScanSchedule struct {
+	gorm.Model
+	ProjectID     uint   `gorm:"index;comment:所属项目ID"`
+	ScanProfileID uint   `gorm:"index;comment:使用的扫描模板ID"`
+	Name          string `gorm:"size:255;not null;comment:计划名称"`
+	Description   string `gorm:"type:text;comment:计划描述"`
+	// CronExpr 为标准5段cron表达式或 @daily 等描述符，可用 CRON_TZ=Asia/Shanghai 前缀指定时区
+	CronExpr         string         `gorm:"size:255;not null;comment:cron表达式"`
+	UseActiveTargets bool           `gorm:"comment:是否使用项目中所有启用的目标"`
+	TargetIDs        JSONBUintArray `gorm:"type:jsonb;comment:指定的项目目标ID列表"`
+	IsEnabled        bool           `gorm:"index;comment:是否启用此计划"`
+	NextRunAt        time.Time      `gorm:"index;comment:下一次计划执行时间"`
+	LastRunAt        time.Time      `gorm:"comment:上一次触发时间"`
+	LastTaskID       uint           `gorm:"comment:上一次触发创建的工作流父任务ID"`
+	LastRunStatus    string         `gorm:"size:50;comment:上一次触发的结果 (launched, skipped, failed)"`
+	LastRunMessage   string         `gorm:"type:text;comment:上一次触发的说明或错误信息"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 可以打标签和添加备注的实体类型
+const (
+	TagEntityDomain  = "domain"
+	TagEntityAsset   = "asset"
+	TagEntityFinding = "finding"
+)
+
+// TagEntities 是所有可以打标签的实体类型
+var TagEntities = []string{TagEntityDomain, TagEntityAsset, TagEntityFinding}
+
+// 标签关联的来源
+const (
+	TagSourceManual = "manual" // 分析人员手动或按搜索语句批量添加
+	TagSourceRule   = "rule"   // 入库后由自动打标签规则添加
+)
+
+// Tag 是项目内的标签 (e.g., interesting, login-panel)，名称在项目内唯一
+type Tag struct {
+	gorm.Model
+	ProjectID   uint   `gorm:"uniqueIndex:idx_tag_name_in_project;comment:所属项目ID"`
+	Name        string `gorm:"uniqueIndex:idx_tag_name_in_project;size:100;not null;comment:标签名称"`
+	Color       string `gorm:"size:20;comment:前端展示用的颜色 (e.g., #ff4d4f)"`
+	Description string `gorm:"type:text;comment:对此标签的描述"`
+}
+
+// Tagging 是标签与域名、资产或漏洞发现之间的关联，同一实体的同一标签只保留一条
+type Tagging struct {
+	ID         uint      `gorm:"primaryKey"`
+	ProjectID  uint      `gorm:"index;comment:所属项目ID"`
+	TagID      uint      `gorm:"uniqueIndex:idx_tagging_unique;comment:标签ID"`
+	EntityType string    `gorm:"uniqueIndex:idx_tagging_unique;index:idx_tagging_entity;size:20;comment:实体类型 (domain, asset, finding)"`
+	EntityID   uint      `gorm:"uniqueIndex:idx_tagging_unique;index:idx_tagging_entity;comment:实体ID"`
+	Source     string    `gorm:"size:20;comment:关联来源 (manual, rule)"`
+	RuleID     uint      `gorm:"index;comment:添加此标签的自动打标签规则ID，手动添加时为0"`
+	CreatedAt  time.Time `gorm:"comment:打标签的时间"`
+}
+
+// Note 是分析人员对域名、资产或漏洞发现的自由文本备注
+type Note struct {
+	gorm.Model
+	ProjectID  uint   `gorm:"index;comment:所属项目ID"`
+	EntityType string `gorm:"index:idx_note_entity;size:20;comment:实体类型 (domain, asset, finding)"`
+	EntityID   uint   `gorm:"index:idx_note_entity;comment:实体ID"`
+	Author     string `gorm:"size:100;comment:备注作者"`
+	Content    string `gorm:"type:text;not null;comment:备注内容"`
+}
+
+// TagRule 是自动打标签规则：每次入库后对本次保存的实体求值，字段匹配正则时打上对应标签
+type TagRule struct {
+	gorm.Model
+	ProjectID   uint   `gorm:"index;comment:所属项目ID"`
+	TagID       uint   `gorm:"index;comment:命中时添加的标签ID"`
+	EntityType  string `gorm:"size:20;not null;comment:规则作用的实体类型 (domain, asset, finding)"`
+	Field       string `gorm:"size:50;not null;comment:匹配的字段 (e.g., title, domain, template)"`
+	Pattern     string `gorm:"size:1024;not null;comment:正则表达式，忽略大小写 (e.g., admin)"`
+	IsEnabled   bool   `gorm:"index;comment:是否启用此规则"`
+	Description string `gorm:"type:text;comment:对此规则的描述"`
+}
@@ -0,0 +1,291 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/src-hunter/internal/changes"
+	"github.com/src-hunter/internal/model"
+)
+
+// 渠道的默认限制
+const (
+	DefaultChatMinInterval = 300 // 秒
+	DefaultChatMaxItems    = 20
+)
+
+// ChatProviders 是支持的聊天渠道类型
+var ChatProviders = []string{model.ChatProviderSlack, model.ChatProviderDiscord, model.ChatProviderTelegram}
+
+// 各平台单条消息的长度上限 (字符)，超出部分会被截断
+var chatMessageLimits = map[string]int{
+	model.ChatProviderSlack:    3900,
+	model.ChatProviderDiscord:  2000,
+	model.ChatProviderTelegram: 4096,
+}
+
+// DigestAsset 是摘要中列出的一个新增或变化的资产
+type DigestAsset struct {
+	Value     string `json:"value"`
+	Kind      string `json:"kind"` // new, changed
+	Title     string `json:"title,omitempty"`
+	WebServer string `json:"webServer,omitempty"`
+	Changes   string `json:"changes,omitempty"` // 变化的属性，如 "title, web_server"
+}
+
+// DigestWorkflow 是一次工作流运行在摘要中的数据，保存在 ChatDigestEntry.Data 中
+type DigestWorkflow struct {
+	WorkflowID uint            `json:"workflowId"`
+	Summary    changes.Summary `json:"summary"`
+	NewDomains []string        `json:"newDomains"`
+	Assets     []DigestAsset   `json:"assets"`
+}
+
+// Digest 是渲染消息模板时的数据，合并了一个或多个工作流的结果
+type Digest struct {
+	ProjectID   uint
+	ProjectName string
+	Workflows   []uint
+	Summary     changes.Summary
+	NewDomains  []string
+	MoreDomains int // 超出 MaxItems 未列出的域名数量
+	Assets      []DigestAsset
+	MoreAssets  int // 超出 MaxItems 未列出的资产数量
+}
+
+// NewDigestWorkflow 从一次运行的变化集合中提取摘要数据，最多保留 limit 条域名和资产
+func NewDigestWorkflow(set *changes.ChangeSet, limit int) DigestWorkflow {
+	wf := DigestWorkflow{WorkflowID: set.ToWorkflowID, Summary: set.Summary}
+	for _, d := range set.NewDomains {
+		if len(wf.NewDomains) >= limit {
+			break
+		}
+		wf.NewDomains = append(wf.NewDomains, d.Value)
+	}
+	for _, a := range set.NewAssets {
+		if len(wf.Assets) >= limit {
+			break
+		}
+		wf.Assets = append(wf.Assets, DigestAsset{Value: a.Value, Kind: "new"})
+	}
+	for _, a := range set.ChangedAssets {
+		if len(wf.Assets) >= limit {
+			break
+		}
+		asset := DigestAsset{Value: a.Value, Kind: "changed"}
+		var fields []string
+		for _, f := range a.Changes {
+			fields = append(fields, f.Field)
+			switch f.Field {
+			case "title":
+				asset.Title = f.New
+			case "web_server":
+				asset.WebServer = f.New
+			}
+		}
+		asset.Changes = strings.Join(fields, ", ")
+		wf.Assets = append(wf.Assets, asset)
+	}
+	return wf
+}
+
+// MergeDigest 把多个工作流的摘要合并为一条消息的数据，每类条目最多列出 maxItems 个。
+// 多个工作流中重复出现的域名和资产只列出并计数一次；各工作流保存时已省略的条目无法去重，按原数量计入"另有"。
+func MergeDigest(project *model.Project, workflows []DigestWorkflow, maxItems int) Digest {
+	digest := Digest{ProjectID: project.ID, ProjectName: project.Name}
+	seenDomains := map[string]bool{}
+	seenAssets := map[string]bool{}
+	omittedDomains, omittedAssets := 0, 0
+	for _, wf := range workflows {
+		digest.Workflows = append(digest.Workflows, wf.WorkflowID)
+		digest.Summary.NewDomains += wf.Summary.NewDomains
+		digest.Summary.RemovedDomains += wf.Summary.RemovedDomains
+		digest.Summary.NewAssets += wf.Summary.NewAssets
+		digest.Summary.RemovedAssets += wf.Summary.RemovedAssets
+		digest.Summary.ChangedAssets += wf.Summary.ChangedAssets
+		omittedDomains += max(wf.Summary.NewDomains-len(wf.NewDomains), 0)
+		omittedAssets += max(wf.Summary.NewAssets+wf.Summary.ChangedAssets-len(wf.Assets), 0)
+		for _, d := range wf.NewDomains {
+			if !seenDomains[d] {
+				seenDomains[d] = true
+				digest.NewDomains = append(digest.NewDomains, d)
+			}
+		}
+		for _, a := range wf.Assets {
+			if !seenAssets[a.Value] {
+				seenAssets[a.Value] = true
+				digest.Assets = append(digest.Assets, a)
+			}
+		}
+	}
+	if len(digest.NewDomains) > maxItems {
+		omittedDomains += len(digest.NewDomains) - maxItems
+		digest.NewDomains = digest.NewDomains[:maxItems]
+	}
+	if len(digest.Assets) > maxItems {
+		omittedAssets += len(digest.Assets) - maxItems
+		digest.Assets = digest.Assets[:maxItems]
+	}
+	digest.MoreDomains = omittedDomains
+	digest.MoreAssets = omittedAssets
+	return digest
+}
+
+// defaultChatTemplate 是所有渠道共用的默认模板，bold/code 按渠道输出对应的标记
+const defaultChatTemplate = `{{bold (printf "[src-hunter] 项目 %s 扫描摘要" .ProjectName)}}
+工作流: {{range $i, $id := .Workflows}}{{if $i}}, {{end}}#{{$id}}{{end}}
+新增域名 {{.Summary.NewDomains}} / 新增资产 {{.Summary.NewAssets}} / 变化资产 {{.Summary.ChangedAssets}} / 消失资产 {{.Summary.RemovedAssets}}
+{{- if .NewDomains}}
+
+{{bold "新增子域名"}}
+{{- range .NewDomains}}
+- {{code .}}
+{{- end}}
+{{- if .MoreDomains}}
+... 另有 {{.MoreDomains}} 个
+{{- end}}
+{{- end}}
+{{- if .Assets}}
+
+{{bold "值得关注的资产"}}
+{{- range .Assets}}
+- {{code .Value}} {{if eq .Kind "new"}}新增{{else}}变化 ({{.Changes}}){{end}}{{if .Title}} 「{{.Title}}」{{end}}{{if .WebServer}} [{{.WebServer}}]{{end}}
+{{- end}}
+{{- if .MoreAssets}}
+... 另有 {{.MoreAssets}} 个
+{{- end}}
+{{- end}}`
+
+// chatFuncs 返回渠道对应的模板函数
+func chatFuncs(provider string) template.FuncMap {
+	bold, code := "*", "`"
+	switch provider {
+	case model.ChatProviderDiscord:
+		bold = "**"
+	case model.ChatProviderTelegram:
+		// Telegram 以纯文本发送，不使用标记
+		bold, code = "", ""
+	}
+	return template.FuncMap{
+		"bold": func(s string) string { return bold + s + bold },
+		"code": func(s string) string { return code + s + code },
+		"join": strings.Join,
+	}
+}
+
+// ParseChatTemplate 解析渠道模板，为空时使用默认模板
+func ParseChatTemplate(provider, text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultChatTemplate
+	}
+	tmpl, err := template.New("chat").Funcs(chatFuncs(provider)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("消息模板无效: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ValidateChatChannel 校验渠道类型、Telegram 的 chat_id，并用示例数据试渲染模板
+func ValidateChatChannel(channel *model.ChatChannel) error {
+	if !slices.Contains(ChatProviders, channel.Provider) {
+		return fmt.Errorf("不支持的渠道类型 '%s'", channel.Provider)
+	}
+	if channel.Provider == model.ChatProviderTelegram && channel.ChatID == "" {
+		return fmt.Errorf("Telegram 渠道必须指定 chatId")
+	}
+	if channel.MinInterval < 0 || channel.MaxItems < 0 {
+		return fmt.Errorf("minInterval 和 maxItems 不能为负数")
+	}
+	_, err := RenderChatMessage(channel, SampleDigest())
+	return err
+}
+
+// SampleDigest 返回用于校验模板和测试发送的示例摘要
+func SampleDigest() Digest {
+	return Digest{
+		ProjectName: "示例项目",
+		Workflows:   []uint{1},
+		Summary:     changes.Summary{NewDomains: 2, NewAssets: 1, ChangedAssets: 1},
+		NewDomains:  []string{"dev.example.com", "api.example.com"},
+		Assets: []DigestAsset{
+			{Value: "203.0.113.10:443", Kind: "new", Title: "Admin Console", WebServer: "nginx"},
+			{Value: "203.0.113.11:8080", Kind: "changed", Title: "Jenkins", Changes: "title"},
+		},
+	}
+}
+
+// RenderChatMessage 用渠道模板渲染摘要，并按平台的长度上限截断
+func RenderChatMessage(channel *model.ChatChannel, digest Digest) (string, error) {
+	tmpl, err := ParseChatTemplate(channel.Provider, channel.Template)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, digest); err != nil {
+		return "", fmt.Errorf("渲染消息模板失败: %w", err)
+	}
+	return truncateMessage(buf.String(), chatMessageLimits[channel.Provider]), nil
+}
+
+func truncateMessage(text string, limit int) string {
+	const suffix = "\n...(已截断)"
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:limit-utf8.RuneCountInString(suffix)]) + suffix
+}
+
+// chatRequest 按平台的 incoming-webhook 格式构造请求地址和请求体
+func chatRequest(channel *model.ChatChannel, text string) (string, []byte, error) {
+	var body interface{}
+	url := channel.URL
+	switch channel.Provider {
+	case model.ChatProviderSlack:
+		body = map[string]interface{}{"text": text}
+	case model.ChatProviderDiscord:
+		body = map[string]interface{}{"content": text, "username": "src-hunter"}
+	case model.ChatProviderTelegram:
+		if !strings.HasSuffix(url, "/sendMessage") {
+			url = strings.TrimSuffix(url, "/") + "/sendMessage"
+		}
+		body = map[string]interface{}{"chat_id": channel.ChatID, "text": text, "disable_web_page_preview": true}
+	default:
+		return "", nil, fmt.Errorf("不支持的渠道类型 '%s'", channel.Provider)
+	}
+	payload, err := json.Marshal(body)
+	return url, payload, err
+}
+
+// SendChatMessage 把消息发送到渠道，接收方返回非 2xx 时返回错误
+func SendChatMessage(ctx context.Context, client *http.Client, channel *model.ChatChannel, text string) error {
+	url, payload, err := chatRequest(channel, text)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("构造请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "src-hunter-chat/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return fmt.Errorf("渠道返回状态码 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
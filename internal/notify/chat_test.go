@@ -0,0 +1,319 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/changes"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+)
+
+func TestChatRequest(t *testing.T) {
+	cases := []struct {
+		name     string
+		channel  model.ChatChannel
+		wantURL  string
+		wantBody map[string]interface{}
+	}{
+		{
+			name:     "slack",
+			channel:  model.ChatChannel{Provider: model.ChatProviderSlack, URL: "https://hooks.slack.com/services/T/B/X"},
+			wantURL:  "https://hooks.slack.com/services/T/B/X",
+			wantBody: map[string]interface{}{"text": "hello"},
+		},
+		{
+			name:     "discord",
+			channel:  model.ChatChannel{Provider: model.ChatProviderDiscord, URL: "https://discord.com/api/webhooks/1/abc"},
+			wantURL:  "https://discord.com/api/webhooks/1/abc",
+			wantBody: map[string]interface{}{"content": "hello", "username": "src-hunter"},
+		},
+		{
+			name:     "telegram",
+			channel:  model.ChatChannel{Provider: model.ChatProviderTelegram, URL: "https://api.telegram.org/bot123:abc/", ChatID: "-10042"},
+			wantURL:  "https://api.telegram.org/bot123:abc/sendMessage",
+			wantBody: map[string]interface{}{"chat_id": "-10042", "text": "hello", "disable_web_page_preview": true},
+		},
+		{
+			name:     "telegram 已包含 sendMessage",
+			channel:  model.ChatChannel{Provider: model.ChatProviderTelegram, URL: "https://api.telegram.org/bot123:abc/sendMessage", ChatID: "7"},
+			wantURL:  "https://api.telegram.org/bot123:abc/sendMessage",
+			wantBody: map[string]interface{}{"chat_id": "7", "text": "hello", "disable_web_page_preview": true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, payload, err := chatRequest(&tc.channel, "hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if url != tc.wantURL {
+				t.Errorf("url = %q, want %q", url, tc.wantURL)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(payload, &body); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body, tc.wantBody) {
+				t.Errorf("body = %v, want %v", body, tc.wantBody)
+			}
+		})
+	}
+
+	if _, _, err := chatRequest(&model.ChatChannel{Provider: "teams"}, "hello"); err == nil {
+		t.Error("不支持的渠道类型应返回错误")
+	}
+}
+
+func TestSendChatMessage(t *testing.T) {
+	cases := []struct {
+		provider string
+		path     string
+		field    string
+	}{
+		{model.ChatProviderSlack, "/services/T/B/X", "text"},
+		{model.ChatProviderDiscord, "/api/webhooks/1/abc", "content"},
+		{model.ChatProviderTelegram, "/bot123:abc/sendMessage", "text"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.provider, func(t *testing.T) {
+			r, server := newReceiver(t, "", http.StatusOK)
+			url := server.URL + tc.path
+			if tc.provider == model.ChatProviderTelegram {
+				url = server.URL + "/bot123:abc"
+			}
+			channel := &model.ChatChannel{Provider: tc.provider, URL: url, ChatID: "42"}
+
+			if err := SendChatMessage(context.Background(), http.DefaultClient, channel, "新增域名 1"); err != nil {
+				t.Fatal(err)
+			}
+			if r.count() != 1 {
+				t.Fatalf("请求次数 = %d, want 1", r.count())
+			}
+			req, raw := r.request(0)
+			if req.Method != http.MethodPost || req.URL.Path != tc.path {
+				t.Errorf("请求 = %s %s, want POST %s", req.Method, req.URL.Path, tc.path)
+			}
+			if got := req.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(raw, &body); err != nil {
+				t.Fatal(err)
+			}
+			if body[tc.field] != "新增域名 1" {
+				t.Errorf("%s = %v", tc.field, body[tc.field])
+			}
+			if tc.provider == model.ChatProviderTelegram && body["chat_id"] != "42" {
+				t.Errorf("chat_id = %v", body["chat_id"])
+			}
+		})
+	}
+}
+
+func TestSendChatMessageNon2xx(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		_, server := newReceiver(t, "", status)
+		channel := &model.ChatChannel{Provider: model.ChatProviderSlack, URL: server.URL}
+		err := SendChatMessage(context.Background(), http.DefaultClient, channel, "hello")
+		if err == nil {
+			t.Fatalf("状态码 %d 应返回错误", status)
+		}
+		if !strings.Contains(err.Error(), strconv.Itoa(status)) {
+			t.Errorf("错误中应包含状态码 %d: %v", status, err)
+		}
+	}
+}
+
+func TestRenderChatMessageTruncates(t *testing.T) {
+	digest := Digest{ProjectName: "p", Workflows: []uint{1}}
+	for i := 0; i < 500; i++ {
+		digest.NewDomains = append(digest.NewDomains, "very-long-subdomain-name-"+strconv.Itoa(i)+".example.com")
+	}
+	digest.Summary.NewDomains = len(digest.NewDomains)
+
+	for provider, limit := range chatMessageLimits {
+		t.Run(provider, func(t *testing.T) {
+			channel := &model.ChatChannel{Provider: provider}
+			text, err := RenderChatMessage(channel, digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := utf8.RuneCountInString(text); n != limit {
+				t.Errorf("长度 = %d, want %d", n, limit)
+			}
+			if !strings.HasSuffix(text, "...(已截断)") {
+				t.Errorf("截断的消息应以提示结尾: %q", text[len(text)-40:])
+			}
+
+			short, err := RenderChatMessage(channel, SampleDigest())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(short, "已截断") {
+				t.Error("未超出上限的消息不应截断")
+			}
+		})
+	}
+}
+
+func TestMergeDigest(t *testing.T) {
+	project := &model.Project{Name: "demo"}
+	project.ID = 3
+	workflows := []DigestWorkflow{
+		{
+			WorkflowID: 10,
+			Summary:    changes.Summary{NewDomains: 3, NewAssets: 1, ChangedAssets: 1},
+			NewDomains: []string{"a.example.com", "b.example.com", "c.example.com"},
+			Assets:     []DigestAsset{{Value: "1.1.1.1:443", Kind: "new"}, {Value: "1.1.1.2:80", Kind: "changed", Changes: "title"}},
+		},
+		{
+			// 第二次运行重复发现了 c 和 1.1.1.1:443，且保存时已省略了 2 个域名
+			WorkflowID: 11,
+			Summary:    changes.Summary{NewDomains: 4, NewAssets: 2, RemovedAssets: 1},
+			NewDomains: []string{"c.example.com", "d.example.com"},
+			Assets:     []DigestAsset{{Value: "1.1.1.1:443", Kind: "new"}, {Value: "1.1.1.3:22", Kind: "new"}},
+		},
+	}
+
+	digest := MergeDigest(project, workflows, 10)
+	if digest.ProjectID != 3 || digest.ProjectName != "demo" || !reflect.DeepEqual(digest.Workflows, []uint{10, 11}) {
+		t.Errorf("项目或工作流不正确: %+v", digest)
+	}
+	wantSummary := changes.Summary{NewDomains: 7, NewAssets: 3, ChangedAssets: 1, RemovedAssets: 1}
+	if digest.Summary != wantSummary {
+		t.Errorf("Summary = %+v, want %+v", digest.Summary, wantSummary)
+	}
+	wantDomains := []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"}
+	if !reflect.DeepEqual(digest.NewDomains, wantDomains) {
+		t.Errorf("NewDomains = %v, want %v", digest.NewDomains, wantDomains)
+	}
+	if len(digest.Assets) != 3 {
+		t.Errorf("Assets = %v, 重复的资产应只列出一次", digest.Assets)
+	}
+	// 全部列出时，只有第二次运行保存时省略的 2 个域名计入"另有"
+	if digest.MoreDomains != 2 || digest.MoreAssets != 0 {
+		t.Errorf("MoreDomains = %d, MoreAssets = %d, want 2, 0", digest.MoreDomains, digest.MoreAssets)
+	}
+
+	limited := MergeDigest(project, workflows, 2)
+	if !reflect.DeepEqual(limited.NewDomains, wantDomains[:2]) || len(limited.Assets) != 2 {
+		t.Errorf("超出 maxItems 时应截断: %v %v", limited.NewDomains, limited.Assets)
+	}
+	if limited.MoreDomains != 4 || limited.MoreAssets != 1 {
+		t.Errorf("MoreDomains = %d, MoreAssets = %d, want 4, 1", limited.MoreDomains, limited.MoreAssets)
+	}
+}
+
+func TestNewDigestWorkflow(t *testing.T) {
+	set := &changes.ChangeSet{
+		ToWorkflowID: 5,
+		Summary:      changes.Summary{NewDomains: 3, ChangedAssets: 1},
+		NewDomains:   []changes.EntityChange{{Value: "a.example.com"}, {Value: "b.example.com"}, {Value: "c.example.com"}},
+		ChangedAssets: []changes.EntityChange{{Value: "1.1.1.1:443", Changes: []changes.FieldChange{
+			{Field: "title", Old: "Old", New: "Admin"},
+			{Field: "web_server", Old: "apache", New: "nginx"},
+		}}},
+	}
+	wf := NewDigestWorkflow(set, 2)
+	if wf.WorkflowID != 5 || len(wf.NewDomains) != 2 {
+		t.Errorf("WorkflowID = %d, NewDomains = %v", wf.WorkflowID, wf.NewDomains)
+	}
+	want := DigestAsset{Value: "1.1.1.1:443", Kind: "changed", Title: "Admin", WebServer: "nginx", Changes: "title, web_server"}
+	if len(wf.Assets) != 1 || wf.Assets[0] != want {
+		t.Errorf("Assets = %+v, want %+v", wf.Assets, want)
+	}
+}
+
+// newFlushChannel 在测试库中创建项目、指向 url 的 Slack 渠道和若干待发送摘要
+func newFlushChannel(t *testing.T, d *ChatDispatcher, url string, workflows ...DigestWorkflow) (*model.ChatChannel, []uint) {
+	t.Helper()
+	project := model.Project{Name: "chat-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	if err := d.DB.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	channel := model.ChatChannel{ProjectID: project.ID, Name: "ops", Provider: model.ChatProviderSlack, URL: url, IsEnabled: true}
+	if err := d.DB.Create(&channel).Error; err != nil {
+		t.Fatal(err)
+	}
+	var ids []uint
+	for _, wf := range workflows {
+		data, _ := json.Marshal(wf)
+		entry := model.ChatDigestEntry{ProjectID: project.ID, ChannelID: channel.ID, WorkflowID: wf.WorkflowID, Data: data}
+		if err := d.DB.Create(&entry).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, entry.ID)
+	}
+	return &channel, ids
+}
+
+func flushTask(channelID uint) *asynq.Task {
+	payload, _ := json.Marshal(ChatFlushPayload{ChannelID: channelID})
+	return asynq.NewTask(TaskTypeChatFlush, payload)
+}
+
+func TestHandleFlushTaskSendsAndMarksEntries(t *testing.T) {
+	db := testutil.DB(t)
+	r, server := newReceiver(t, "", http.StatusOK)
+	d := NewChatDispatcher(db, nil)
+	channel, ids := newFlushChannel(t, d, server.URL,
+		DigestWorkflow{WorkflowID: 1, Summary: changes.Summary{NewDomains: 1}, NewDomains: []string{"a.example.com"}},
+		DigestWorkflow{WorkflowID: 2, Summary: changes.Summary{NewDomains: 1}, NewDomains: []string{"b.example.com"}},
+	)
+
+	if err := d.HandleFlushTask(context.Background(), flushTask(channel.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if r.count() != 1 {
+		t.Fatalf("两条摘要应合并为一条消息，请求次数 = %d", r.count())
+	}
+	_, raw := r.request(0)
+	if !strings.Contains(string(raw), "a.example.com") || !strings.Contains(string(raw), "b.example.com") {
+		t.Errorf("消息中应包含两个工作流的域名: %s", raw)
+	}
+
+	var entries []model.ChatDigestEntry
+	db.Find(&entries, ids)
+	for _, e := range entries {
+		if e.SentAt == nil {
+			t.Errorf("摘要 %d 应标记为已发送", e.ID)
+		}
+	}
+	var saved model.ChatChannel
+	db.First(&saved, channel.ID)
+	if saved.LastSentAt.IsZero() {
+		t.Error("应记录渠道的上次发送时间")
+	}
+}
+
+func TestHandleFlushTaskReleasesClaimOnFailure(t *testing.T) {
+	db := testutil.DB(t)
+	_, server := newReceiver(t, "", http.StatusInternalServerError)
+	d := NewChatDispatcher(db, nil)
+	channel, ids := newFlushChannel(t, d, server.URL,
+		DigestWorkflow{WorkflowID: 1, Summary: changes.Summary{NewDomains: 1}, NewDomains: []string{"a.example.com"}},
+	)
+
+	if err := d.HandleFlushTask(context.Background(), flushTask(channel.ID)); err == nil {
+		t.Fatal("发送失败时应返回错误以便重试")
+	}
+	var entry model.ChatDigestEntry
+	db.First(&entry, ids[0])
+	if entry.SentAt != nil || entry.ClaimedAt != nil {
+		t.Errorf("发送失败后摘要应保持待发送且未被认领: sent=%v claimed=%v", entry.SentAt, entry.ClaimedAt)
+	}
+	var saved model.ChatChannel
+	db.First(&saved, channel.ID)
+	if !saved.LastSentAt.IsZero() {
+		t.Error("发送失败时不应更新上次发送时间")
+	}
+}
@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/changes"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// TaskTypeChatFlush 是把渠道中待发送的摘要合并发送的任务类型
+	TaskTypeChatFlush = "notify:chat:flush"
+	// digestBatchDelay 是摘要的最短合并等待时间，期间完成的工作流会合并为一条消息
+	digestBatchDelay = 30 * time.Second
+	// maxDigestEntries 是单条消息最多合并的工作流数量
+	maxDigestEntries = 50
+	// claimTimeout 之后仍未发送完成的认领视为失效 (如 worker 在发送途中退出)，摘要可以被重新认领
+	claimTimeout = 5 * time.Minute
+)
+
+// ChatFlushPayload 是摘要发送任务的载荷
+type ChatFlushPayload struct {
+	ChannelID uint `json:"channel_id"`
+}
+
+// minInterval 返回渠道两次发送之间的最小间隔
+func minInterval(channel *model.ChatChannel) time.Duration {
+	if channel.MinInterval <= 0 {
+		return DefaultChatMinInterval * time.Second
+	}
+	return time.Duration(channel.MinInterval) * time.Second
+}
+
+func maxItems(channel *model.ChatChannel) int {
+	if channel.MaxItems <= 0 {
+		return DefaultChatMaxItems
+	}
+	return channel.MaxItems
+}
+
+// AddDigest 把一次工作流的新发现加入项目所有启用渠道的待发送摘要，并安排发送任务。
+// 没有任何新增或变化的运行不会产生消息。
+func (p *Publisher) AddDigest(workflow *model.Task, set *changes.ChangeSet) error {
+	s := set.Summary
+	if s.NewDomains+s.NewAssets+s.ChangedAssets+s.RemovedAssets+s.RemovedDomains == 0 {
+		return nil
+	}
+	var channels []model.ChatChannel
+	if err := p.DB.Where("project_id = ? AND is_enabled = ?", workflow.ProjectID, true).Find(&channels).Error; err != nil {
+		return fmt.Errorf("查询聊天渠道失败: %w", err)
+	}
+	for i := range channels {
+		channel := &channels[i]
+		data, _ := json.Marshal(NewDigestWorkflow(set, maxItems(channel)))
+		entry := model.ChatDigestEntry{
+			ProjectID:  workflow.ProjectID,
+			ChannelID:  channel.ID,
+			WorkflowID: workflow.ID,
+			Data:       data,
+		}
+		// 同一工作流重复完成时 (如任务被重复投递) 只保留第一条，避免合并时重复计数
+		result := p.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "channel_id"}, {Name: "workflow_id"}},
+			DoNothing: true,
+		}).Create(&entry)
+		if result.Error != nil {
+			return fmt.Errorf("保存待发送摘要失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		at := time.Now().Add(digestBatchDelay)
+		if earliest := channel.LastSentAt.Add(minInterval(channel)); earliest.After(at) {
+			at = earliest
+		}
+		if err := scheduleFlush(p.AsynqClient, channel.ID, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduleFlush 安排在 at 之后发送渠道的摘要。时间按 digestBatchDelay 向上取整并作为任务ID的一部分，
+// 同一时间窗口内的多次安排只会产生一个任务，从而把多个工作流合并为一条消息。
+func scheduleFlush(client *asynq.Client, channelID uint, at time.Time) error {
+	at = at.Truncate(digestBatchDelay).Add(digestBatchDelay)
+	payload, _ := json.Marshal(ChatFlushPayload{ChannelID: channelID})
+	_, err := client.Enqueue(
+		asynq.NewTask(TaskTypeChatFlush, payload),
+		asynq.Queue(QueueNotifications),
+		asynq.TaskID(fmt.Sprintf("chat-flush:%d:%d", channelID, at.Unix())),
+		asynq.ProcessAt(at),
+		asynq.MaxRetry(5),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return fmt.Errorf("安排摘要发送任务失败: %w", err)
+	}
+	return nil
+}
+
+// ChatDispatcher 负责把渠道中待发送的摘要合并为一条消息并发送
+type ChatDispatcher struct {
+	DB          *gorm.DB
+	AsynqClient *asynq.Client
+	HTTPClient  *http.Client
+}
+
+func NewChatDispatcher(db *gorm.DB, client *asynq.Client) *ChatDispatcher {
+	return &ChatDispatcher{
+		DB:          db,
+		AsynqClient: client,
+		HTTPClient:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// HandleFlushTask 合并发送渠道的待发送摘要。距上次发送不足最小间隔时推迟到允许的时间；
+// 发送失败时释放认领并返回错误，由 asynq 退避重试，摘要保持待发送状态。
+func (d *ChatDispatcher) HandleFlushTask(ctx context.Context, t *asynq.Task) error {
+	var payload ChatFlushPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析摘要发送任务载荷失败: %v: %w", err, asynq.SkipRetry)
+	}
+
+	channel, entries, err := d.claim(payload.ChannelID)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	text, err := d.render(channel, entries)
+	if err == nil {
+		err = SendChatMessage(ctx, d.HTTPClient, channel, text)
+	}
+	if err != nil {
+		logger.Logger.Warn("发送聊天摘要失败", zap.Uint("channel_id", channel.ID), zap.Error(err))
+		if releaseErr := d.DB.Model(&model.ChatDigestEntry{}).Where("id IN ?", ids).Update("claimed_at", nil).Error; releaseErr != nil {
+			logger.Logger.Error("释放待发送摘要失败", zap.Uint("channel_id", channel.ID), zap.Error(releaseErr))
+		}
+		return err
+	}
+
+	now := time.Now()
+	if err := d.DB.Model(&model.ChatDigestEntry{}).Where("id IN ?", ids).Update("sent_at", now).Error; err != nil {
+		return fmt.Errorf("标记摘要已发送失败: %v: %w", err, asynq.SkipRetry)
+	}
+	if err := d.DB.Model(channel).Update("last_sent_at", now).Error; err != nil {
+		return err
+	}
+	// 超出单条消息上限的摘要留到下一个时间窗口发送
+	if len(entries) == maxDigestEntries {
+		return scheduleFlush(d.AsynqClient, channel.ID, now.Add(minInterval(channel)))
+	}
+	return nil
+}
+
+// claim 锁住渠道记录并认领一批待发送的摘要，随即提交事务，发送请求不在事务中进行。
+// 距上次发送不足最小间隔或另一批摘要仍在发送时，推迟到之后再发送，返回的摘要为空。
+func (d *ChatDispatcher) claim(channelID uint) (*model.ChatChannel, []model.ChatDigestEntry, error) {
+	var channel model.ChatChannel
+	var entries []model.ChatDigestEntry
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&channel, channelID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // 渠道已被删除
+			}
+			return err
+		}
+		if !channel.IsEnabled {
+			return nil
+		}
+		now := time.Now()
+		if earliest := channel.LastSentAt.Add(minInterval(&channel)); now.Before(earliest) {
+			return scheduleFlush(d.AsynqClient, channel.ID, earliest)
+		}
+
+		var inFlight int64
+		if err := tx.Model(&model.ChatDigestEntry{}).
+			Where("channel_id = ? AND sent_at IS NULL AND claimed_at >= ?", channel.ID, now.Add(-claimTimeout)).
+			Count(&inFlight).Error; err != nil {
+			return err
+		}
+		if inFlight > 0 {
+			return scheduleFlush(d.AsynqClient, channel.ID, now.Add(minInterval(&channel)))
+		}
+
+		if err := tx.Where("channel_id = ? AND sent_at IS NULL", channel.ID).
+			Order("id").Limit(maxDigestEntries).Find(&entries).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		ids := make([]uint, 0, len(entries))
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return tx.Model(&model.ChatDigestEntry{}).Where("id IN ?", ids).Update("claimed_at", now).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &channel, entries, nil
+}
+
+// render 合并认领的摘要并用渠道模板渲染为一条消息
+func (d *ChatDispatcher) render(channel *model.ChatChannel, entries []model.ChatDigestEntry) (string, error) {
+	var project model.Project
+	if err := d.DB.First(&project, channel.ProjectID).Error; err != nil {
+		return "", err
+	}
+	workflows := make([]DigestWorkflow, 0, len(entries))
+	for _, e := range entries {
+		var wf DigestWorkflow
+		if err := json.Unmarshal(e.Data, &wf); err != nil {
+			logger.Logger.Warn("解析待发送摘要失败，已跳过", zap.Uint("entry_id", e.ID), zap.Error(err))
+			continue
+		}
+		workflows = append(workflows, wf)
+	}
+
+	digest := MergeDigest(&project, workflows, maxItems(channel))
+	text, err := RenderChatMessage(channel, digest)
+	if err != nil {
+		// 模板在保存时已校验，这里出错时退回默认模板，避免摘要一直无法发送
+		logger.Logger.Warn("渲染渠道模板失败，使用默认模板", zap.Uint("channel_id", channel.ID), zap.Error(err))
+		fallback := *channel
+		fallback.Template = ""
+		if text, err = RenderChatMessage(&fallback, digest); err != nil {
+			return "", fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+		}
+	}
+	return text, nil
+}
+
+// SendTest 立即用示例数据向渠道发送一条测试消息，不受发送间隔限制
+func (d *ChatDispatcher) SendTest(ctx context.Context, channel *model.ChatChannel) error {
+	digest := SampleDigest()
+	var project model.Project
+	if err := d.DB.First(&project, channel.ProjectID).Error; err == nil {
+		digest.ProjectID, digest.ProjectName = project.ID, project.Name
+	}
+	text, err := RenderChatMessage(channel, digest)
+	if err != nil {
+		return err
+	}
+	return SendChatMessage(ctx, d.HTTPClient, channel, text)
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// QueueNotifications 是投递通知使用的独立队列，避免与扫描任务互相阻塞
+	QueueNotifications = "notifications"
+	// TaskTypeWebhookDeliver 是投递单个 Webhook 的任务类型
+	TaskTypeWebhookDeliver = "notify:webhook:deliver"
+	// maxDeliveryRetry 是单次投递的最大重试次数
+	maxDeliveryRetry = 8
+)
+
+// 事件类型
+const (
+	EventWorkflowCompleted = "workflow.completed" // 工作流成功完成，附带变化汇总
+	EventWorkflowFailed    = "workflow.failed"    // 工作流中某个步骤失败
+	EventDomainNew         = "domain.new"         // 一次运行中新发现的域名
+	EventAssetNew          = "asset.new"          // 一次运行中新发现的资产
+	EventAssetChanged      = "asset.changed"      // 一次运行中标题、Web服务器或技术栈发生变化的资产
+	EventTest              = "webhook.test"       // 测试投递
+)
+
+// Events 是所有可订阅的事件类型
+var Events = []string{
+	EventWorkflowCompleted,
+	EventWorkflowFailed,
+	EventDomainNew,
+	EventAssetNew,
+	EventAssetChanged,
+}
+
+// ValidateEvents 校验订阅的事件类型是否都受支持
+func ValidateEvents(events []string) error {
+	for _, e := range events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("不支持的事件类型 '%s'", e)
+		}
+	}
+	return nil
+}
+
+// Envelope 是投递给接收方的 JSON 请求体
+type Envelope struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	ProjectID  uint        `json:"projectId"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// NewEnvelope 为事件生成带唯一ID的请求体
+func NewEnvelope(projectID uint, event string, data interface{}) Envelope {
+	return Envelope{
+		ID:         uuid.NewString(),
+		Event:      event,
+		ProjectID:  projectID,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+}
+
+// DeliverPayload 是投递任务的载荷
+type DeliverPayload struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// Publisher 负责把事件分发给项目中订阅了该事件的 Webhook
+type Publisher struct {
+	DB          *gorm.DB
+	AsynqClient *asynq.Client
+}
+
+func NewPublisher(db *gorm.DB, client *asynq.Client) *Publisher {
+	return &Publisher{DB: db, AsynqClient: client}
+}
+
+// Publish 为每个订阅了该事件的启用订阅创建投递记录并入队，返回创建的投递数量
+func (p *Publisher) Publish(projectID uint, event string, data interface{}) (int, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := p.DB.Where("project_id = ? AND is_enabled = ?", projectID, true).Find(&subscriptions).Error; err != nil {
+		return 0, fmt.Errorf("查询Webhook订阅失败: %w", err)
+	}
+
+	envelope := NewEnvelope(projectID, event, data)
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return 0, fmt.Errorf("序列化事件失败: %w", err)
+	}
+
+	count := 0
+	for _, sub := range subscriptions {
+		if len(sub.Events) > 0 && !slices.Contains(sub.Events, event) {
+			continue
+		}
+		delivery := model.WebhookDelivery{
+			ProjectID:      projectID,
+			SubscriptionID: sub.ID,
+			EventID:        envelope.ID,
+			Event:          event,
+			Payload:        body,
+			Status:         model.DeliveryStatusPending,
+		}
+		if err := p.DB.Create(&delivery).Error; err != nil {
+			return count, fmt.Errorf("创建投递记录失败: %w", err)
+		}
+		if err := p.enqueue(delivery.ID); err != nil {
+			p.DB.Model(&delivery).Updates(map[string]interface{}{
+				"status": model.DeliveryStatusFailed,
+				"error":  fmt.Sprintf("投递任务入队失败: %v", err),
+			})
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (p *Publisher) enqueue(deliveryID uint) error {
+	payload, _ := json.Marshal(DeliverPayload{DeliveryID: deliveryID})
+	_, err := p.AsynqClient.Enqueue(
+		asynq.NewTask(TaskTypeWebhookDeliver, payload),
+		asynq.Queue(QueueNotifications),
+		asynq.MaxRetry(maxDeliveryRetry),
+		asynq.Timeout(time.Minute),
+	)
+	return err
+}
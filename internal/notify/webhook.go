@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 投递请求携带的头部
+const (
+	HeaderEvent     = "X-Src-Hunter-Event"
+	HeaderDelivery  = "X-Src-Hunter-Delivery"
+	HeaderTimestamp = "X-Src-Hunter-Timestamp"
+	// HeaderSignature 的值为 "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	HeaderSignature = "X-Src-Hunter-Signature"
+)
+
+// maxResponseBody 是投递日志中保存的响应体最大长度
+const maxResponseBody = 2048
+
+// Sign 计算请求体的签名，接收方应使用相同算法校验并拒绝时间戳过旧的请求以防重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay 为通知任务提供带抖动的指数退避 (30s, 1m, 2m ... 最长 1h)，其他任务使用 asynq 默认策略
+func RetryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if !strings.HasPrefix(t.Type(), "notify:") {
+		return asynq.DefaultRetryDelayFunc(n, err, t)
+	}
+	delay := 30 * time.Second << min(n, 7)
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay/10)+1))
+}
+
+// Deliverer 负责把投递记录发送到订阅的 URL 并记录结果
+type Deliverer struct {
+	DB         *gorm.DB
+	HTTPClient *http.Client
+}
+
+func NewDeliverer(db *gorm.DB) *Deliverer {
+	return &Deliverer{
+		DB:         db,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// HandleDeliverTask 处理投递任务。可重试的失败返回错误交由 asynq 按 RetryDelay 退避重试；
+// 接收方返回 4xx (408、429 除外) 时视为不可重试，直接标记为失败。
+func (d *Deliverer) HandleDeliverTask(ctx context.Context, t *asynq.Task) error {
+	var payload DeliverPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析投递任务载荷失败: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return d.deliver(ctx, payload.DeliveryID, retried >= maxRetry)
+}
+
+// deliver 执行一次投递并更新投递记录，lastAttempt 为 true 时可重试的失败也直接标记为失败
+func (d *Deliverer) deliver(ctx context.Context, deliveryID uint, lastAttempt bool) error {
+	var delivery model.WebhookDelivery
+	if err := d.DB.First(&delivery, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // 项目或订阅已被删除
+		}
+		return err
+	}
+	var sub model.WebhookSubscription
+	if err := d.DB.First(&sub, delivery.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			d.finish(&delivery, model.DeliveryStatusFailed, "订阅已被删除")
+			return nil
+		}
+		return err
+	}
+
+	retryable, err := d.Send(ctx, &sub, &delivery)
+	if err == nil {
+		return nil
+	}
+
+	if !retryable || lastAttempt {
+		d.finish(&delivery, model.DeliveryStatusFailed, err.Error())
+		logger.Logger.Warn("Webhook投递失败",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Uint("subscription_id", sub.ID),
+			zap.Error(err),
+		)
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+	d.finish(&delivery, model.DeliveryStatusRetrying, err.Error())
+	return err
+}
+
+// Send 执行一次投递尝试并把响应写入投递记录，返回错误时 retryable 表示是否值得重试
+func (d *Deliverer) Send(ctx context.Context, sub *model.WebhookSubscription, delivery *model.WebhookDelivery) (retryable bool, err error) {
+	delivery.Attempts++
+	delivery.ResponseCode = 0
+	delivery.ResponseBody = ""
+
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return false, fmt.Errorf("构造请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "src-hunter-webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	delivery.ResponseCode = resp.StatusCode
+	delivery.ResponseBody = string(body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		now := time.Now()
+		delivery.DeliveredAt = &now
+		d.finish(delivery, model.DeliveryStatusSuccess, "")
+		return false, nil
+	}
+	err = fmt.Errorf("接收方返回状态码 %d", resp.StatusCode)
+	retryable = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, err
+}
+
+// finish 保存投递记录的最新状态
+func (d *Deliverer) finish(delivery *model.WebhookDelivery, status, message string) {
+	delivery.Status = status
+	delivery.Error = message
+	if err := d.DB.Model(delivery).Select("status", "attempts", "response_code", "response_body", "error", "delivered_at").
+		Updates(delivery).Error; err != nil {
+		logger.Logger.Error("保存投递记录失败", zap.Uint("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// SendTest 立即向订阅发送一个测试事件，并把结果记录为一条投递日志
+func (d *Deliverer) SendTest(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookDelivery, error) {
+	envelope := NewEnvelope(sub.ProjectID, EventTest, map[string]interface{}{
+		"subscriptionId": sub.ID,
+		"message":        "这是一条来自 src-hunter 的测试消息",
+	})
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	delivery := model.WebhookDelivery{
+		ProjectID:      sub.ProjectID,
+		SubscriptionID: sub.ID,
+		EventID:        envelope.ID,
+		Event:          EventTest,
+		Payload:        body,
+		Status:         model.DeliveryStatusPending,
+	}
+	if err := d.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	if _, err := d.Send(ctx, sub, &delivery); err != nil {
+		d.finish(&delivery, model.DeliveryStatusFailed, err.Error())
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,315 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+)
+
+// receiver 是记录请求并校验签名的测试接收方
+type receiver struct {
+	t      *testing.T
+	secret string
+	status []int // 依次返回的状态码，用完后重复最后一个
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, secret string, status ...int) (*receiver, *httptest.Server) {
+	r := &receiver{t: t, secret: secret, status: status}
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	n := len(r.requests)
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+
+	if r.secret != "" {
+		ts, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			r.t.Errorf("时间戳头部无效: %q", req.Header.Get(HeaderTimestamp))
+		}
+		if got, want := req.Header.Get(HeaderSignature), Sign(r.secret, ts, body); got != want {
+			r.t.Errorf("签名不匹配: got %q, want %q", got, want)
+		}
+	}
+	status := r.status[min(n, len(r.status)-1)]
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte("status " + strconv.Itoa(status)))
+}
+
+// request 返回第 i 个请求及其请求体
+func (r *receiver) request(i int) (*http.Request, []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[i], r.bodies[i]
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func TestSendRetryableStatus(t *testing.T) {
+	cases := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusRequestTimeout, true},
+		{http.StatusBadRequest, false},
+		{http.StatusUnauthorized, false},
+		{http.StatusNotFound, false},
+		{http.StatusGone, false},
+	}
+	for _, tc := range cases {
+		t.Run(strconv.Itoa(tc.status), func(t *testing.T) {
+			r, server := newReceiver(t, "s3cret", tc.status)
+			d := NewDeliverer(nil)
+			sub := &model.WebhookSubscription{URL: server.URL, Secret: "s3cret"}
+			delivery := &model.WebhookDelivery{Event: EventDomainNew, Payload: []byte(`{"event":"domain.new"}`), Attempts: 2}
+
+			retryable, err := d.Send(context.Background(), sub, delivery)
+			if err == nil {
+				t.Fatal("非 2xx 响应应返回错误")
+			}
+			if retryable != tc.retryable {
+				t.Errorf("retryable = %v, want %v", retryable, tc.retryable)
+			}
+			if delivery.Attempts != 3 {
+				t.Errorf("Attempts = %d, want 3", delivery.Attempts)
+			}
+			if delivery.ResponseCode != tc.status || delivery.ResponseBody != "status "+strconv.Itoa(tc.status) {
+				t.Errorf("响应未记录: code=%d body=%q", delivery.ResponseCode, delivery.ResponseBody)
+			}
+			if r.count() != 1 {
+				t.Fatalf("请求次数 = %d, want 1", r.count())
+			}
+			req, body := r.request(0)
+			if got := req.Header.Get(HeaderEvent); got != EventDomainNew {
+				t.Errorf("%s = %q", HeaderEvent, got)
+			}
+			if got := string(body); got != `{"event":"domain.new"}` {
+				t.Errorf("请求体 = %q", got)
+			}
+		})
+	}
+}
+
+func TestSendWithoutSecretOmitsSignature(t *testing.T) {
+	r, server := newReceiver(t, "", http.StatusServiceUnavailable)
+	d := NewDeliverer(nil)
+	sub := &model.WebhookSubscription{URL: server.URL}
+	if _, err := d.Send(context.Background(), sub, &model.WebhookDelivery{Payload: []byte(`{}`)}); err == nil {
+		t.Fatal("503 应返回错误")
+	}
+	req, _ := r.request(0)
+	if got := req.Header.Get(HeaderSignature); got != "" {
+		t.Errorf("未设置密钥时不应签名，got %q", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	task := asynq.NewTask(TaskTypeWebhookDeliver, nil)
+	for n := 0; n <= 12; n++ {
+		base := min(30*time.Second<<min(n, 7), time.Hour)
+		for i := 0; i < 20; i++ {
+			delay := RetryDelay(n, errors.New("boom"), task)
+			if delay < base || delay > base+base/10 {
+				t.Fatalf("RetryDelay(%d) = %v, 应在 [%v, %v] 之间", n, delay, base, base+base/10)
+			}
+		}
+	}
+}
+
+// newSubscription 在测试库中创建项目、订阅和一条待投递记录
+func newSubscription(t *testing.T, d *Deliverer, url, secret string) (*model.WebhookSubscription, *model.WebhookDelivery) {
+	t.Helper()
+	project := model.Project{Name: "webhook-test-" + strconv.FormatInt(time.Now().UnixNano(), 10)}
+	if err := d.DB.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	sub := model.WebhookSubscription{ProjectID: project.ID, Name: "test", URL: url, Secret: secret, IsEnabled: true}
+	if err := d.DB.Create(&sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := model.WebhookDelivery{
+		ProjectID:      project.ID,
+		SubscriptionID: sub.ID,
+		EventID:        "evt-1",
+		Event:          EventAssetNew,
+		Payload:        []byte(`{"event":"asset.new"}`),
+		Status:         model.DeliveryStatusPending,
+	}
+	if err := d.DB.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &sub, &delivery
+}
+
+func reloadDelivery(t *testing.T, d *Deliverer, id uint) model.WebhookDelivery {
+	t.Helper()
+	var delivery model.WebhookDelivery
+	if err := d.DB.First(&delivery, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return delivery
+}
+
+func TestDeliverUpdatesDeliveryStatus(t *testing.T) {
+	db := testutil.DB(t)
+	cases := []struct {
+		name        string
+		status      int
+		lastAttempt bool
+		wantStatus  string
+		skipRetry   bool
+	}{
+		{"成功", http.StatusOK, false, model.DeliveryStatusSuccess, false},
+		{"5xx 等待重试", http.StatusInternalServerError, false, model.DeliveryStatusRetrying, false},
+		{"429 等待重试", http.StatusTooManyRequests, false, model.DeliveryStatusRetrying, false},
+		{"4xx 不重试", http.StatusBadRequest, false, model.DeliveryStatusFailed, true},
+		{"重试耗尽", http.StatusInternalServerError, true, model.DeliveryStatusFailed, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, server := newReceiver(t, "s3cret", tc.status)
+			d := NewDeliverer(db)
+			_, delivery := newSubscription(t, d, server.URL, "s3cret")
+
+			err := d.deliver(context.Background(), delivery.ID, tc.lastAttempt)
+			if tc.status == http.StatusOK {
+				if err != nil {
+					t.Fatalf("投递成功时不应返回错误: %v", err)
+				}
+			} else {
+				if err == nil {
+					t.Fatal("投递失败时应返回错误")
+				}
+				if got := errors.Is(err, asynq.SkipRetry); got != tc.skipRetry {
+					t.Errorf("SkipRetry = %v, want %v (err: %v)", got, tc.skipRetry, err)
+				}
+			}
+
+			saved := reloadDelivery(t, d, delivery.ID)
+			if saved.Status != tc.wantStatus {
+				t.Errorf("Status = %q, want %q", saved.Status, tc.wantStatus)
+			}
+			if saved.Attempts != 1 || saved.ResponseCode != tc.status {
+				t.Errorf("Attempts = %d, ResponseCode = %d", saved.Attempts, saved.ResponseCode)
+			}
+			if (saved.DeliveredAt != nil) != (tc.status == http.StatusOK) {
+				t.Errorf("DeliveredAt = %v", saved.DeliveredAt)
+			}
+			if tc.status != http.StatusOK && saved.Error == "" {
+				t.Error("失败时应记录错误信息")
+			}
+		})
+	}
+}
+
+func TestDeliverRetriesUntilSuccess(t *testing.T) {
+	db := testutil.DB(t)
+	r, server := newReceiver(t, "s3cret", http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent)
+	d := NewDeliverer(db)
+	_, delivery := newSubscription(t, d, server.URL, "s3cret")
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		err := d.deliver(context.Background(), delivery.ID, false)
+		saved := reloadDelivery(t, d, delivery.ID)
+		if saved.Attempts != attempt {
+			t.Fatalf("第 %d 次投递后 Attempts = %d", attempt, saved.Attempts)
+		}
+		if attempt < 3 {
+			if err == nil || errors.Is(err, asynq.SkipRetry) || saved.Status != model.DeliveryStatusRetrying {
+				t.Fatalf("第 %d 次投递应等待重试: status=%q err=%v", attempt, saved.Status, err)
+			}
+			continue
+		}
+		if err != nil || saved.Status != model.DeliveryStatusSuccess || saved.Error != "" {
+			t.Fatalf("第 3 次投递应成功: status=%q error=%q err=%v", saved.Status, saved.Error, err)
+		}
+	}
+	if r.count() != 3 {
+		t.Errorf("请求次数 = %d, want 3", r.count())
+	}
+}
+
+func TestDeliverDeletedSubscription(t *testing.T) {
+	db := testutil.DB(t)
+	d := NewDeliverer(db)
+	sub, delivery := newSubscription(t, d, "http://127.0.0.1:1", "")
+	if err := db.Delete(sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := d.deliver(context.Background(), delivery.ID, false); err != nil {
+		t.Fatalf("订阅已删除时不应重试: %v", err)
+	}
+	if saved := reloadDelivery(t, d, delivery.ID); saved.Status != model.DeliveryStatusFailed || saved.Attempts != 0 {
+		t.Errorf("Status = %q, Attempts = %d", saved.Status, saved.Attempts)
+	}
+}
+
+func TestSendTestRecordsSignedDelivery(t *testing.T) {
+	db := testutil.DB(t)
+	r, server := newReceiver(t, "s3cret", http.StatusOK)
+	d := NewDeliverer(db)
+	sub, _ := newSubscription(t, d, server.URL, "s3cret")
+
+	delivery, err := d.SendTest(context.Background(), sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.count() != 1 {
+		t.Fatalf("请求次数 = %d, want 1", r.count())
+	}
+	req, _ := r.request(0)
+	if got := req.Header.Get(HeaderEvent); got != EventTest {
+		t.Errorf("%s = %q, want %q", HeaderEvent, got, EventTest)
+	}
+	if got := req.Header.Get(HeaderDelivery); got != strconv.FormatUint(uint64(delivery.ID), 10) {
+		t.Errorf("%s = %q, want %d", HeaderDelivery, got, delivery.ID)
+	}
+	saved := reloadDelivery(t, d, delivery.ID)
+	if saved.Status != model.DeliveryStatusSuccess || saved.Attempts != 1 || saved.DeliveredAt == nil {
+		t.Errorf("测试投递未记录为成功: status=%q attempts=%d", saved.Status, saved.Attempts)
+	}
+}
+
+func TestSendTestRecordsFailure(t *testing.T) {
+	db := testutil.DB(t)
+	_, server := newReceiver(t, "", http.StatusForbidden)
+	d := NewDeliverer(db)
+	sub, _ := newSubscription(t, d, server.URL, "")
+
+	delivery, err := d.SendTest(context.Background(), sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := reloadDelivery(t, d, delivery.ID)
+	if saved.Status != model.DeliveryStatusFailed || saved.ResponseCode != http.StatusForbidden || saved.Error == "" {
+		t.Errorf("status=%q code=%d error=%q", saved.Status, saved.ResponseCode, saved.Error)
+	}
+}
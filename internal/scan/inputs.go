@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/api/validator"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"net"
+	"slices"
+)
+
+// maxExpandedInputs 限制单次扫描中CIDR展开后的IP总数
+const maxExpandedInputs = 65536
+
+// Input 是派发给工作流起始步骤的一个输入，以及它来源的项目目标
+type Input struct {
+	Value    string `json:"value"`
+	TargetID uint   `json:"targetId,omitempty"`
+}
+
+// resolveScanInputs 将请求中的原始输入和项目目标合并为起始步骤的输入列表。
+// CIDR 目标会根据起始步骤的 CIDRMode 展开为IP或原样保留，不被起始步骤接受的类型会被跳过。
+func resolveScanInputs(tx *gorm.DB, req *Request, firstStep model.WorkflowStep) ([]Input, error) {
+	if req.UseActiveTargets && len(req.TargetIDs) > 0 {
+		return nil, fmt.Errorf("%w: useActiveTargets 与 targetIds 不能同时指定", ErrInvalidInput)
+	}
+
+	var targets []model.ProjectTarget
+	switch {
+	case req.UseActiveTargets:
+		if err := tx.Where("project_id = ? AND is_active = ?", req.ProjectID, true).Order("id").Find(&targets).Error; err != nil {
+			return nil, err
+		}
+	case len(req.TargetIDs) > 0:
+		if err := tx.Where("project_id = ? AND id IN ?", req.ProjectID, req.TargetIDs).Order("id").Find(&targets).Error; err != nil {
+			return nil, err
+		}
+		if len(targets) != len(slices.Compact(slices.Sorted(slices.Values(req.TargetIDs)))) {
+			return nil, fmt.Errorf("%w: 部分目标ID不存在或不属于项目 %d", ErrInvalidInput, req.ProjectID)
+		}
+	}
+
+	// 原始输入如果恰好是已登记的目标，也将其归因到该目标
+	if len(req.InitialInputs) > 0 {
+		var known []model.ProjectTarget
+		normalized := make([]string, 0, len(req.InitialInputs))
+		for _, input := range req.InitialInputs {
+			normalized = append(normalized, validator.NormalizeTargetValue(input))
+		}
+		if err := tx.Where("project_id = ? AND value IN ?", req.ProjectID, normalized).Find(&known).Error; err != nil {
+			return nil, err
+		}
+		byValue := make(map[string]model.ProjectTarget, len(known))
+		for _, t := range known {
+			byValue[t.Value] = t
+		}
+		for i, input := range req.InitialInputs {
+			if t, ok := byValue[normalized[i]]; ok {
+				targets = append(targets, t)
+				continue
+			}
+			// 未登记的原始输入按原样使用，只在能识别类型时做类型过滤
+			targetType, _ := validator.DetectTargetType(normalized[i])
+			targets = append(targets, model.ProjectTarget{Value: input, Type: targetType})
+		}
+	}
+
+	var inputs []Input
+	seen := make(map[string]bool)
+	add := func(value string, targetID uint) error {
+		if seen[value] {
+			return nil
+		}
+		if len(inputs) >= maxExpandedInputs {
+			return fmt.Errorf("%w: 扫描输入数量超过上限 %d", ErrInvalidInput, maxExpandedInputs)
+		}
+		seen[value] = true
+		inputs = append(inputs, Input{Value: value, TargetID: targetID})
+		return nil
+	}
+
+	for _, t := range targets {
+		if !stepAcceptsType(firstStep, t.Type) {
+			// CIDR 展开后的IP也可以被只接受 ip 的步骤使用
+			if !(t.Type == "cidr" && firstStep.CIDRMode != "keep" && stepAcceptsType(firstStep, "ip")) {
+				continue
+			}
+		}
+		if t.Type != "cidr" || firstStep.CIDRMode == "keep" {
+			if err := add(t.Value, t.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ips, err := expandCIDR(t.Value, maxExpandedInputs-len(inputs))
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if err := add(ip, t.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: 没有可用于起始步骤 '%s' 的输入，请提供 initialInputs、targetIds 或启用 useActiveTargets", ErrInvalidInput, firstStep.Name)
+	}
+	return inputs, nil
+}
+
+// stepAcceptsType 判断步骤是否接受某种类型的目标，未声明 AcceptTypes 或类型未知时总是接受
+func stepAcceptsType(step model.WorkflowStep, targetType string) bool {
+	if len(step.AcceptTypes) == 0 || targetType == "" {
+		return true
+	}
+	return slices.Contains(step.AcceptTypes, targetType)
+}
+
+// expandCIDR 将CIDR展开为其中的主机地址。
+// 对于 IPv4 且前缀小于31的网段，会排除网络地址和广播地址。
+func expandCIDR(cidr string, limit int) ([]string, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' 不是一个合法的CIDR地址块", ErrInvalidInput, cidr)
+	}
+	ones, bits := ipNet.Mask.Size()
+	hostBits := bits - ones
+	if hostBits >= 31 || 1<<hostBits > limit {
+		return nil, fmt.Errorf("%w: CIDR '%s' 展开后的地址数量超过上限 %d，请在起始步骤中设置 cidr_mode 为 keep", ErrInvalidInput, cidr, limit)
+	}
+
+	isV4 := ip.To4() != nil
+	current := ipNet.IP.Mask(ipNet.Mask)
+	if isV4 {
+		current = current.To4()
+	}
+	total := 1 << hostBits
+	ips := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		skip := isV4 && hostBits >= 2 && (i == 0 || i == total-1)
+		if !skip {
+			ips = append(ips, current.String())
+		}
+		current = nextIP(current)
+	}
+	return ips, nil
+}
+
+// nextIP 返回 ip 的下一个地址
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
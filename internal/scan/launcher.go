@@ -0,0 +1,138 @@
+package scan
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+// 发起扫描时可直接反馈给用户的业务错误
+var (
+	ErrProjectNotFound = errors.New("项目不存在")
+	ErrProjectInactive = errors.New("项目已归档或正在删除，无法发起新的扫描")
+	ErrProfileNotFound = errors.New("扫描模板不存在")
+	ErrInvalidInput    = errors.New("扫描输入不合法")
+)
+
+// IsUserError 判断错误是否为可以直接反馈给用户的业务错误
+func IsUserError(err error) bool {
+	return errors.Is(err, ErrProjectNotFound) || errors.Is(err, ErrProjectInactive) ||
+		errors.Is(err, ErrProfileNotFound) || errors.Is(err, ErrInvalidInput)
+}
+
+// Request 描述一次扫描的扫描模板和输入来源，会随输入列表一起保存到父任务的 Payload 中
+type Request struct {
+	ProjectID     uint     `json:"projectId"`
+	ScanProfileID uint     `json:"scanProfileId"`
+	InitialInputs []string `json:"initialInputs"`
+	// UseActiveTargets 为 true 时使用项目中所有启用的目标作为输入，此时的运行为全量运行，不能与 TargetIDs 同时指定
+	UseActiveTargets bool   `json:"useActiveTargets"`
+	TargetIDs        []uint `json:"targetIds"`
+	Description      string `json:"description"`
+}
+
+// Options 是发起扫描时的附加选项
+type Options struct {
+	// ScheduleID 为触发本次扫描的定时计划ID，手动发起时为0
+	ScheduleID uint
+}
+
+// Launch 创建代表整个工作流的父任务，并为每个输入派发起始步骤的任务。
+// 数据库写入和任务入队在同一个事务中完成，入队失败时父任务会被回滚。
+func Launch(db *gorm.DB, client *asynq.Client, req *Request, opts Options) (*model.Task, error) {
+	var profile model.ScanProfile
+	var parentTask model.Task
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 0. 验证项目存在且处于活跃状态，归档或删除中的项目拒绝新的扫描
+		var project model.Project
+		if err := tx.First(&project, req.ProjectID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		if project.Status != model.ProjectStatusActive {
+			return ErrProjectInactive
+		}
+
+		// 1. 获取并验证扫描模板
+		if err := tx.First(&profile, req.ScanProfileID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProfileNotFound
+			}
+			return err
+		}
+
+		// 2. 找到工作流的第一步
+		firstStep, ok := findFirstStep(profile.WorkflowSteps)
+		if !ok {
+			return errors.New("无法在工作流中找到起始步骤 (input_from: 'initial')")
+		}
+
+		// 3. 合并原始输入和项目目标，得到起始步骤的输入列表
+		inputs, err := resolveScanInputs(tx, req, firstStep)
+		if err != nil {
+			return err
+		}
+
+		// 4. 将完整的请求体和解析后的输入序列化为JSON，作为父任务的Payload
+		payloadBytes, err := json.Marshal(struct {
+			Request
+			Inputs []Input `json:"inputs"`
+		}{*req, inputs})
+		if err != nil {
+			// 如果gin可以绑定成功，这里序列化通常不会失败，但做好防御是好习惯
+			return errors.New("无法序列化请求为Payload")
+		}
+
+		// 创建父任务，代表整个工作流
+		parentTask = model.Task{
+			ProjectID:       req.ProjectID,
+			ScanProfileID:   req.ScanProfileID,
+			ScanScheduleID:  opts.ScheduleID,
+			IsFullRun:       req.UseActiveTargets,
+			Type:            "workflow",
+			Status:          "pending",
+			Payload:         payloadBytes, // 存入序列化后的 []byte
+			PendingSubtasks: len(inputs),
+		}
+		if err := tx.Create(&parentTask).Error; err != nil {
+			return err
+		}
+
+		// 5. 为每个输入派发第一步的任务，并携带来源目标ID以便按目标归因结果
+		for _, input := range inputs {
+			taskPayload, _ := json.Marshal(map[string]interface{}{
+				"parent_task_id":    parentTask.ID,
+				"scan_profile_id":   profile.ID,
+				"current_step_name": firstStep.Name,
+				"input":             input.Value,
+				"project_id":        parentTask.ProjectID,
+				"target_id":         input.TargetID,
+			})
+
+			task := asynq.NewTask(firstStep.TaskType, taskPayload)
+			if _, err := client.Enqueue(task, asynq.Queue("default")); err != nil { // 明确指定队列
+				return err // 事务会回滚
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &parentTask, nil
+}
+
+func findFirstStep(steps []model.WorkflowStep) (model.WorkflowStep, bool) {
+	for _, step := range steps {
+		if step.InputFrom == "initial" {
+			return step, true
+		}
+	}
+	return model.WorkflowStep{}, false
+}
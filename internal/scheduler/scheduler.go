@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/robfig/cron/v3"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/scan"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// leaderKey 是调度器选主使用的 Redis 键
+	leaderKey = "src-hunter:scheduler:leader"
+	// 上一次运行超过 staleRunTimeout 没有任何步骤活动时视为已失效 (如 worker 中途退出)，不再阻止新的运行
+	staleRunTimeout = 24 * time.Hour
+)
+
+// 上一次触发的结果
+const (
+	RunStatusLaunched = "launched"
+	RunStatusSkipped  = "skipped"
+	RunStatusFailed   = "failed"
+)
+
+// renewScript 仅当锁仍由自己持有时才续期
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript 仅当锁仍由自己持有时才释放
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Parse 解析计划的 cron 表达式，支持标准5段格式、@daily 等描述符和 CRON_TZ= 前缀
+func Parse(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, fmt.Errorf("无效的cron表达式 '%s': %w", expr, err)
+	}
+	return schedule, nil
+}
+
+// NextRun 计算 cron 表达式在 from 之后的下一次执行时间
+func NextRun(expr string, from time.Time) (time.Time, error) {
+	schedule, err := Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(from), nil
+}
+
+// Scheduler 周期性地检查到期的扫描计划并派发工作流。
+// 多个 web 实例同时运行时，通过 Redis 锁选出唯一的主实例负责派发；
+// 每次派发前还会以 next_run_at 为条件在数据库中认领计划，保证同一次计划只会被触发一次。
+type Scheduler struct {
+	db       *gorm.DB
+	client   *asynq.Client
+	rdb      *redis.Client
+	id       string
+	interval time.Duration
+}
+
+func NewScheduler(db *gorm.DB, client *asynq.Client, rdb *redis.Client, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		db:       db,
+		client:   client,
+		rdb:      rdb,
+		id:       fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		interval: interval,
+	}
+}
+
+// Run 阻塞运行调度循环，直到 ctx 被取消
+func (s *Scheduler) Run(ctx context.Context) {
+	logger.Logger.Info("定时扫描调度器已启动", zap.String("instance", s.id), zap.Duration("interval", s.interval))
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.release()
+
+	for {
+		if s.acquireLeadership(ctx) {
+			s.runDueSchedules(ctx)
+		}
+		select {
+		case <-ctx.Done():
+			logger.Logger.Info("定时扫描调度器已停止", zap.String("instance", s.id))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquireLeadership 尝试成为主实例或为已持有的锁续期
+func (s *Scheduler) acquireLeadership(ctx context.Context) bool {
+	ttl := 3 * s.interval
+	ok, err := s.rdb.SetNX(ctx, leaderKey, s.id, ttl).Result()
+	if err != nil {
+		logger.Logger.Warn("调度器选主失败", zap.Error(err))
+		return false
+	}
+	if ok {
+		logger.Logger.Info("当前实例成为调度主实例", zap.String("instance", s.id))
+		return true
+	}
+	renewed, err := renewScript.Run(ctx, s.rdb, []string{leaderKey}, s.id, ttl.Milliseconds()).Int()
+	if err != nil {
+		logger.Logger.Warn("调度器续期失败", zap.Error(err))
+		return false
+	}
+	return renewed == 1
+}
+
+func (s *Scheduler) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := releaseScript.Run(ctx, s.rdb, []string{leaderKey}, s.id).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		logger.Logger.Warn("释放调度器锁失败", zap.Error(err))
+	}
+}
+
+func (s *Scheduler) runDueSchedules(ctx context.Context) {
+	now := time.Now()
+	var schedules []model.ScanSchedule
+	if err := s.db.WithContext(ctx).
+		Where("is_enabled = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at").
+		Find(&schedules).Error; err != nil {
+		logger.Logger.Error("查询到期的扫描计划失败", zap.Error(err))
+		return
+	}
+	for i := range schedules {
+		if ctx.Err() != nil {
+			return
+		}
+		s.trigger(ctx, &schedules[i], now)
+	}
+}
+
+// trigger 认领并执行一次到期的计划
+func (s *Scheduler) trigger(ctx context.Context, schedule *model.ScanSchedule, now time.Time) {
+	next, err := NextRun(schedule.CronExpr, now)
+	if err != nil {
+		// 表达式在保存时已校验，这里只可能是历史脏数据，停用该计划避免反复报错
+		s.db.Model(schedule).Updates(map[string]interface{}{
+			"is_enabled":       false,
+			"last_run_status":  RunStatusFailed,
+			"last_run_message": err.Error(),
+		})
+		return
+	}
+
+	// 以旧的 next_run_at 为条件认领计划，其他实例或重复轮询不会再次触发
+	claim := s.db.WithContext(ctx).Model(&model.ScanSchedule{}).
+		Where("id = ? AND next_run_at = ?", schedule.ID, schedule.NextRunAt).
+		Updates(map[string]interface{}{"next_run_at": next, "last_run_at": now})
+	if claim.Error != nil {
+		logger.Logger.Error("认领扫描计划失败", zap.Uint("schedule_id", schedule.ID), zap.Error(claim.Error))
+		return
+	}
+	if claim.RowsAffected == 0 {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if running, err := s.previousRunActive(schedule, now); err != nil {
+		updates["last_run_status"] = RunStatusFailed
+		updates["last_run_message"] = fmt.Sprintf("检查上一次运行状态失败: %v", err)
+	} else if running {
+		updates["last_run_status"] = RunStatusSkipped
+		updates["last_run_message"] = fmt.Sprintf("上一次运行 (任务 %d) 尚未结束，跳过本次触发", schedule.LastTaskID)
+		logger.Logger.Info("上一次运行尚未结束，跳过扫描计划", zap.Uint("schedule_id", schedule.ID), zap.Uint("last_task_id", schedule.LastTaskID))
+	} else {
+		req := scan.Request{
+			ProjectID:        schedule.ProjectID,
+			ScanProfileID:    schedule.ScanProfileID,
+			UseActiveTargets: schedule.UseActiveTargets,
+			TargetIDs:        schedule.TargetIDs,
+			Description:      fmt.Sprintf("定时计划 #%d: %s", schedule.ID, schedule.Name),
+		}
+		task, err := scan.Launch(s.db, s.client, &req, scan.Options{ScheduleID: schedule.ID})
+		if err != nil {
+			updates["last_run_status"] = RunStatusFailed
+			updates["last_run_message"] = err.Error()
+			logger.Logger.Warn("扫描计划触发失败", zap.Uint("schedule_id", schedule.ID), zap.Error(err))
+		} else {
+			updates["last_run_status"] = RunStatusLaunched
+			updates["last_run_message"] = ""
+			updates["last_task_id"] = task.ID
+			logger.Logger.Info("扫描计划已触发", zap.Uint("schedule_id", schedule.ID), zap.Uint("task_id", task.ID))
+		}
+	}
+	if err := s.db.Model(&model.ScanSchedule{}).Where("id = ?", schedule.ID).Updates(updates).Error; err != nil {
+		logger.Logger.Error("更新扫描计划运行状态失败", zap.Uint("schedule_id", schedule.ID), zap.Error(err))
+	}
+}
+
+// runActivitySQL 查询一次运行中所有任务 (含扇出的各级子任务) 最近一次更新的时间
+const runActivitySQL = `WITH RECURSIVE run AS (
+	SELECT id, updated_at FROM tasks WHERE id = ?
+	UNION ALL
+	SELECT t.id, t.updated_at FROM tasks t JOIN run ON t.parent_task_id = run.id
+)
+SELECT MAX(updated_at) FROM run`
+
+// previousRunActive 判断计划的上一次运行是否仍在进行中。
+// 工作流顶级任务在所有分支结束或任一步骤失败时才离开 pending 状态，
+// 仍有未结束的分支 (pending_subtasks > 0) 且最近有步骤活动的运行才视为进行中
+func (s *Scheduler) previousRunActive(schedule *model.ScanSchedule, now time.Time) (bool, error) {
+	if schedule.LastTaskID == 0 {
+		return false, nil
+	}
+	var task model.Task
+	if err := s.db.Select("id", "status", "pending_subtasks").First(&task, schedule.LastTaskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if (task.Status != "pending" && task.Status != "running") || task.PendingSubtasks <= 0 {
+		return false, nil
+	}
+	var lastActivity time.Time
+	if err := s.db.Raw(runActivitySQL, task.ID).Row().Scan(&lastActivity); err != nil {
+		return false, err
+	}
+	return now.Sub(lastActivity) < staleRunTimeout, nil
+}
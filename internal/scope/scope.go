@@ -0,0 +1,315 @@
+package scope
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+)
+
+// 规则动作
+const (
+	ActionInclude = "include"
+	ActionExclude = "exclude"
+)
+
+// 规则类型
+const (
+	RuleDomain = "domain" // 域名，支持 *.example.com 通配符；不带通配符时同时匹配其所有子域名
+	RuleIP     = "ip"     // 单个IP
+	RuleCIDR   = "cidr"   // IP地址块
+	RuleRegex  = "regex"  // 对域名或IP做正则匹配
+	RulePort   = "port"   // 端口或端口范围，逗号分隔，如 80,443,8000-9000
+)
+
+// Decision 是一次范围判定的结果
+type Decision struct {
+	InScope bool
+	Reason  string
+}
+
+func inScope() Decision {
+	return Decision{InScope: true}
+}
+
+func outOfScope(format string, args ...interface{}) Decision {
+	return Decision{InScope: false, Reason: fmt.Sprintf(format, args...)}
+}
+
+type matcher struct {
+	ruleType string
+	value    string
+	source   string // 规则来源说明，用于生成可读的原因
+	domain   string // RuleDomain: 去掉通配符后的域名
+	wildcard bool   // RuleDomain: 是否为 *. 前缀
+	ip       net.IP
+	ipNet    *net.IPNet
+	re       *regexp.Regexp
+	ports    [][2]int
+}
+
+// Scope 是编译后的项目范围，包含由项目目标和显式规则组成的包含/排除列表
+type Scope struct {
+	includes []*matcher
+	excludes []*matcher
+}
+
+// Load 从数据库加载项目的所有目标和范围规则并编译为 Scope
+func Load(db *gorm.DB, projectID uint) (*Scope, error) {
+	var targets []model.ProjectTarget
+	if err := db.Where("project_id = ?", projectID).Find(&targets).Error; err != nil {
+		return nil, fmt.Errorf("加载项目目标失败: %w", err)
+	}
+	var rules []model.ScopeRule
+	if err := db.Where("project_id = ?", projectID).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("加载范围规则失败: %w", err)
+	}
+	return New(targets, rules)
+}
+
+// New 由项目目标和范围规则编译出 Scope。项目目标视为包含规则。
+func New(targets []model.ProjectTarget, rules []model.ScopeRule) (*Scope, error) {
+	s := &Scope{}
+	for _, t := range targets {
+		m, err := compileMatcher(t.Type, t.Value)
+		if err != nil {
+			return nil, fmt.Errorf("项目目标 '%s' 无效: %w", t.Value, err)
+		}
+		m.source = fmt.Sprintf("目标 %s", t.Value)
+		s.includes = append(s.includes, m)
+	}
+	for _, r := range rules {
+		m, err := compileMatcher(r.Type, r.Value)
+		if err != nil {
+			return nil, fmt.Errorf("范围规则 #%d 无效: %w", r.ID, err)
+		}
+		m.source = fmt.Sprintf("规则 #%d (%s %s:%s)", r.ID, r.Action, r.Type, r.Value)
+		if r.Action == ActionExclude {
+			s.excludes = append(s.excludes, m)
+		} else {
+			s.includes = append(s.includes, m)
+		}
+	}
+	return s, nil
+}
+
+// ValidateRule 校验一条范围规则的动作、类型和值是否合法
+func ValidateRule(action, ruleType, value string) error {
+	if action != ActionInclude && action != ActionExclude {
+		return fmt.Errorf("不支持的规则动作 '%s'", action)
+	}
+	_, err := compileMatcher(ruleType, value)
+	return err
+}
+
+func compileMatcher(ruleType, value string) (*matcher, error) {
+	value = strings.TrimSpace(value)
+	m := &matcher{ruleType: ruleType, value: value}
+	switch ruleType {
+	case RuleDomain:
+		d := strings.TrimSuffix(strings.ToLower(value), ".")
+		if strings.HasPrefix(d, "*.") {
+			m.wildcard = true
+			d = strings.TrimPrefix(d, "*.")
+		}
+		if d == "" || strings.Contains(d, "*") {
+			return nil, fmt.Errorf("'%s' 不是合法的域名规则，只支持 *. 前缀通配符", value)
+		}
+		m.domain = d
+	case RuleIP:
+		if m.ip = net.ParseIP(value); m.ip == nil {
+			return nil, fmt.Errorf("'%s' 不是一个合法的IP地址", value)
+		}
+	case RuleCIDR:
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' 不是一个合法的CIDR地址块", value)
+		}
+		m.ipNet = ipNet
+	case RuleRegex:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式 '%s' 无效: %w", value, err)
+		}
+		m.re = re
+	case RulePort:
+		ports, err := parsePortRanges(value)
+		if err != nil {
+			return nil, err
+		}
+		m.ports = ports
+	default:
+		return nil, fmt.Errorf("不支持的规则类型 '%s'", ruleType)
+	}
+	return m, nil
+}
+
+func parsePortRanges(value string) ([][2]int, error) {
+	var ranges [][2]int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		to := from
+		var err2 error
+		if isRange {
+			to, err2 = strconv.Atoi(strings.TrimSpace(hi))
+		}
+		if err1 != nil || err2 != nil || from < 0 || to > 65535 || from > to {
+			return nil, fmt.Errorf("'%s' 不是合法的端口或端口范围", part)
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("端口规则不能为空")
+	}
+	return ranges, nil
+}
+
+func (m *matcher) matchHost(host string) bool {
+	switch m.ruleType {
+	case RuleDomain:
+		if m.wildcard {
+			return strings.HasSuffix(host, "."+m.domain)
+		}
+		return host == m.domain || strings.HasSuffix(host, "."+m.domain)
+	case RuleIP:
+		ip := net.ParseIP(host)
+		return ip != nil && ip.Equal(m.ip)
+	case RuleCIDR:
+		ip := net.ParseIP(host)
+		if ip != nil {
+			return m.ipNet.Contains(ip)
+		}
+		// 输入本身是一个CIDR时，要求它完全落在规则网段内
+		_, other, err := net.ParseCIDR(host)
+		if err != nil {
+			return false
+		}
+		otherOnes, _ := other.Mask.Size()
+		ones, _ := m.ipNet.Mask.Size()
+		return otherOnes >= ones && m.ipNet.Contains(other.IP)
+	case RuleRegex:
+		return m.re.MatchString(host)
+	}
+	return false
+}
+
+func (m *matcher) matchPort(port int) bool {
+	for _, r := range m.ports {
+		if port >= r[0] && port <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesToHost 判断规则是否可以用于判定此类主机 (域名规则不适用于IP，IP规则不适用于域名)
+func (m *matcher) appliesToHost(isIP bool) bool {
+	switch m.ruleType {
+	case RuleDomain:
+		return !isIP
+	case RuleIP, RuleCIDR:
+		return isIP
+	case RuleRegex:
+		return true
+	}
+	return false
+}
+
+// Enabled 判断项目是否配置了任何范围约束；没有目标和规则的项目不做限制
+func (s *Scope) Enabled() bool {
+	return s != nil && (len(s.includes) > 0 || len(s.excludes) > 0)
+}
+
+// CheckHost 判断一个域名、IP或CIDR是否在范围内。
+// 排除规则优先；只要存在主机类的包含规则 (含项目目标)，就必须命中其中一条适用的规则。
+func (s *Scope) CheckHost(host string) Decision {
+	if !s.Enabled() {
+		return inScope()
+	}
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" {
+		return outOfScope("主机为空")
+	}
+	isIP := net.ParseIP(host) != nil || strings.Contains(host, "/")
+
+	for _, m := range s.excludes {
+		if m.appliesToHost(isIP) && m.matchHost(host) {
+			return outOfScope("命中排除%s", m.source)
+		}
+	}
+
+	hostIncludes := 0
+	for _, m := range s.includes {
+		if m.ruleType == RulePort {
+			continue
+		}
+		hostIncludes++
+		if m.appliesToHost(isIP) && m.matchHost(host) {
+			return inScope()
+		}
+	}
+	if hostIncludes == 0 {
+		return inScope()
+	}
+	return outOfScope("'%s' 不在任何项目目标或包含规则的范围内", host)
+}
+
+// CheckPort 判断端口是否被端口规则允许
+func (s *Scope) CheckPort(port int) Decision {
+	if !s.Enabled() || port == 0 {
+		return inScope()
+	}
+	for _, m := range s.excludes {
+		if m.ruleType == RulePort && m.matchPort(port) {
+			return outOfScope("端口 %d 命中排除%s", port, m.source)
+		}
+	}
+	portIncludes := 0
+	for _, m := range s.includes {
+		if m.ruleType != RulePort {
+			continue
+		}
+		portIncludes++
+		if m.matchPort(port) {
+			return inScope()
+		}
+	}
+	if portIncludes == 0 {
+		return inScope()
+	}
+	return outOfScope("端口 %d 不在允许的端口范围内", port)
+}
+
+// CheckAsset 判断资产 (IP+端口) 是否在范围内。
+// viaDomain 为发现该资产的域名 (可为空)：经由范围内域名解析到的IP视为在范围内，
+// 除非该IP被排除规则显式排除。
+func (s *Scope) CheckAsset(ip string, port int, viaDomain string) Decision {
+	if !s.Enabled() {
+		return inScope()
+	}
+	if d := s.CheckPort(port); !d.InScope {
+		return d
+	}
+	d := s.CheckHost(ip)
+	if d.InScope || viaDomain == "" {
+		return d
+	}
+	for _, m := range s.excludes {
+		if m.appliesToHost(true) && m.matchHost(ip) {
+			return d
+		}
+	}
+	if s.CheckHost(viaDomain).InScope {
+		return inScope()
+	}
+	return d
+}
@@ -0,0 +1,265 @@
+package search
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Condition 是编译后的SQL条件，可以直接传给 gorm 的 Where(SQL, Args...)
+type Condition struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Compile 解析查询语句并编译为针对指定实体的SQL条件。
+// 所有用户输入都通过占位符传递，列名只来自字段白名单。
+func Compile(query string, entity Entity) (*Condition, error) {
+	if entity != EntityAsset && entity != EntityDomain {
+		return nil, fmt.Errorf("不支持的搜索实体 '%s'", entity)
+	}
+	root, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{entity: entity}
+	sql, err := c.compile(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Condition{SQL: sql, Args: c.args}, nil
+}
+
+type compiler struct {
+	entity Entity
+	args   []interface{}
+}
+
+func (c *compiler) compile(n Node) (string, error) {
+	switch n := n.(type) {
+	case *AndNode:
+		return c.compileGroup(n.Children, " AND ")
+	case *OrNode:
+		return c.compileGroup(n.Children, " OR ")
+	case *NotNode:
+		inner, err := c.compile(n.Child)
+		if err != nil {
+			return "", err
+		}
+		// COALESCE 保证 NULL 列取反后也能被正确排除/包含
+		return fmt.Sprintf("NOT COALESCE((%s), false)", inner), nil
+	case *TermNode:
+		return c.compileTerm(n)
+	default:
+		return "", fmt.Errorf("未知的语法节点 %T", n)
+	}
+}
+
+func (c *compiler) compileGroup(children []Node, op string) (string, error) {
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		part, err := c.compile(child)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, op) + ")", nil
+}
+
+func (c *compiler) compileTerm(t *TermNode) (string, error) {
+	if t.Field == "" {
+		return c.compileFreeText(t)
+	}
+	f, ok := lookupField(t.Field)
+	if !ok {
+		msg := fmt.Sprintf("未知字段 '%s'", t.Field)
+		if s := suggestField(t.Field); s != "" {
+			msg += fmt.Sprintf("，是否想输入 '%s'?", s)
+		}
+		return "", &SyntaxError{Pos: t.Pos, Msg: msg}
+	}
+	return c.compileField(f, t)
+}
+
+// compileFreeText 处理没有字段名的条件: 域名、标题或IP中任一匹配即可
+func (c *compiler) compileFreeText(t *TermNode) (string, error) {
+	candidates := []string{"domain"}
+	if c.entity == EntityAsset {
+		candidates = append(candidates, "title", "ip")
+	}
+	var parts []string
+	for _, name := range candidates {
+		f, _ := lookupField(name)
+		term := &TermNode{Field: f.Name, Value: t.Value, Quoted: t.Quoted, Pos: t.Pos}
+		if f.Type == FieldIP && !strings.Contains(t.Value, "*") && net.ParseIP(t.Value) == nil {
+			continue
+		}
+		part, err := c.compileField(&Field{
+			Name:   f.Name,
+			Type:   f.Type,
+			source: f.source,
+			column: f.column,
+			match:  matchContains,
+		}, term)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", nil
+}
+
+func (c *compiler) compileField(f *Field, t *TermNode) (string, error) {
+	col := qualifier(f.source, c.entity) + "." + f.column
+	cond, err := c.fieldCondition(f, col, t)
+	if err != nil {
+		return "", err
+	}
+	return wrapSource(f.source, c.entity, cond), nil
+}
+
+func (c *compiler) fieldCondition(f *Field, col string, t *TermNode) (string, error) {
+	switch f.Type {
+	case FieldInt:
+		return c.intCondition(f, col, t)
+	case FieldIP:
+		return c.ipCondition(col, t)
+	case FieldList:
+		pattern := c.likePattern(t.Value, f.match)
+		c.args = append(c.args, pattern)
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements_text(%s) AS elem(v) WHERE elem.v ILIKE ?)", col), nil
+	default:
+		return c.textCondition(f, col, t), nil
+	}
+}
+
+func (c *compiler) textCondition(f *Field, col string, t *TermNode) string {
+	if !strings.Contains(t.Value, "*") && f.match == matchExact {
+		c.args = append(c.args, t.Value)
+		return fmt.Sprintf("LOWER(%s) = LOWER(?)", col)
+	}
+	c.args = append(c.args, c.likePattern(t.Value, f.match))
+	return fmt.Sprintf("%s ILIKE ?", col)
+}
+
+func (c *compiler) ipCondition(col string, t *TermNode) (string, error) {
+	switch {
+	case strings.Contains(t.Value, "/"):
+		_, ipNet, err := net.ParseCIDR(t.Value)
+		if err != nil {
+			return "", &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("'%s' 不是一个合法的CIDR地址块", t.Value)}
+		}
+		c.args = append(c.args, ipNet.String())
+		return fmt.Sprintf("CAST(%s AS inet) <<= CAST(? AS inet)", col), nil
+	case strings.Contains(t.Value, "*"):
+		c.args = append(c.args, c.likePattern(t.Value, matchExact))
+		return fmt.Sprintf("%s ILIKE ?", col), nil
+	default:
+		if net.ParseIP(t.Value) == nil {
+			return "", &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("'%s' 不是一个合法的IP地址", t.Value)}
+		}
+		c.args = append(c.args, t.Value)
+		return fmt.Sprintf("%s = ?", col), nil
+	}
+}
+
+func (c *compiler) intCondition(f *Field, col string, t *TermNode) (string, error) {
+	value := t.Value
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, op) {
+			n, err := parsePort(f, t, strings.TrimPrefix(value, op))
+			if err != nil {
+				return "", err
+			}
+			c.args = append(c.args, n)
+			return fmt.Sprintf("%s %s ?", col, op), nil
+		}
+	}
+	if lo, hi, found := strings.Cut(value, "-"); found {
+		from, err := parsePort(f, t, lo)
+		if err != nil {
+			return "", err
+		}
+		to, err := parsePort(f, t, hi)
+		if err != nil {
+			return "", err
+		}
+		if from > to {
+			return "", &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("字段 '%s' 的范围 '%s' 起始值大于结束值", f.Name, value)}
+		}
+		c.args = append(c.args, from, to)
+		return fmt.Sprintf("%s BETWEEN ? AND ?", col), nil
+	}
+	n, err := parsePort(f, t, value)
+	if err != nil {
+		return "", err
+	}
+	c.args = append(c.args, n)
+	return fmt.Sprintf("%s = ?", col), nil
+}
+
+func parsePort(f *Field, t *TermNode, s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 || n > 65535 {
+		return 0, &SyntaxError{Pos: t.Pos, Msg: fmt.Sprintf("字段 '%s' 的值 '%s' 不是合法的端口 (0-65535)", f.Name, t.Value)}
+	}
+	return n, nil
+}
+
+// likePattern 将用户输入转换为 ILIKE 模式: 转义 % 和 _，并把 * 替换为 %
+func (c *compiler) likePattern(value string, mode matchMode) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+	if strings.Contains(value, "*") {
+		return strings.ReplaceAll(escaped, "*", "%")
+	}
+	if mode == matchContains {
+		return "%" + escaped + "%"
+	}
+	return escaped
+}
+
+// qualifier 返回字段所在表在SQL中的引用名
+func qualifier(src source, entity Entity) string {
+	switch {
+	case src == sourceAsset && entity == EntityAsset:
+		return "assets"
+	case src == sourceDomain && entity == EntityDomain:
+		return "domains"
+	case src == sourceAsset:
+		return "a"
+	case src == sourceDomain:
+		return "d"
+	case src == sourceTag:
+		return "t"
+	default:
+		return "im"
+	}
+}
+
+// wrapSource 当字段不属于搜索实体本身时，通过 asset_domain_mappings / ip_metadata / taggings 关联子查询
+func wrapSource(src source, entity Entity, cond string) string {
+	switch {
+	case entity == EntityAsset && src == sourceDomain:
+		return "EXISTS (SELECT 1 FROM asset_domain_mappings m JOIN domains d ON d.id = m.domain_id AND d.deleted_at IS NULL " +
+			"WHERE m.asset_id = assets.id AND " + cond + ")"
+	case entity == EntityAsset && src == sourceIPMetadata:
+		return "EXISTS (SELECT 1 FROM ip_metadata im WHERE im.ip = assets.ip AND " + cond + ")"
+	case entity == EntityDomain && src == sourceAsset:
+		return "EXISTS (SELECT 1 FROM asset_domain_mappings m JOIN assets a ON a.id = m.asset_id AND a.deleted_at IS NULL " +
+			"WHERE m.domain_id = domains.id AND " + cond + ")"
+	case src == sourceTag:
+		table := "assets"
+		if entity == EntityDomain {
+			table = "domains"
+		}
+		return "EXISTS (SELECT 1 FROM taggings tg JOIN tags t ON t.id = tg.tag_id AND t.deleted_at IS NULL " +
+			"WHERE tg.entity_type = '" + string(entity) + "' AND tg.entity_id = " + table + ".id AND " + cond + ")"
+	case entity == EntityDomain && src == sourceIPMetadata:
+		return "EXISTS (SELECT 1 FROM asset_domain_mappings m JOIN assets a ON a.id = m.asset_id AND a.deleted_at IS NULL " +
+			"JOIN ip_metadata im ON im.ip = a.ip WHERE m.domain_id = domains.id AND " + cond + ")"
+	default:
+		return cond
+	}
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		query  string
+		entity Entity
+		sql    string
+		args   []interface{}
+	}{
+		{"port:443", EntityAsset, "assets.port = ?", []interface{}{443}},
+		{"port:8000-9000", EntityAsset, "assets.port BETWEEN ? AND ?", []interface{}{8000, 9000}},
+		{"port:>=1024", EntityAsset, "assets.port >= ?", []interface{}{1024}},
+		{"ip:192.0.2.1", EntityAsset, "assets.ip = ?", []interface{}{"192.0.2.1"}},
+		// CIDR 按网络地址规范化后用 inet 比较
+		{"ip:10.1.2.3/8", EntityAsset, "CAST(assets.ip AS inet) <<= CAST(? AS inet)", []interface{}{"10.0.0.0/8"}},
+		{"ip:2001:db8::/32", EntityAsset, "CAST(assets.ip AS inet) <<= CAST(? AS inet)", []interface{}{"2001:db8::/32"}},
+		{"ip:192.168.*", EntityAsset, "assets.ip ILIKE ?", []interface{}{"192.168.%"}},
+		// 通配符以外的 % 和 _ 按字面匹配
+		{"domain:*.example.com", EntityDomain, "domains.fqdn ILIKE ?", []interface{}{"%.example.com"}},
+		{"domain:www_1.example.com", EntityDomain, "LOWER(domains.fqdn) = LOWER(?)", []interface{}{"www_1.example.com"}},
+		{`title:"100%_off"`, EntityAsset, "assets.title ILIKE ?", []interface{}{`%100\%\_off%`}},
+		{"tech:nginx", EntityAsset, "EXISTS (SELECT 1 FROM jsonb_array_elements_text(assets.technologies) AS elem(v) WHERE elem.v ILIKE ?)", []interface{}{"%nginx%"}},
+		{"host:api.example.com", EntityAsset,
+			"EXISTS (SELECT 1 FROM asset_domain_mappings m JOIN domains d ON d.id = m.domain_id AND d.deleted_at IS NULL WHERE m.asset_id = assets.id AND LOWER(d.fqdn) = LOWER(?))",
+			[]interface{}{"api.example.com"}},
+		{"-server:cloudflare", EntityAsset, "NOT COALESCE((assets.web_server ILIKE ?), false)", []interface{}{"%cloudflare%"}},
+		{"(port:80 OR port:443) transport:tcp", EntityAsset, "((assets.port = ? OR assets.port = ?) AND LOWER(assets.transport) = LOWER(?))", []interface{}{80, 443, "tcp"}},
+		// 自由文本只在值像IP时才匹配IP列
+		{"admin", EntityAsset,
+			"(EXISTS (SELECT 1 FROM asset_domain_mappings m JOIN domains d ON d.id = m.domain_id AND d.deleted_at IS NULL WHERE m.asset_id = assets.id AND d.fqdn ILIKE ?) OR assets.title ILIKE ?)",
+			[]interface{}{"%admin%", "%admin%"}},
+		{"192.0.2.1", EntityDomain, "(domains.fqdn ILIKE ?)", []interface{}{"%192.0.2.1%"}},
+	}
+	for _, tt := range tests {
+		cond, err := Compile(tt.query, tt.entity)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.query, err)
+			continue
+		}
+		if cond.SQL != tt.sql {
+			t.Errorf("Compile(%q).SQL =\n  %s\nwant\n  %s", tt.query, cond.SQL, tt.sql)
+		}
+		if fmt.Sprint(cond.Args) != fmt.Sprint(tt.args) {
+			t.Errorf("Compile(%q).Args = %v, want %v", tt.query, cond.Args, tt.args)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"prot:80", 1, "是否想输入 'port'"},
+		{"tech:nginx unknown:x", 12, "未知字段 'unknown'"},
+		{"ip:10.0.0.0/33", 1, "不是一个合法的CIDR地址块"},
+		{"a ip:999.1.1.1", 3, "不是一个合法的IP地址"},
+		{"port:70000", 1, "不是合法的端口"},
+		{"port:9000-8000", 1, "起始值大于结束值"},
+		{"port:>http", 1, "不是合法的端口"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.query, EntityAsset)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) err = %v, want SyntaxError", tt.query, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) {
+			t.Errorf("Compile(%q) = 第%d个字符 %s, want 第%d个字符 %s", tt.query, syntaxErr.Pos, syntaxErr.Msg, tt.pos, tt.msg)
+		}
+	}
+
+	if _, err := Compile("port:80", Entity("endpoint")); err == nil {
+		t.Error("不支持的实体应返回错误")
+	}
+}
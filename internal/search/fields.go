@@ -0,0 +1,141 @@
+package search
+
+import "sort"
+
+// Entity 表示搜索的目标实体
+type Entity string
+
+const (
+	EntityAsset  Entity = "asset"
+	EntityDomain Entity = "domain"
+)
+
+// source 表示字段所在的数据表
+type source int
+
+const (
+	sourceAsset source = iota
+	sourceDomain
+	sourceIPMetadata
+	sourceTag
+)
+
+// FieldType 决定了字段值的解析方式和可用的匹配语法
+type FieldType string
+
+const (
+	FieldText FieldType = "text" // 文本，支持 * 通配符
+	FieldInt  FieldType = "int"  // 整数，支持 80、8000-9000、>1024
+	FieldIP   FieldType = "ip"   // IP地址，支持精确匹配、CIDR和 * 通配符
+	FieldList FieldType = "list" // JSON数组，任一元素匹配即可
+)
+
+// matchMode 决定文本字段在未使用通配符时的匹配方式
+type matchMode int
+
+const (
+	matchExact    matchMode = iota // 忽略大小写的精确匹配
+	matchContains                  // 忽略大小写的包含匹配
+)
+
+// Field 描述了一个可搜索的字段
+type Field struct {
+	Name        string    `json:"name"`
+	Aliases     []string  `json:"aliases,omitempty"`
+	Type        FieldType `json:"type"`
+	Description string    `json:"description"`
+	Example     string    `json:"example"`
+
+	source source
+	column string
+	match  matchMode
+}
+
+// fields 是所有可搜索字段的白名单，SQL中的列名只会来自这里
+var fields = []*Field{
+	{Name: "domain", Aliases: []string{"host", "fqdn"}, Type: FieldText, Description: "完整域名", Example: "domain:*.example.com", source: sourceDomain, column: "fqdn", match: matchExact},
+	{Name: "root", Aliases: []string{"rootdomain"}, Type: FieldText, Description: "根域名", Example: "root:example.com", source: sourceDomain, column: "root_domain", match: matchExact},
+	{Name: "ip", Type: FieldIP, Description: "IP地址或CIDR地址块", Example: "ip:10.0.0.0/8", source: sourceAsset, column: "ip"},
+	{Name: "port", Type: FieldInt, Description: "端口号，支持范围和比较", Example: "port:8000-9000", source: sourceAsset, column: "port"},
+	{Name: "title", Type: FieldText, Description: "网页标题", Example: `title:"admin"`, source: sourceAsset, column: "title", match: matchContains},
+	{Name: "server", Aliases: []string{"webserver"}, Type: FieldText, Description: "Web服务器软件", Example: "server:nginx", source: sourceAsset, column: "web_server", match: matchContains},
+	{Name: "tech", Aliases: []string{"technology"}, Type: FieldList, Description: "使用的技术栈", Example: "tech:nginx", source: sourceAsset, column: "technologies", match: matchContains},
+	{Name: "protocol", Aliases: []string{"scheme"}, Type: FieldText, Description: "应用层协议", Example: "protocol:https", source: sourceAsset, column: "protocol", match: matchExact},
+	{Name: "transport", Type: FieldText, Description: "传输层协议 (tcp, udp)", Example: "transport:udp", source: sourceAsset, column: "transport", match: matchExact},
+	{Name: "product", Type: FieldText, Description: "服务识别出的产品名", Example: "product:openssh", source: sourceAsset, column: "product", match: matchContains},
+	{Name: "version", Type: FieldText, Description: "服务识别出的产品版本", Example: "version:8.9", source: sourceAsset, column: "version", match: matchContains},
+	{Name: "source", Type: FieldText, Description: "资产的发现来源", Example: "source:httpx", source: sourceAsset, column: "source", match: matchExact},
+	{Name: "cdn", Type: FieldText, Description: "资产所在的CDN/WAF服务商", Example: "cdn:cloudflare", source: sourceAsset, column: "cdn_provider", match: matchExact},
+	{Name: "asn", Type: FieldText, Description: "自治系统编号", Example: "asn:AS13335", source: sourceIPMetadata, column: "asn", match: matchExact},
+	{Name: "org", Aliases: []string{"organization"}, Type: FieldText, Description: "IP所属组织", Example: `org:"Cloudflare"`, source: sourceIPMetadata, column: "organization", match: matchContains},
+	{Name: "country", Type: FieldText, Description: "国家代码", Example: "country:CN", source: sourceIPMetadata, column: "country_code", match: matchExact},
+	{Name: "city", Type: FieldText, Description: "IP所在城市", Example: `city:"Tokyo"`, source: sourceIPMetadata, column: "city", match: matchContains},
+	{Name: "cloud", Type: FieldText, Description: "IP所属的CDN或云服务商", Example: "cloud:cloudflare", source: sourceIPMetadata, column: "cloud_provider", match: matchExact},
+	{Name: "tag", Aliases: []string{"label"}, Type: FieldText, Description: "实体本身的标签", Example: "tag:login-panel", source: sourceTag, column: "name", match: matchExact},
+}
+
+var fieldIndex = buildFieldIndex()
+
+func buildFieldIndex() map[string]*Field {
+	index := make(map[string]*Field)
+	for _, f := range fields {
+		index[f.Name] = f
+		for _, alias := range f.Aliases {
+			index[alias] = f
+		}
+	}
+	return index
+}
+
+// lookupField 根据名称或别名查找字段
+func lookupField(name string) (*Field, bool) {
+	f, ok := fieldIndex[name]
+	return f, ok
+}
+
+// Fields 返回所有可搜索字段的描述，供前端做自动补全
+func Fields() []Field {
+	result := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, *f)
+	}
+	return result
+}
+
+// suggestField 为拼写错误的字段名找出最接近的候选
+func suggestField(name string) string {
+	var names []string
+	for key := range fieldIndex {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	best, bestDist := "", 3
+	for _, candidate := range names {
+		if d := levenshtein(name, candidate); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,153 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError 描述了查询语句中的语法错误，Pos 为出错位置 (从1开始的字符序号)
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("查询语法错误 (第 %d 个字符): %s", e.Pos, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokTerm   tokenKind = iota // field:value 或自由文本
+	tokLParen                  // (
+	tokRParen                  // )
+	tokNot                     // - 前缀
+	tokOr                      // OR
+	tokAnd                     // AND (可省略，相邻条件默认为AND)
+	tokEOF
+)
+
+type token struct {
+	kind   tokenKind
+	pos    int
+	field  string // 仅 tokTerm: 字段名，自由文本时为空
+	value  string // 仅 tokTerm: 值 (已去除引号)
+	quoted bool   // 值是否由双引号包裹
+}
+
+// lex 将查询字符串切分为 token 序列
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i + 1})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokNot, pos: i + 1})
+			i++
+		default:
+			tok, next, err := lexTerm(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+// lexTerm 读取一个条件: field:value、field:"quoted value"、"phrase" 或 word
+func lexTerm(runes []rune, start int) (token, int, error) {
+	tok := token{kind: tokTerm, pos: start + 1}
+
+	if runes[start] == '"' {
+		value, next, err := lexQuoted(runes, start)
+		if err != nil {
+			return tok, 0, err
+		}
+		tok.value, tok.quoted = value, true
+		return tok, next, nil
+	}
+
+	i := start
+	for i < len(runes) && !isTermBoundary(runes[i]) && runes[i] != ':' {
+		i++
+	}
+	word := string(runes[start:i])
+
+	if i < len(runes) && runes[i] == ':' {
+		if word == "" {
+			return tok, 0, &SyntaxError{Pos: start + 1, Msg: "冒号前缺少字段名"}
+		}
+		tok.field = strings.ToLower(word)
+		i++ // 跳过冒号
+		if i < len(runes) && runes[i] == '"' {
+			value, next, err := lexQuoted(runes, i)
+			if err != nil {
+				return tok, 0, err
+			}
+			if value == "" {
+				return tok, 0, &SyntaxError{Pos: i + 1, Msg: fmt.Sprintf("字段 '%s' 的值不能为空", tok.field)}
+			}
+			tok.value, tok.quoted = value, true
+			return tok, next, nil
+		}
+		valueStart := i
+		for i < len(runes) && !isTermBoundary(runes[i]) {
+			i++
+		}
+		if i == valueStart {
+			return tok, 0, &SyntaxError{Pos: valueStart + 1, Msg: fmt.Sprintf("字段 '%s' 缺少值", tok.field)}
+		}
+		tok.value = string(runes[valueStart:i])
+		return tok, i, nil
+	}
+
+	switch word {
+	case "OR":
+		return token{kind: tokOr, pos: start + 1}, i, nil
+	case "AND":
+		return token{kind: tokAnd, pos: start + 1}, i, nil
+	}
+	tok.value = word
+	return tok, i, nil
+}
+
+// lexQuoted 读取以双引号包裹的字符串，支持 \" 和 \\ 转义
+func lexQuoted(runes []rune, start int) (string, int, error) {
+	var sb strings.Builder
+	i := start + 1
+	for i < len(runes) {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				sb.WriteRune(runes[i+1])
+				i += 2
+				continue
+			}
+			sb.WriteRune(runes[i])
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteRune(runes[i])
+		}
+		i++
+	}
+	return "", 0, &SyntaxError{Pos: start + 1, Msg: "引号未闭合"}
+}
+
+func isTermBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
@@ -0,0 +1,185 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxQueryLength 限制查询语句的最大长度，避免生成过于庞大的SQL
+const MaxQueryLength = 1024
+
+// Node 是查询语法树中的节点
+type Node interface {
+	node()
+}
+
+// AndNode 表示所有子条件同时满足
+type AndNode struct {
+	Children []Node
+}
+
+// OrNode 表示任一子条件满足
+type OrNode struct {
+	Children []Node
+}
+
+// NotNode 表示对子条件取反
+type NotNode struct {
+	Child Node
+}
+
+// TermNode 是一个叶子条件，Field 为空时表示自由文本搜索
+type TermNode struct {
+	Field  string
+	Value  string
+	Quoted bool
+	Pos    int
+}
+
+func (*AndNode) node()  {}
+func (*OrNode) node()   {}
+func (*NotNode) node()  {}
+func (*TermNode) node() {}
+
+// Parse 将查询语句解析为语法树。
+// 语法: 相邻条件默认为 AND，支持 OR、括号分组以及 - 前缀取反，例如:
+//
+//	tech:nginx port:8443 title:"admin" -domain:*.cdn.example.com
+//	(port:80 OR port:443) -server:cloudflare
+func Parse(query string) (Node, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &SyntaxError{Pos: 1, Msg: "查询语句不能为空"}
+	}
+	if len([]rune(query)) > MaxQueryLength {
+		return nil, &SyntaxError{Pos: MaxQueryLength, Msg: fmt.Sprintf("查询语句过长，最多 %d 个字符", MaxQueryLength)}
+	}
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: "多余的右括号"}
+		}
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "无法识别的内容"}
+	}
+	return n, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr: and ( OR and )*
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for p.peek().kind == tokOr {
+		orTok := p.next()
+		if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+			return nil, &SyntaxError{Pos: orTok.pos, Msg: "OR 之后缺少条件"}
+		}
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+// parseAnd: unary ( AND? unary )*
+func (p *parser) parseAnd() (Node, error) {
+	var children []Node
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(children) == 0 {
+				if tok.kind == tokOr {
+					return nil, &SyntaxError{Pos: tok.pos, Msg: "OR 之前缺少条件"}
+				}
+				return nil, &SyntaxError{Pos: tok.pos, Msg: "缺少查询条件"}
+			}
+			if len(children) == 1 {
+				return children[0], nil
+			}
+			return &AndNode{Children: children}, nil
+		case tokAnd:
+			if len(children) == 0 {
+				return nil, &SyntaxError{Pos: tok.pos, Msg: "AND 之前缺少条件"}
+			}
+			p.next()
+			if k := p.peek().kind; k == tokEOF || k == tokRParen || k == tokOr || k == tokAnd {
+				return nil, &SyntaxError{Pos: tok.pos, Msg: "AND 之后缺少条件"}
+			}
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+}
+
+// parseUnary: - unary | primary
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary: ( or ) | term
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: "括号未闭合"}
+		}
+		return n, nil
+	case tokTerm:
+		return &TermNode{Field: tok.field, Value: tok.value, Quoted: tok.quoted, Pos: tok.pos}, nil
+	case tokRParen:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "多余的右括号"}
+	case tokEOF:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "查询语句意外结束"}
+	default:
+		return nil, &SyntaxError{Pos: tok.pos, Msg: "此处需要一个查询条件"}
+	}
+}
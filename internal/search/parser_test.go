@@ -0,0 +1,119 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// format 把语法树格式化为前缀表达式，便于比较结构
+func format(n Node) string {
+	switch n := n.(type) {
+	case *AndNode:
+		return "AND(" + formatChildren(n.Children) + ")"
+	case *OrNode:
+		return "OR(" + formatChildren(n.Children) + ")"
+	case *NotNode:
+		return "NOT(" + format(n.Child) + ")"
+	case *TermNode:
+		value := n.Value
+		if n.Quoted {
+			value = `"` + value + `"`
+		}
+		if n.Field == "" {
+			return value
+		}
+		return n.Field + ":" + value
+	}
+	return "?"
+}
+
+func formatChildren(children []Node) string {
+	parts := make([]string, len(children))
+	for i, c := range children {
+		parts[i] = format(c)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"tech:nginx", "tech:nginx"},
+		{"Tech:Nginx", "tech:Nginx"},
+		// 相邻条件默认为 AND，AND 的优先级高于 OR
+		{"tech:nginx port:443", "AND(tech:nginx port:443)"},
+		{"a AND b", "AND(a b)"},
+		{"a b OR c", "OR(AND(a b) c)"},
+		{"a OR b c", "OR(a AND(b c))"},
+		{"a OR b OR c", "OR(a b c)"},
+		{"(a OR b) c", "AND(OR(a b) c)"},
+		{"((a))", "a"},
+		// 取反只作用于紧随其后的条件或括号
+		{"-server:cloudflare", "NOT(server:cloudflare)"},
+		{"-a b", "AND(NOT(a) b)"},
+		{"-(a OR b)", "NOT(OR(a b))"},
+		{"--a", "NOT(NOT(a))"},
+		{"port:8000-9000", "port:8000-9000"},
+		{"a - b", "AND(a - b)"},
+		// 引号内的空白、括号和关键字都是值的一部分
+		{`title:"admin panel"`, `title:"admin panel"`},
+		{`title:"a (b) OR c"`, `title:"a (b) OR c"`},
+		{`"login page"`, `"login page"`},
+		{`title:"say \"hi\" \\ ok"`, `title:"say "hi" \ ok"`},
+		{`or and`, "AND(or and)"},
+		{"ip:10.0.0.0/8", "ip:10.0.0.0/8"},
+		{"domain:*.example.com", "domain:*.example.com"},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		if got := format(n); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"   ", 1, "不能为空"},
+		{strings.Repeat("a", MaxQueryLength+1), MaxQueryLength, "过长"},
+		{`title:"admin`, 7, "引号未闭合"},
+		{`"admin`, 1, "引号未闭合"},
+		{":nginx", 1, "缺少字段名"},
+		{"tech: port:80", 6, "缺少值"},
+		{`title:""`, 7, "不能为空"},
+		{"(a OR b", 1, "括号未闭合"},
+		{"a OR b)", 7, "多余的右括号"},
+		{")", 1, "缺少查询条件"},
+		{"a OR", 3, "OR 之后缺少条件"},
+		{"OR a", 1, "OR 之前缺少条件"},
+		{"a AND", 3, "AND 之后缺少条件"},
+		{"AND a", 1, "AND 之前缺少条件"},
+		{"a OR OR b", 3, "OR 之后缺少条件"},
+		{"()", 2, "缺少查询条件"},
+		{"-)", 2, "多余的右括号"},
+		{"-(", 3, "缺少查询条件"},
+		{"中文 tech:", 9, "缺少值"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) err = %v, want SyntaxError", tt.query, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) {
+			t.Errorf("Parse(%q) = 第%d个字符 %s, want 第%d个字符 %s", tt.query, syntaxErr.Pos, syntaxErr.Msg, tt.pos, tt.msg)
+		}
+	}
+}
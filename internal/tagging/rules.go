@@ -0,0 +1,184 @@
+package tagging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+)
+
+// domainFields 是域名规则可以匹配的字段
+var domainFields = map[string]func(d *model.Domain) []string{
+	"domain": func(d *model.Domain) []string { return []string{d.FQDN} },
+	"root":   func(d *model.Domain) []string { return []string{d.RootDomain} },
+	"cdn":    func(d *model.Domain) []string { return []string{d.CDNProvider} },
+	"source": func(d *model.Domain) []string { return []string{d.Source} },
+}
+
+// assetFields 是资产规则可以匹配的字段，字段名与搜索语法保持一致
+var assetFields = map[string]func(a *model.Asset) []string{
+	"ip":        func(a *model.Asset) []string { return []string{a.IP} },
+	"port":      func(a *model.Asset) []string { return []string{strconv.Itoa(a.Port)} },
+	"title":     func(a *model.Asset) []string { return []string{a.Title} },
+	"server":    func(a *model.Asset) []string { return []string{a.WebServer} },
+	"tech":      func(a *model.Asset) []string { return a.Technologies },
+	"protocol":  func(a *model.Asset) []string { return []string{a.Protocol} },
+	"transport": func(a *model.Asset) []string { return []string{a.Transport} },
+	"product":   func(a *model.Asset) []string { return []string{a.Product} },
+	"version":   func(a *model.Asset) []string { return []string{a.Version} },
+	"cdn":       func(a *model.Asset) []string { return []string{a.CDNProvider} },
+	"source":    func(a *model.Asset) []string { return []string{a.Source} },
+}
+
+// findingFields 是漏洞发现规则可以匹配的字段
+var findingFields = map[string]func(f *model.Finding) []string{
+	"template": func(f *model.Finding) []string { return []string{f.TemplateID} },
+	"name":     func(f *model.Finding) []string { return []string{f.Name} },
+	"severity": func(f *model.Finding) []string { return []string{f.Severity} },
+	"type":     func(f *model.Finding) []string { return []string{f.Type} },
+	"host":     func(f *model.Finding) []string { return []string{f.Host} },
+	"matched":  func(f *model.Finding) []string { return []string{f.MatchedAt} },
+}
+
+// Fields 返回每种实体的规则可以匹配的字段名，按名称排序
+func Fields() map[string][]string {
+	return map[string][]string{
+		model.TagEntityDomain:  sortedKeys(domainFields),
+		model.TagEntityAsset:   sortedKeys(assetFields),
+		model.TagEntityFinding: sortedKeys(findingFields),
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type rule struct {
+	id     uint
+	tagID  uint
+	entity string
+	field  string
+	re     *regexp.Regexp
+}
+
+// Rules 是编译后的项目自动打标签规则
+type Rules struct {
+	rules []*rule
+}
+
+// Load 从数据库加载项目已启用的自动打标签规则并编译为 Rules
+func Load(db *gorm.DB, projectID uint) (*Rules, error) {
+	var rules []model.TagRule
+	if err := db.Where("project_id = ? AND is_enabled = ?", projectID, true).Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("加载自动打标签规则失败: %w", err)
+	}
+	return New(rules)
+}
+
+// New 编译自动打标签规则
+func New(rules []model.TagRule) (*Rules, error) {
+	r := &Rules{}
+	for _, tr := range rules {
+		re, err := compileRule(tr.EntityType, tr.Field, tr.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("自动打标签规则 #%d 无效: %w", tr.ID, err)
+		}
+		r.rules = append(r.rules, &rule{id: tr.ID, tagID: tr.TagID, entity: tr.EntityType, field: tr.Field, re: re})
+	}
+	return r, nil
+}
+
+// ValidateRule 校验一条自动打标签规则的实体类型、字段和正则表达式是否合法
+func ValidateRule(entityType, field, pattern string) error {
+	_, err := compileRule(entityType, field, pattern)
+	return err
+}
+
+func compileRule(entityType, field, pattern string) (*regexp.Regexp, error) {
+	fields, ok := Fields()[entityType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的实体类型 '%s'", entityType)
+	}
+	if i := sort.SearchStrings(fields, field); i == len(fields) || fields[i] != field {
+		return nil, fmt.Errorf("实体类型 '%s' 不支持字段 '%s'，可用字段: %s", entityType, field, strings.Join(fields, ", "))
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("正则表达式 '%s' 无效: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Empty 判断是否没有任何规则
+func (r *Rules) Empty() bool {
+	return len(r.rules) == 0
+}
+
+// Match 对解析结果中已入库 (带ID) 的域名、资产和漏洞发现求值，返回命中规则产生的标签关联
+func (r *Rules) Match(projectID uint, result *parser.ParseResult) []model.Tagging {
+	now := time.Now()
+	var taggings []model.Tagging
+	add := func(ru *rule, entityID uint, values []string) {
+		if entityID == 0 || !matchAny(ru.re, values) {
+			return
+		}
+		taggings = append(taggings, model.Tagging{
+			ProjectID:  projectID,
+			TagID:      ru.tagID,
+			EntityType: ru.entity,
+			EntityID:   entityID,
+			Source:     model.TagSourceRule,
+			RuleID:     ru.id,
+			CreatedAt:  now,
+		})
+	}
+	for _, ru := range r.rules {
+		switch ru.entity {
+		case model.TagEntityDomain:
+			for i := range result.Domains {
+				add(ru, result.Domains[i].ID, domainFields[ru.field](&result.Domains[i]))
+			}
+		case model.TagEntityAsset:
+			for i := range result.Assets {
+				add(ru, result.Assets[i].ID, assetFields[ru.field](&result.Assets[i]))
+			}
+		case model.TagEntityFinding:
+			for i := range result.Findings {
+				add(ru, result.Findings[i].ID, findingFields[ru.field](&result.Findings[i]))
+			}
+		}
+	}
+	return taggings
+}
+
+func matchAny(re *regexp.Regexp, values []string) bool {
+	for _, v := range values {
+		if v != "" && re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply 加载项目的自动打标签规则，对本次入库的实体求值并保存命中的标签，返回新增的关联数量
+func Apply(db *gorm.DB, projectID uint, result *parser.ParseResult) (int64, error) {
+	rules, err := Load(db, projectID)
+	if err != nil {
+		return 0, err
+	}
+	if rules.Empty() {
+		return 0, nil
+	}
+	return save(db, rules.Match(projectID, result))
+}
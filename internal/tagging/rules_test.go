@@ -0,0 +1,83 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"gorm.io/gorm"
+)
+
+func TestRulesMatch(t *testing.T) {
+	result := &parser.ParseResult{
+		Domains: []model.Domain{
+			{Model: gorm.Model{ID: 1}, FQDN: "admin.example.com", RootDomain: "example.com"},
+			{Model: gorm.Model{ID: 2}, FQDN: "www.example.com", RootDomain: "example.com", CDNProvider: "cloudflare"},
+			// 未入库的实体没有ID，不参与打标签
+			{FQDN: "admin.example.org"},
+		},
+		Assets: []model.Asset{
+			{Model: gorm.Model{ID: 10}, IP: "192.0.2.1", Port: 9200, Technologies: []string{"Nginx", "Elasticsearch:8.11"}},
+			{Model: gorm.Model{ID: 11}, IP: "192.0.2.2", Port: 443, Transport: model.TransportTCP, Title: "Welcome"},
+			{Model: gorm.Model{ID: 12}, IP: "192.0.2.2", Port: 161, Transport: model.TransportUDP},
+		},
+		Findings: []model.Finding{
+			{Model: gorm.Model{ID: 20}, TemplateID: "git-config", Severity: "medium"},
+			{Model: gorm.Model{ID: 21}, TemplateID: "CVE-2021-44228", Severity: "critical"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		rule model.TagRule
+		want []string
+	}{
+		{"域名前缀", model.TagRule{EntityType: model.TagEntityDomain, Field: "domain", Pattern: `^admin\.`}, []string{"domain#1"}},
+		{"大小写不敏感", model.TagRule{EntityType: model.TagEntityDomain, Field: "cdn", Pattern: "CloudFlare"}, []string{"domain#2"}},
+		{"空值不匹配", model.TagRule{EntityType: model.TagEntityAsset, Field: "title", Pattern: ".*"}, []string{"asset#11"}},
+		{"多值字段任一命中", model.TagRule{EntityType: model.TagEntityAsset, Field: "tech", Pattern: "^elasticsearch"}, []string{"asset#10"}},
+		{"端口", model.TagRule{EntityType: model.TagEntityAsset, Field: "port", Pattern: "^(9200|161)$"}, []string{"asset#10", "asset#12"}},
+		{"传输层协议", model.TagRule{EntityType: model.TagEntityAsset, Field: "transport", Pattern: "^udp$"}, []string{"asset#12"}},
+		{"漏洞发现", model.TagRule{EntityType: model.TagEntityFinding, Field: "template", Pattern: "^cve-"}, []string{"finding#21"}},
+		{"无命中", model.TagRule{EntityType: model.TagEntityFinding, Field: "severity", Pattern: "^low$"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.ID, tt.rule.TagID = 7, 3
+			rules, err := New([]model.TagRule{tt.rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, tg := range rules.Match(5, result) {
+				if tg.ProjectID != 5 || tg.TagID != 3 || tg.RuleID != 7 || tg.Source != model.TagSourceRule {
+					t.Errorf("tagging = %+v", tg)
+				}
+				got = append(got, fmt.Sprintf("%s#%d", tg.EntityType, tg.EntityID))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		entity, field, pattern string
+		want                   string
+	}{
+		{model.TagEntityAsset, "tech", "^nginx", ""},
+		{"endpoint", "url", ".*", "不支持的实体类型"},
+		{model.TagEntityDomain, "title", ".*", "不支持字段 'title'"},
+		{model.TagEntityFinding, "name", "(", "正则表达式 '(' 无效"},
+	}
+	for _, tt := range tests {
+		err := ValidateRule(tt.entity, tt.field, tt.pattern)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("ValidateRule(%s, %s, %q) = %v, want %q", tt.entity, tt.field, tt.pattern, err, tt.want)
+		}
+	}
+}
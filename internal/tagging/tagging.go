@@ -0,0 +1,125 @@
+package tagging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/src-hunter/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tables 是可以打标签的实体类型对应的数据表
+var tables = map[string]string{
+	model.TagEntityDomain:  "domains",
+	model.TagEntityAsset:   "assets",
+	model.TagEntityFinding: "findings",
+}
+
+// Table 返回实体类型对应的数据表，不支持的实体类型返回 false
+func Table(entityType string) (string, bool) {
+	table, ok := tables[entityType]
+	return table, ok
+}
+
+// NormalizeName 规范化标签名称：去掉首尾空白并合并连续空白
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// EnsureTags 返回项目中指定名称的标签，不存在的标签会被创建
+func EnsureTags(db *gorm.DB, projectID uint, names []string) ([]model.Tag, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		name = NormalizeName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, model.Tag{ProjectID: projectID, Name: name})
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "name"}},
+		DoNothing: true,
+	}).Create(&tags).Error; err != nil {
+		return nil, fmt.Errorf("创建标签失败: %w", err)
+	}
+	names = make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	var saved []model.Tag
+	if err := db.Where("project_id = ? AND name IN ?", projectID, names).Order("name").Find(&saved).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	return saved, nil
+}
+
+// Add 为一组实体手动打上标签，已存在的关联保持不变，返回新增的关联数量
+func Add(db *gorm.DB, projectID uint, tagIDs []uint, entityType string, entityIDs []uint) (int64, error) {
+	now := time.Now()
+	taggings := make([]model.Tagging, 0, len(tagIDs)*len(entityIDs))
+	for _, tagID := range tagIDs {
+		for _, entityID := range entityIDs {
+			taggings = append(taggings, model.Tagging{
+				ProjectID:  projectID,
+				TagID:      tagID,
+				EntityType: entityType,
+				EntityID:   entityID,
+				Source:     model.TagSourceManual,
+				CreatedAt:  now,
+			})
+		}
+	}
+	return save(db, taggings)
+}
+
+// Remove 删除一组实体上的指定标签，返回删除的关联数量
+func Remove(db *gorm.DB, projectID uint, tagIDs []uint, entityType string, entityIDs []uint) (int64, error) {
+	result := db.Where("project_id = ? AND tag_id IN ? AND entity_type = ? AND entity_id IN ?", projectID, tagIDs, entityType, entityIDs).
+		Delete(&model.Tagging{})
+	return result.RowsAffected, result.Error
+}
+
+// Names 返回一组实体的标签名称，按实体ID分组，每个实体的标签按名称排序
+func Names(db *gorm.DB, entityType string, entityIDs []uint) (map[uint][]string, error) {
+	names := make(map[uint][]string)
+	if len(entityIDs) == 0 {
+		return names, nil
+	}
+	var rows []struct {
+		EntityID uint
+		Name     string
+	}
+	err := db.Table("taggings AS tg").
+		Select("tg.entity_id, t.name").
+		Joins("JOIN tags t ON t.id = tg.tag_id AND t.deleted_at IS NULL").
+		Where("tg.entity_type = ? AND tg.entity_id IN ?", entityType, entityIDs).
+		Order("t.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		names[row.EntityID] = append(names[row.EntityID], row.Name)
+	}
+	return names, nil
+}
+
+// save 批量写入标签关联，已存在的关联 (同一标签、同一实体) 保持不变
+func save(db *gorm.DB, taggings []model.Tagging) (int64, error) {
+	if len(taggings) == 0 {
+		return 0, nil
+	}
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tag_id"}, {Name: "entity_type"}, {Name: "entity_id"}},
+		DoNothing: true,
+	}).CreateInBatches(&taggings, 1000)
+	return result.RowsAffected, result.Error
+}
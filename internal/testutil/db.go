@@ -0,0 +1,58 @@
+// Package testutil 提供测试共用的数据库和日志初始化
+package testutil
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/src-hunter/internal/database"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DSNEnv 是测试数据库连接串所在的环境变量，未设置时需要数据库的测试会被跳过
+const DSNEnv = "SRC_HUNTER_TEST_DSN"
+
+var (
+	migrateOnce sync.Once
+	sharedDB    *gorm.DB
+	migrateErr  error
+)
+
+// InitLogger 为测试设置一个不输出的全局日志
+func InitLogger() {
+	if logger.Logger == nil {
+		logger.Logger = zap.NewNop()
+	}
+}
+
+// DB 连接测试数据库并在首次调用时执行迁移。每个测试在独立的事务中运行，结束时回滚。
+func DB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("未设置 %s，跳过需要 PostgreSQL 的测试", DSNEnv)
+	}
+	InitLogger()
+
+	migrateOnce.Do(func() {
+		sharedDB, migrateErr = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Discard})
+		if migrateErr == nil {
+			migrateErr = database.Migrate(sharedDB)
+		}
+	})
+	if migrateErr != nil {
+		t.Fatalf("初始化测试数据库失败: %v", migrateErr)
+	}
+
+	tx := sharedDB.Begin()
+	if tx.Error != nil {
+		t.Fatalf("开启测试事务失败: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
@@ -0,0 +1,150 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/src-hunter/internal/dnsname"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+	"net"
+	"time"
+)
+
+const (
+	// wildcardTTL 是根域名泛解析检测结果的缓存时间
+	wildcardTTL = 24 * time.Hour
+	// wildcardProbes 是每次检测解析的随机子域名数量
+	wildcardProbes = 2
+	// wildcardTimeout 是单次检测的超时时间
+	wildcardTimeout = 5 * time.Second
+)
+
+// filterWildcardDomains 移除泛解析产生的垃圾子域名：根域名存在泛解析，
+// 且子域名的 A/AAAA 记录全部落在随机子域名解析到的IP中时，丢弃该域名及其全部记录。
+// 只有解析结果中带有DNS记录 (如 dnsx) 时才能判断。
+func (p *TaskProcessor) filterWildcardDomains(task *model.Task, result *parser.ParseResult) {
+	if len(result.DNSRecords) == 0 || len(result.Domains) == 0 {
+		return
+	}
+
+	addresses := make(map[string][]string)
+	for _, r := range result.DNSRecords {
+		if r.Type == model.DNSRecordA || r.Type == model.DNSRecordAAAA {
+			addresses[r.FQDN] = append(addresses[r.FQDN], r.Value)
+		}
+	}
+
+	wildcards := make(map[string]map[string]bool)
+	junk := make(map[string]bool)
+	for _, d := range result.Domains {
+		root := dnsname.Root(d.FQDN)
+		ips := addresses[d.FQDN]
+		if root == "" || root == d.FQDN || len(ips) == 0 {
+			continue
+		}
+		wildcardIPs, checked := wildcards[root]
+		if !checked {
+			wildcardIPs = p.wildcardIPs(root)
+			wildcards[root] = wildcardIPs
+		}
+		if len(wildcardIPs) == 0 {
+			continue
+		}
+		allWildcard := true
+		for _, ip := range ips {
+			if !wildcardIPs[ip] {
+				allWildcard = false
+				break
+			}
+		}
+		if allWildcard {
+			junk[d.FQDN] = true
+		}
+	}
+	if len(junk) == 0 {
+		return
+	}
+
+	domains := result.Domains[:0]
+	for _, d := range result.Domains {
+		if !junk[d.FQDN] {
+			domains = append(domains, d)
+		}
+	}
+	result.Domains = domains
+	records := result.DNSRecords[:0]
+	for _, r := range result.DNSRecords {
+		if !junk[r.FQDN] {
+			records = append(records, r)
+		}
+	}
+	result.DNSRecords = records
+
+	logger.Logger.Info("已丢弃泛解析产生的子域名", zap.Uint("task_id", task.ID), zap.Int("count", len(junk)))
+}
+
+// lookupHost 解析域名，测试时可以替换
+var lookupHost = net.DefaultResolver.LookupHost
+
+// wildcardIPs 返回根域名泛解析到的IP集合，根域名没有泛解析时返回空。
+// 确定的检测结果会缓存 wildcardTTL；检测因超时等临时错误无法确定时不写入缓存，
+// 有过期的缓存时继续使用，避免一次解析失败让该根域名一整天都不再过滤泛解析。
+func (p *TaskProcessor) wildcardIPs(root string) map[string]bool {
+	var cached model.DNSWildcard
+	err := p.DB.Where("root_domain = ?", root).First(&cached).Error
+	if err != nil || time.Since(cached.CheckedAt) > wildcardTTL {
+		detected, conclusive := detectWildcard(root)
+		switch {
+		case conclusive:
+			cached = detected
+			if err := p.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&cached).Error; err != nil {
+				logger.Logger.Warn("保存泛解析检测结果失败", zap.String("root", root), zap.Error(err))
+			}
+		case err != nil:
+			cached = detected
+			logger.Logger.Warn("泛解析检测失败，本次不缓存结果", zap.String("root", root))
+		default:
+			logger.Logger.Warn("泛解析检测失败，继续使用过期的检测结果", zap.String("root", root))
+		}
+	}
+
+	ips := make(map[string]bool, len(cached.IPs))
+	for _, ip := range cached.IPs {
+		ips[ip] = true
+	}
+	return ips
+}
+
+// detectWildcard 解析若干个随机子域名，任意一个能解析即认为存在泛解析。
+// 只有每个随机子域名都得到了应答或 NXDOMAIN 时结果才是确定的 (conclusive)，
+// 超时、SERVFAIL 等临时错误不能说明根域名没有泛解析。
+func detectWildcard(root string) (result model.DNSWildcard, conclusive bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), wildcardTimeout)
+	defer cancel()
+
+	result = model.DNSWildcard{RootDomain: root, CheckedAt: time.Now()}
+	conclusive = true
+	seen := make(map[string]bool)
+	for i := 0; i < wildcardProbes; i++ {
+		addrs, err := lookupHost(ctx, uuid.NewString()+"."+root)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+				conclusive = false
+			}
+			continue
+		}
+		result.IsWildcard = true
+		for _, addr := range addrs {
+			if !seen[addr] {
+				seen[addr] = true
+				result.IPs = append(result.IPs, addr)
+			}
+		}
+	}
+	return result, conclusive
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+)
+
+// stubLookupHost 在测试期间把解析替换为依次返回给定结果的函数
+func stubLookupHost(t *testing.T, answers ...func() ([]string, error)) {
+	t.Helper()
+	orig := lookupHost
+	t.Cleanup(func() { lookupHost = orig })
+	i := 0
+	lookupHost = func(context.Context, string) ([]string, error) {
+		answer := answers[i%len(answers)]
+		i++
+		return answer()
+	}
+}
+
+func answer(addrs ...string) func() ([]string, error) {
+	return func() ([]string, error) { return addrs, nil }
+}
+
+func nxdomain() ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", IsNotFound: true}
+}
+
+func timeout() ([]string, error) {
+	return nil, &net.DNSError{Err: "i/o timeout", IsTimeout: true}
+}
+
+func servfail() ([]string, error) {
+	return nil, &net.DNSError{Err: "server misbehaving", IsTemporary: true}
+}
+
+func TestDetectWildcard(t *testing.T) {
+	tests := []struct {
+		name       string
+		answers    []func() ([]string, error)
+		wildcard   bool
+		ips        []string
+		conclusive bool
+	}{
+		{"NXDOMAIN", []func() ([]string, error){nxdomain}, false, nil, true},
+		{"泛解析", []func() ([]string, error){answer("192.0.2.1"), answer("192.0.2.1", "192.0.2.2")}, true, []string{"192.0.2.1", "192.0.2.2"}, true},
+		{"超时", []func() ([]string, error){timeout}, false, nil, false},
+		{"SERVFAIL", []func() ([]string, error){servfail}, false, nil, false},
+		{"部分超时", []func() ([]string, error){nxdomain, timeout}, false, nil, false},
+		{"其他错误", []func() ([]string, error){func() ([]string, error) { return nil, errors.New("boom") }}, false, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubLookupHost(t, tt.answers...)
+			got, conclusive := detectWildcard("example.com")
+			if got.IsWildcard != tt.wildcard || conclusive != tt.conclusive || len(got.IPs) != len(tt.ips) {
+				t.Fatalf("detectWildcard = %+v, %v, want wildcard=%v ips=%v conclusive=%v", got, conclusive, tt.wildcard, tt.ips, tt.conclusive)
+			}
+			for i, ip := range tt.ips {
+				if got.IPs[i] != ip {
+					t.Errorf("ips = %v, want %v", got.IPs, tt.ips)
+				}
+			}
+		})
+	}
+}
+
+func TestWildcardIPsDoesNotCacheFailedProbes(t *testing.T) {
+	db := testutil.DB(t)
+	p := &TaskProcessor{DB: db}
+	root := "wildcard-test.example"
+
+	stubLookupHost(t, timeout)
+	if ips := p.wildcardIPs(root); len(ips) != 0 {
+		t.Errorf("ips = %v, want 空", ips)
+	}
+	var n int64
+	db.Model(&model.DNSWildcard{}).Where("root_domain = ?", root).Count(&n)
+	if n != 0 {
+		t.Fatal("检测失败的结果不应缓存")
+	}
+
+	// 解析恢复后重新检测并缓存结果
+	stubLookupHost(t, answer("192.0.2.1"))
+	if ips := p.wildcardIPs(root); !ips["192.0.2.1"] {
+		t.Errorf("ips = %v, want 192.0.2.1", ips)
+	}
+	var cached model.DNSWildcard
+	if err := db.Where("root_domain = ?", root).First(&cached).Error; err != nil || !cached.IsWildcard {
+		t.Fatalf("cached = %+v, %v", cached, err)
+	}
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/scope"
+	"net"
+	"strconv"
+)
+
+// fanOutItem 是并行步骤中一个子任务的输入
+type fanOutItem struct {
+	host     string // 子任务的输入: 域名或 IP:端口
+	ip       string // 按 IP:端口 扇出时的IP
+	domainID uint   // 输入为域名时对应的域名ID，用于关联后续发现的资产
+}
+
+// extractFanOutItem 按扇出键从上一步输出的单个条目中取出子任务输入，并判断其是否在项目范围内。
+// 条目缺少所需字段时返回 false。字段名与 model.Domain / model.Asset 序列化后的一致。
+func extractFanOutItem(itemMap map[string]interface{}, key string, projectScope *scope.Scope) (fanOutItem, scope.Decision, bool) {
+	switch key {
+	case model.FanOutKeyHostPort:
+		ip, _ := itemMap["IP"].(string)
+		port, _ := itemMap["Port"].(float64) // JSON 数字默认为 float64
+		if ip == "" || port <= 0 {
+			return fanOutItem{}, scope.Decision{}, false
+		}
+		item := fanOutItem{host: net.JoinHostPort(ip, strconv.Itoa(int(port))), ip: ip}
+		return item, projectScope.CheckAsset(ip, int(port), ""), true
+	default:
+		host, _ := itemMap["FQDN"].(string)
+		if host == "" {
+			return fanOutItem{}, scope.Decision{}, false
+		}
+		item := fanOutItem{host: host}
+		if idVal, ok := itemMap["ID"].(float64); ok {
+			item.domainID = uint(idVal)
+		}
+		return item, projectScope.CheckHost(host), true
+	}
+}
+
+// filterCDNItems 移除接入CDN/WAF的扇出条目：域名按已识别的CDN服务商判断，
+// IP:端口 按同一IP上是否有CDN资产或IP是否落在CDN节点IP段中判断。
+func (p *TaskProcessor) filterCDNItems(projectID uint, items []fanOutItem) ([]fanOutItem, error) {
+	var hosts, ips []string
+	for _, item := range items {
+		if item.ip != "" {
+			ips = append(ips, item.ip)
+		} else {
+			hosts = append(hosts, item.host)
+		}
+	}
+
+	cdnHosts := make(map[string]bool)
+	if len(hosts) > 0 {
+		var fqdns []string
+		if err := p.DB.Model(&model.Domain{}).
+			Where("project_id = ? AND fqdn IN ? AND cdn_provider <> ''", projectID, hosts).
+			Pluck("fqdn", &fqdns).Error; err != nil {
+			return nil, err
+		}
+		for _, fqdn := range fqdns {
+			cdnHosts[fqdn] = true
+		}
+	}
+	cdnIPs := make(map[string]bool)
+	if len(ips) > 0 {
+		var matched []string
+		if err := p.DB.Model(&model.Asset{}).
+			Where("project_id = ? AND ip IN ? AND cdn_provider <> ''", projectID, ips).
+			Distinct().Pluck("ip", &matched).Error; err != nil {
+			return nil, err
+		}
+		var flagged []string
+		if err := p.DB.Model(&model.IPMetadata{}).
+			Where("ip IN ? AND is_cdn", ips).
+			Pluck("ip", &flagged).Error; err != nil {
+			return nil, err
+		}
+		for _, ip := range append(matched, flagged...) {
+			cdnIPs[ip] = true
+		}
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if (item.ip != "" && cdnIPs[item.ip]) || (item.ip == "" && cdnHosts[item.host]) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, nil
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/changes"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/notify"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+// failWorkflow 在某个步骤失败后把工作流顶级任务标记为失败并发出通知。
+// 失败的子任务不会再完成扇入计数，工作流也就不可能再正常结束；
+// 以当前状态为条件更新，保证同一个工作流只通知一次。
+func (p *TaskProcessor) failWorkflow(task *model.Task, reason string) {
+	if task.ParentTaskID == 0 {
+		return
+	}
+	workflowID, err := p.workflowRootID(task)
+	if err != nil {
+		logger.Logger.Error("查找工作流顶级任务失败", zap.Uint("task_id", task.ID), zap.Error(err))
+		return
+	}
+	result := p.DB.Model(&model.Task{}).
+		Where("id = ? AND status IN ?", workflowID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":      "failed",
+			"result":      fmt.Sprintf("步骤 '%s' 失败: %s", task.WorkflowStep, reason),
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		logger.Logger.Error("标记工作流失败状态失败", zap.Uint("workflowTaskId", workflowID), zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+	p.publish(task.ProjectID, notify.EventWorkflowFailed, map[string]interface{}{
+		"workflowId": workflowID,
+		"taskId":     task.ID,
+		"step":       task.WorkflowStep,
+		"reason":     reason,
+	})
+}
+
+// completeWorkflow 在所有分支结束后把工作流顶级任务标记为完成。
+// 与 failWorkflow 一样以当前状态为条件更新，重复投递或并发扇入时只有一次能够生效，
+// 变化汇总、消失标记和完成通知都只在这次更新成功后执行。
+func (p *TaskProcessor) completeWorkflow(workflow *model.Task) error {
+	now := time.Now()
+	result := p.DB.Model(&model.Task{}).
+		Where("id = ? AND status IN ?", workflow.ID, []string{"pending", "running"}).
+		Updates(map[string]interface{}{
+			"status":      changes.WorkflowStatusCompleted,
+			"result":      "工作流成功完成",
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	workflow.Status = changes.WorkflowStatusCompleted
+	workflow.Result = "工作流成功完成"
+	workflow.FinishedAt = now
+	logger.Logger.Info("工作流已成功完成", zap.Uint("workflowTaskId", workflow.ID))
+
+	// 与上一次运行比较，把新发现的汇总写入结果
+	summary, err := changes.Summarize(p.DB, workflow)
+	if err != nil {
+		logger.Logger.Warn("计算工作流变化汇总失败", zap.Uint("workflowTaskId", workflow.ID), zap.Error(err))
+	} else {
+		workflow.Result = fmt.Sprintf("工作流成功完成：%s", changes.SummaryText(summary.Summary))
+		p.DB.Model(workflow).Update("result", workflow.Result)
+	}
+	if workflow.IsFullRun {
+		if err := changes.MarkGone(p.DB, workflow); err != nil {
+			logger.Logger.Warn("标记消失的域名和资产失败", zap.Uint("workflowTaskId", workflow.ID), zap.Error(err))
+		}
+	}
+	p.notifyWorkflowCompleted(workflow, summary)
+	return nil
+}
+
+// notifyWorkflowCompleted 发出工作流完成事件，并把新增域名、新增资产和变化资产按运行聚合为各一条事件；
+// 同时把本次运行的新发现加入聊天渠道的待发送摘要
+func (p *TaskProcessor) notifyWorkflowCompleted(workflow *model.Task, summary *changes.ChangeSet) {
+	data := map[string]interface{}{
+		"workflowId":     workflow.ID,
+		"scanProfileId":  workflow.ScanProfileID,
+		"scanScheduleId": workflow.ScanScheduleID,
+		"result":         workflow.Result,
+	}
+	if summary == nil {
+		p.publish(workflow.ProjectID, notify.EventWorkflowCompleted, data)
+		return
+	}
+	data["summary"] = summary.Summary
+	data["fromWorkflowId"] = summary.FromWorkflowID
+	p.publish(workflow.ProjectID, notify.EventWorkflowCompleted, data)
+
+	for event, items := range map[string][]changes.EntityChange{
+		notify.EventDomainNew:    summary.NewDomains,
+		notify.EventAssetNew:     summary.NewAssets,
+		notify.EventAssetChanged: summary.ChangedAssets,
+	} {
+		if len(items) == 0 {
+			continue
+		}
+		p.publish(workflow.ProjectID, event, map[string]interface{}{
+			"workflowId": workflow.ID,
+			"count":      len(items),
+			"items":      items,
+		})
+	}
+
+	if p.Notifier != nil {
+		if err := p.Notifier.AddDigest(workflow, summary); err != nil {
+			logger.Logger.Error("加入聊天渠道摘要失败", zap.Uint("workflowTaskId", workflow.ID), zap.Error(err))
+		}
+	}
+}
+
+// publish 发布事件，失败只记录日志，不影响工作流
+func (p *TaskProcessor) publish(projectID uint, event string, data interface{}) {
+	if p.Notifier == nil {
+		return
+	}
+	if _, err := p.Notifier.Publish(projectID, event, data); err != nil {
+		logger.Logger.Error("发布通知事件失败", zap.String("event", event), zap.Uint("project_id", projectID), zap.Error(err))
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// recordObservations 为本次工作流运行记录观测到的域名和资产及其当时的属性，
+// 作为运行间变化检测的快照。记录失败只写日志，不影响工作流。
+func (p *TaskProcessor) recordObservations(task *model.Task, result *parser.ParseResult) {
+	if len(result.Domains) == 0 && len(result.Assets) == 0 {
+		return
+	}
+	workflowID, err := p.workflowRootID(task)
+	if err != nil {
+		logger.Logger.Error("查找工作流顶级任务失败，跳过观测记录", zap.Uint("task_id", task.ID), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	var observations []model.Observation
+	for _, d := range result.Domains {
+		if d.ID == 0 {
+			continue
+		}
+		observations = append(observations, model.Observation{
+			ProjectID:  task.ProjectID,
+			WorkflowID: workflowID,
+			Kind:       model.ObservationKindDomain,
+			EntityID:   d.ID,
+			Value:      d.FQDN,
+			ObservedAt: now,
+		})
+	}
+
+	// 资产的观测值取本次解析出的属性，ID 已在入库时回填
+	for _, a := range result.Assets {
+		if a.ID == 0 {
+			continue
+		}
+		observations = append(observations, model.Observation{
+			ProjectID:    task.ProjectID,
+			WorkflowID:   workflowID,
+			Kind:         model.ObservationKindAsset,
+			EntityID:     a.ID,
+			Value:        a.Address(),
+			Title:        a.Title,
+			WebServer:    a.WebServer,
+			Technologies: a.Technologies,
+			ObservedAt:   now,
+		})
+	}
+	if len(observations) == 0 {
+		return
+	}
+
+	// 同一工作流内重复观测同一实体时，保留非空的属性值
+	err = p.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workflow_id"}, {Name: "kind"}, {Name: "entity_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"observed_at":  gorm.Expr("EXCLUDED.observed_at"),
+			"title":        gorm.Expr("COALESCE(NULLIF(EXCLUDED.title, ''), observations.title)"),
+			"web_server":   gorm.Expr("COALESCE(NULLIF(EXCLUDED.web_server, ''), observations.web_server)"),
+			"technologies": gorm.Expr("COALESCE(EXCLUDED.technologies, observations.technologies)"),
+		}),
+	}).CreateInBatches(&observations, 500).Error
+	if err != nil {
+		logger.Logger.Error("保存观测记录失败", zap.Uint("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// workflowRootID 沿父任务链向上查找工作流的顶级任务ID
+func (p *TaskProcessor) workflowRootID(task *model.Task) (uint, error) {
+	id, parentID := task.ID, task.ParentTaskID
+	for parentID != 0 {
+		var parent model.Task
+		if err := p.DB.Select("id", "parent_task_id").First(&parent, parentID).Error; err != nil {
+			return 0, err
+		}
+		id, parentID = parent.ID, parent.ParentTaskID
+	}
+	return id, nil
+}
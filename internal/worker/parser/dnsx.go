@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/src-hunter/internal/cdn"
+	"github.com/src-hunter/internal/dnsname"
+	"github.com/src-hunter/internal/model"
+	"strings"
+)
+
+// maxRecordValueLength 限制记录值的长度，超长的 TXT 记录会被截断
+const maxRecordValueLength = 2048
+
+// DnsxParser 负责解析 dnsx 的JSON行输出 (-json)
+type DnsxParser struct{}
+
+func init() {
+	Register("dnsx_json", &DnsxParser{}, Info{
+		Description: "dnsx 解析结果，生成域名及其 A/AAAA/CNAME/MX/NS/TXT 记录，标记悬空的 CNAME 和接入CDN的域名",
+		InputFormat: "JSON行 (dnsx -json)",
+		Entities:    []string{EntityDomain, EntityDNSRecord},
+	})
+}
+
+// dnsxOutputLine 对应 dnsx 的一行输出
+type dnsxOutputLine struct {
+	Host       string   `json:"host"`
+	A          []string `json:"a"`
+	AAAA       []string `json:"aaaa"`
+	CNAME      []string `json:"cname"`
+	MX         []string `json:"mx"`
+	NS         []string `json:"ns"`
+	TXT        []string `json:"txt"`
+	StatusCode string   `json:"status_code"`
+}
+
+// takeoverServices 是常见的、CNAME 指向后若资源被删除即可能被他人接管的服务后缀
+var takeoverServices = []struct {
+	suffix  string
+	service string
+}{
+	{".github.io", "github-pages"},
+	{".herokuapp.com", "heroku"},
+	{".herokudns.com", "heroku"},
+	{".s3.amazonaws.com", "aws-s3"},
+	{".s3-website", "aws-s3"},
+	{".cloudfront.net", "aws-cloudfront"},
+	{".elasticbeanstalk.com", "aws-elastic-beanstalk"},
+	{".azurewebsites.net", "azure-app-service"},
+	{".cloudapp.net", "azure-cloud-service"},
+	{".cloudapp.azure.com", "azure-cloud-service"},
+	{".trafficmanager.net", "azure-traffic-manager"},
+	{".blob.core.windows.net", "azure-blob"},
+	{".azureedge.net", "azure-cdn"},
+	{".myshopify.com", "shopify"},
+	{".ghost.io", "ghost"},
+	{".pantheonsite.io", "pantheon"},
+	{".netlify.app", "netlify"},
+	{".netlify.com", "netlify"},
+	{".vercel.app", "vercel"},
+	{".zendesk.com", "zendesk"},
+	{".readme.io", "readme"},
+	{".surge.sh", "surge"},
+	{".bitbucket.io", "bitbucket"},
+	{".wordpress.com", "wordpress"},
+	{".fastly.net", "fastly"},
+	{".helpscoutdocs.com", "helpscout"},
+	{".unbouncepages.com", "unbounce"},
+}
+
+// Parse 实现了 Parser 接口：每个主机生成一个域名和其全部解析记录。
+// CNAME 在解析结果为 NXDOMAIN (目标不存在) 时标记为悬空，指向易被接管的服务时记录服务名。
+// 不能用"没有 A/AAAA 记录"判断：只查询 CNAME (dnsx -cname) 时输出中本来就没有地址记录。
+func (p *DnsxParser) Parse(output []byte) (*ParseResult, error) {
+	result := &ParseResult{}
+	seenDomains := make(map[string]bool)
+	seenRecords := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line dnsxOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		host := normalizeDomain(line.Host)
+		if host == "" {
+			continue
+		}
+		if !seenDomains[host] {
+			seenDomains[host] = true
+			result.Domains = append(result.Domains, model.Domain{FQDN: host, Source: "dnsx", CDNProvider: dnsCDNProvider(&line)})
+		}
+
+		nxdomain := strings.EqualFold(line.StatusCode, "NXDOMAIN")
+		add := func(recordType string, values []string) {
+			for _, value := range values {
+				value = strings.TrimSpace(value)
+				if recordType == model.DNSRecordCNAME || recordType == model.DNSRecordNS || recordType == model.DNSRecordMX {
+					value = normalizeDNSName(value)
+				}
+				if len(value) > maxRecordValueLength {
+					value = value[:maxRecordValueLength]
+				}
+				key := host + "|" + recordType + "|" + value
+				if value == "" || seenRecords[key] {
+					continue
+				}
+				seenRecords[key] = true
+
+				record := model.DNSRecord{FQDN: host, Type: recordType, Value: strings.ToValidUTF8(value, "")}
+				if recordType == model.DNSRecordCNAME {
+					record.TakeoverService = takeoverService(value)
+					record.IsDangling = nxdomain
+				}
+				result.DNSRecords = append(result.DNSRecords, record)
+			}
+		}
+		add(model.DNSRecordA, line.A)
+		add(model.DNSRecordAAAA, line.AAAA)
+		add(model.DNSRecordCNAME, line.CNAME)
+		add(model.DNSRecordMX, line.MX)
+		add(model.DNSRecordNS, line.NS)
+		add(model.DNSRecordTXT, line.TXT)
+	}
+
+	return result, scanner.Err()
+}
+
+// dnsCDNProvider 按 CNAME 指向或解析到的IP识别域名接入的CDN，未识别时返回空
+func dnsCDNProvider(line *dnsxOutputLine) string {
+	for _, target := range line.CNAME {
+		if provider := cdn.MatchCNAME(target); provider != "" {
+			return provider
+		}
+	}
+	for _, ip := range append(append([]string{}, line.A...), line.AAAA...) {
+		if provider := cdn.MatchIP(strings.TrimSpace(ip)); provider != "" {
+			return provider
+		}
+	}
+	return ""
+}
+
+// takeoverService 返回 CNAME 目标匹配到的易被接管服务，未匹配时返回空
+func takeoverService(target string) string {
+	for _, s := range takeoverServices {
+		if strings.HasSuffix(target, s.suffix) || strings.Contains(target, s.suffix+".") {
+			return s.service
+		}
+	}
+	return ""
+}
+
+// normalizeDomain 规范化解析出的域名 (见 dnsname.Normalize)，不是合法域名时返回空
+func normalizeDomain(name string) string {
+	normalized, _ := dnsname.Normalize(name)
+	return normalized
+}
+
+// normalizeHost 规范化URL或目标中的主机：域名按 normalizeDomain 规范化，IP 等其他主机只转为小写
+func normalizeHost(host string) string {
+	if domain := normalizeDomain(host); domain != "" {
+		return domain
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+}
+
+// normalizeDNSName 规范化DNS记录中的名称 (CNAME/NS/MX 的目标)
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+)
+
+func TestDnsxParser(t *testing.T) {
+	result, err := (&DnsxParser{}).Parse(readTestdata(t, "dnsx.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var domains []string
+	for _, d := range result.Domains {
+		domains = append(domains, d.FQDN)
+	}
+	if want := []string{"www.example.com", "blog.example.com", "old.example.com"}; !equalStrings(domains, want) {
+		t.Errorf("domains = %v, want %v", domains, want)
+	}
+
+	tests := []struct {
+		fqdn, recordType, value string
+		takeover                string
+		dangling                bool
+	}{
+		{"www.example.com", model.DNSRecordA, "192.0.2.10", "", false},
+		{"www.example.com", model.DNSRecordA, "192.0.2.11", "", false},
+		// 只查询了 CNAME (dnsx -cname) 时没有地址记录，不能据此认为悬空
+		{"blog.example.com", model.DNSRecordCNAME, "example-org.github.io", "github-pages", false},
+		{"old.example.com", model.DNSRecordCNAME, "old-example.azurewebsites.net", "azure-app-service", true},
+		{"www.example.com", model.DNSRecordAAAA, "2001:db8::10", "", false},
+		{"www.example.com", model.DNSRecordMX, "mail.example.com", "", false},
+		{"www.example.com", model.DNSRecordTXT, "v=spf1 -all", "", false},
+	}
+	if len(result.DNSRecords) != len(tests) {
+		t.Fatalf("records = %+v", result.DNSRecords)
+	}
+	for i, tt := range tests {
+		r := result.DNSRecords[i]
+		if r.FQDN != tt.fqdn || r.Type != tt.recordType || r.Value != tt.value || r.TakeoverService != tt.takeover || r.IsDangling != tt.dangling {
+			t.Errorf("record[%d] = %s %s %s takeover=%q dangling=%v, want %s %s %s takeover=%q dangling=%v", i,
+				r.FQDN, r.Type, r.Value, r.TakeoverService, r.IsDangling, tt.fqdn, tt.recordType, tt.value, tt.takeover, tt.dangling)
+		}
+	}
+}
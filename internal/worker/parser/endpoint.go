@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/src-hunter/internal/model"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxExtensionLength 超过此长度的"扩展名"通常不是真正的文件扩展名 (如版本号、哈希)
+const maxExtensionLength = 10
+
+// NormalizedURL 是规范化后的URL及从中提取的信息
+type NormalizedURL struct {
+	URL        string   // 规范化后的完整URL (保留参数值)
+	Method     string   // 大写的HTTP方法，为空时为 GET
+	Host       string   // 小写的主机名，不含端口
+	Path       string   // URL路径，为空时为 "/"
+	Extension  string   // 路径最后一段的小写扩展名，不含点
+	Parameters []string // 排序去重后的查询参数名
+	Key        string   // 去重键：方法 + 不含参数值的URL 的哈希
+}
+
+// NormalizeURL 规范化一个 http(s) URL：协议和主机转为小写、去掉默认端口和片段、查询参数按名称排序。
+// 去重键只包含参数名，参数值不同的URL会得到相同的键。非 http(s) 或无法解析的URL返回 false。
+func NormalizeURL(raw, method string) (NormalizedURL, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return NormalizedURL{}, false
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return NormalizedURL{}, false
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return NormalizedURL{}, false
+	}
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if port != "" {
+		u.Host += ":" + port
+	}
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+
+	// 查询参数按名称排序；无法解析的查询串保持原样，参数名按 & 和 = 粗略切分
+	var params []string
+	if query, err := url.ParseQuery(u.RawQuery); err == nil {
+		for name := range query {
+			params = append(params, name)
+		}
+		u.RawQuery = query.Encode()
+	} else {
+		for _, pair := range strings.Split(u.RawQuery, "&") {
+			if name := strings.SplitN(pair, "=", 2)[0]; name != "" {
+				params = append(params, name)
+			}
+		}
+	}
+	params = uniqueSorted(params)
+
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		method = "GET"
+	}
+	base := u.Scheme + "://" + u.Host + u.EscapedPath()
+	sum := sha256.Sum256([]byte(method + " " + base + "?" + strings.Join(params, "&")))
+
+	return NormalizedURL{
+		URL:        u.String(),
+		Method:     method,
+		Host:       host,
+		Path:       u.Path,
+		Extension:  urlExtension(u.Path),
+		Parameters: params,
+		Key:        hex.EncodeToString(sum[:]),
+	}, true
+}
+
+// newEndpoint 根据原始URL构造端点，URL无效时返回 false
+func newEndpoint(raw, method, source string) (model.Endpoint, bool) {
+	n, ok := NormalizeURL(raw, method)
+	if !ok {
+		return model.Endpoint{}, false
+	}
+	return model.Endpoint{
+		URLKey:     n.Key,
+		URL:        n.URL,
+		Method:     n.Method,
+		Host:       n.Host,
+		Path:       n.Path,
+		Extension:  n.Extension,
+		Parameters: n.Parameters,
+		Source:     source,
+	}, true
+}
+
+// endpointSet 在一次解析内按去重键合并端点，后出现的响应信息补全先出现的
+type endpointSet struct {
+	index     map[string]int
+	endpoints []model.Endpoint
+}
+
+func newEndpointSet() *endpointSet {
+	return &endpointSet{index: make(map[string]int)}
+}
+
+func (s *endpointSet) add(e model.Endpoint) {
+	i, ok := s.index[e.URLKey]
+	if !ok {
+		s.index[e.URLKey] = len(s.endpoints)
+		s.endpoints = append(s.endpoints, e)
+		return
+	}
+	existing := &s.endpoints[i]
+	if existing.StatusCode == 0 {
+		existing.StatusCode = e.StatusCode
+	}
+	if existing.ContentType == "" {
+		existing.ContentType = e.ContentType
+	}
+	if existing.ContentLength == 0 {
+		existing.ContentLength = e.ContentLength
+	}
+}
+
+// urlExtension 返回路径最后一段的扩展名，以 / 结尾的目录没有扩展名
+func urlExtension(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return ""
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(path.Base(p))), ".")
+	if ext == "" || len(ext) > maxExtensionLength {
+		return ""
+	}
+	for _, r := range ext {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+	return ext
+}
+
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	out := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,458 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GenericParserType 是通用解析器的 OutputParserType，其行为由步骤的 ParserConfig 决定
+const GenericParserType = "generic"
+
+// 通用解析器支持的输出格式
+const (
+	FormatJSONArray = "json_array"
+	FormatJSONLines = "json_lines"
+	FormatCSV       = "csv"
+	FormatRegex     = "regex"
+)
+
+// GenericTargets 是通用解析器可以映射的目标属性
+var GenericTargets = []string{
+	"domain.fqdn",
+	"asset.ip", "asset.port", "asset.protocol", "asset.transport", "asset.title",
+	"asset.web_server", "asset.technologies", "asset.product", "asset.version",
+	"endpoint.url", "endpoint.method", "endpoint.status_code", "endpoint.content_type", "endpoint.content_length",
+}
+
+// 每类实体必须映射的属性，映射了该实体的任意属性时即要求映射这些属性
+var genericRequiredTargets = map[string][]string{
+	"domain":   {"domain.fqdn"},
+	"asset":    {"asset.ip", "asset.port"},
+	"endpoint": {"endpoint.url"},
+}
+
+// GenericParser 按 model.GenericParserConfig 解析输出
+type GenericParser struct {
+	config  model.GenericParserConfig
+	pattern *regexp.Regexp
+	root    []pathSegment
+	paths   map[string][]pathSegment // From -> 解析后的JSON路径
+}
+
+// NewGenericParser 校验配置并创建通用解析器
+func NewGenericParser(config *model.GenericParserConfig) (*GenericParser, error) {
+	if config == nil {
+		return nil, errors.New("通用解析器缺少 parser_config 配置")
+	}
+	p := &GenericParser{config: *config, paths: make(map[string][]pathSegment)}
+
+	switch config.Format {
+	case FormatJSONArray, FormatJSONLines:
+		root, err := parsePath(config.Root)
+		if err != nil {
+			return nil, fmt.Errorf("root 路径无效: %w", err)
+		}
+		p.root = root
+		for _, m := range config.Mappings {
+			path, err := parsePath(m.From)
+			if err != nil || len(path) == 0 {
+				return nil, fmt.Errorf("映射 '%s' 的JSON路径无效", m.From)
+			}
+			p.paths[m.From] = path
+		}
+	case FormatCSV:
+		if len([]rune(config.Delimiter)) > 1 {
+			return nil, errors.New("csv 分隔符必须是单个字符")
+		}
+		for _, m := range config.Mappings {
+			if _, err := strconv.Atoi(m.From); err != nil && !config.HasHeader {
+				return nil, fmt.Errorf("映射 '%s' 必须是列序号，使用列名需要设置 has_header", m.From)
+			}
+		}
+	case FormatRegex:
+		if config.Pattern == "" {
+			return nil, errors.New("regex 格式必须提供 pattern")
+		}
+		re, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("正则表达式无效: %w", err)
+		}
+		for _, m := range config.Mappings {
+			if regexGroupIndex(re, m.From) < 0 {
+				return nil, fmt.Errorf("正则表达式中不存在分组 '%s'", m.From)
+			}
+		}
+		p.pattern = re
+	default:
+		return nil, fmt.Errorf("不支持的格式 '%s'，可选值: %s, %s, %s, %s", config.Format, FormatJSONArray, FormatJSONLines, FormatCSV, FormatRegex)
+	}
+
+	if len(config.Mappings) == 0 {
+		return nil, errors.New("至少需要一个字段映射")
+	}
+	mapped := make(map[string]bool)
+	for _, m := range config.Mappings {
+		if m.From == "" {
+			return nil, fmt.Errorf("目标 '%s' 的映射来源为空", m.To)
+		}
+		if !isGenericTarget(m.To) {
+			return nil, fmt.Errorf("不支持的目标属性 '%s'，可选值: %s", m.To, strings.Join(GenericTargets, ", "))
+		}
+		if mapped[m.To] {
+			return nil, fmt.Errorf("目标属性 '%s' 被重复映射", m.To)
+		}
+		mapped[m.To] = true
+	}
+	for entity, required := range genericRequiredTargets {
+		if !mapsEntity(mapped, entity) {
+			continue
+		}
+		for _, target := range required {
+			if !mapped[target] {
+				return nil, fmt.Errorf("映射了 %s 的属性，但缺少必需的 '%s'", entity, target)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Parse 实现了 Parser 接口：每条记录按映射生成域名、资产和端点；
+// 同一条记录同时映射出域名和资产时，两者会被关联。缺少必需属性的记录会被跳过。
+func (p *GenericParser) Parse(output []byte) (*ParseResult, error) {
+	b := newGenericBuilder(p.source())
+	var err error
+	switch p.config.Format {
+	case FormatJSONArray:
+		err = p.parseJSONArray(output, b)
+	case FormatJSONLines:
+		err = p.parseJSONLines(output, b)
+	case FormatCSV:
+		err = p.parseCSV(output, b)
+	case FormatRegex:
+		err = p.parseRegex(output, b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.result(), nil
+}
+
+func (p *GenericParser) source() string {
+	if p.config.Source != "" {
+		return p.config.Source
+	}
+	return GenericParserType
+}
+
+func (p *GenericParser) parseJSONArray(output []byte, b *genericBuilder) error {
+	var doc interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(output), &doc); err != nil {
+		return fmt.Errorf("输出不是合法的JSON: %w", err)
+	}
+	items, ok := lookupPath(doc, p.root).([]interface{})
+	if !ok {
+		return fmt.Errorf("路径 '%s' 处不是JSON数组", p.config.Root)
+	}
+	for _, item := range items {
+		b.add(p.jsonValues(item))
+	}
+	return nil
+}
+
+func (p *GenericParser) parseJSONLines(output []byte, b *genericBuilder) error {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var doc interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			continue
+		}
+		for _, item := range jsonItems(lookupPath(doc, p.root)) {
+			b.add(p.jsonValues(item))
+		}
+	}
+	return scanner.Err()
+}
+
+func (p *GenericParser) jsonValues(item interface{}) map[string][]string {
+	values := make(map[string][]string, len(p.config.Mappings))
+	for _, m := range p.config.Mappings {
+		values[m.To] = scalarStrings(lookupPath(item, p.paths[m.From]))
+	}
+	return values
+}
+
+func (p *GenericParser) parseCSV(output []byte, b *genericBuilder) error {
+	reader := csv.NewReader(bytes.NewReader(output))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.Comment = '#'
+	if p.config.Delimiter != "" {
+		reader.Comma = []rune(p.config.Delimiter)[0]
+	}
+
+	columns := make(map[string]int)
+	first := true
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("解析CSV失败: %w", err)
+		}
+		if first && p.config.HasHeader {
+			first = false
+			for i, name := range row {
+				columns[strings.TrimSpace(name)] = i
+			}
+			continue
+		}
+		first = false
+
+		values := make(map[string][]string, len(p.config.Mappings))
+		for _, m := range p.config.Mappings {
+			index, ok := columns[m.From]
+			if !ok {
+				index, err = strconv.Atoi(m.From)
+				if err != nil {
+					continue
+				}
+			}
+			if index >= 0 && index < len(row) {
+				values[m.To] = []string{strings.TrimSpace(row[index])}
+			}
+		}
+		b.add(values)
+	}
+}
+
+func (p *GenericParser) parseRegex(output []byte, b *genericBuilder) error {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		match := p.pattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		values := make(map[string][]string, len(p.config.Mappings))
+		for _, m := range p.config.Mappings {
+			values[m.To] = []string{strings.TrimSpace(match[regexGroupIndex(p.pattern, m.From)])}
+		}
+		b.add(values)
+	}
+	return scanner.Err()
+}
+
+// genericBuilder 把映射后的记录组装为 ParseResult，并在一次解析内去重
+type genericBuilder struct {
+	source    string
+	parsed    ParseResult
+	domains   map[string]bool
+	assets    map[string]bool
+	endpoints *endpointSet
+}
+
+func newGenericBuilder(source string) *genericBuilder {
+	return &genericBuilder{
+		source:    source,
+		domains:   make(map[string]bool),
+		assets:    make(map[string]bool),
+		endpoints: newEndpointSet(),
+	}
+}
+
+func (b *genericBuilder) add(values map[string][]string) {
+	first := func(target string) string {
+		if v := values[target]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	fqdn := normalizeDomain(first("domain.fqdn"))
+	if fqdn != "" && !b.domains[fqdn] {
+		b.domains[fqdn] = true
+		b.parsed.Domains = append(b.parsed.Domains, model.Domain{FQDN: fqdn, Source: b.source})
+	}
+
+	ip := strings.Trim(first("asset.ip"), "[]")
+	port, _ := strconv.Atoi(first("asset.port"))
+	if net.ParseIP(ip) != nil && port > 0 && port <= 65535 {
+		transport := strings.ToLower(first("asset.transport"))
+		key := AssetKey(ip, port, transport)
+		if !b.assets[key] {
+			b.assets[key] = true
+			b.parsed.Assets = append(b.parsed.Assets, model.Asset{
+				IP:           ip,
+				Port:         port,
+				Protocol:     first("asset.protocol"),
+				Transport:    transport,
+				Title:        first("asset.title"),
+				WebServer:    first("asset.web_server"),
+				Technologies: values["asset.technologies"],
+				Product:      first("asset.product"),
+				Version:      first("asset.version"),
+				Source:       b.source,
+			})
+		}
+		if fqdn != "" {
+			b.parsed.Relate(AssetRef(ip, port, transport), DomainRef(fqdn))
+		}
+	}
+
+	if e, ok := newEndpoint(first("endpoint.url"), first("endpoint.method"), b.source); ok {
+		e.StatusCode, _ = strconv.Atoi(first("endpoint.status_code"))
+		e.ContentType = first("endpoint.content_type")
+		e.ContentLength, _ = strconv.ParseInt(first("endpoint.content_length"), 10, 64)
+		b.endpoints.add(e)
+	}
+}
+
+func (b *genericBuilder) result() *ParseResult {
+	b.parsed.Endpoints = b.endpoints.endpoints
+	return &b.parsed
+}
+
+// pathSegment 是JSON路径中的一段：对象的键或数组下标
+type pathSegment struct {
+	key   string
+	index int // 为 -1 时表示按键取值
+}
+
+// parsePath 解析点号分隔的JSON路径，支持可选的 "$." 前缀和 "[n]" 下标，e.g., "$.ports[0].port"
+func parsePath(path string) ([]pathSegment, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	if path == "" {
+		return nil, nil
+	}
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		var indexes []int
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			key = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("路径 '%s' 中的下标格式错误", path)
+				}
+				n, err := strconv.Atoi(rest[1:end])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("路径 '%s' 中的下标必须是非负整数", path)
+				}
+				indexes = append(indexes, n)
+				rest = rest[end+1:]
+			}
+		}
+		if key == "" && len(indexes) == 0 {
+			return nil, fmt.Errorf("路径 '%s' 中存在空的段", path)
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key, index: -1})
+		}
+		for _, n := range indexes {
+			segments = append(segments, pathSegment{index: n})
+		}
+	}
+	return segments, nil
+}
+
+// lookupPath 按路径在解码后的JSON中取值，不存在时返回 nil
+func lookupPath(value interface{}, path []pathSegment) interface{} {
+	for _, seg := range path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if seg.index >= 0 {
+				return nil
+			}
+			value = v[seg.key]
+		case []interface{}:
+			if seg.index < 0 || seg.index >= len(v) {
+				return nil
+			}
+			value = v[seg.index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// jsonItems 把JSON行的根值展开为记录：数组的每个元素各是一条记录
+func jsonItems(value interface{}) []interface{} {
+	if items, ok := value.([]interface{}); ok {
+		return items
+	}
+	if value == nil {
+		return nil
+	}
+	return []interface{}{value}
+}
+
+// scalarStrings 把JSON值转为字符串列表：标量为单个值，数组取其中的标量元素，整数不带小数点
+func scalarStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{strings.TrimSpace(v)}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if _, nested := item.([]interface{}); nested {
+				continue
+			}
+			if _, object := item.(map[string]interface{}); object {
+				continue
+			}
+			out = append(out, scalarStrings(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// regexGroupIndex 返回分组名或分组序号对应的下标，不存在时返回 -1
+func regexGroupIndex(re *regexp.Regexp, group string) int {
+	if i := re.SubexpIndex(group); i >= 0 {
+		return i
+	}
+	if i, err := strconv.Atoi(group); err == nil && i >= 0 && i <= re.NumSubexp() {
+		return i
+	}
+	return -1
+}
+
+func isGenericTarget(target string) bool {
+	for _, t := range GenericTargets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+func mapsEntity(mapped map[string]bool, entity string) bool {
+	for target := range mapped {
+		if strings.HasPrefix(target, entity+".") {
+			return true
+		}
+	}
+	return false
+}
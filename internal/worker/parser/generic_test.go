@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+)
+
+func TestGenericParser(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    model.GenericParserConfig
+		file      string
+		assets    []string
+		domains   []string
+		endpoints []string
+		rels      []string
+	}{
+		{
+			name: "json_array 按 root 取记录",
+			config: model.GenericParserConfig{Format: FormatJSONArray, Root: "results", Source: "ffuf", Mappings: []model.FieldMapping{
+				{From: "url", To: "endpoint.url"},
+				{From: "status", To: "endpoint.status_code"},
+				{From: "content-type", To: "endpoint.content_type"},
+				{From: "$.length", To: "endpoint.content_length"},
+			}},
+			file:      "ffuf.json",
+			endpoints: []string{"GET https://www.example.com/admin 301", "GET https://www.example.com/api/v1 200"},
+		},
+		{
+			name: "json_lines 映射资产、域名和数组字段",
+			config: model.GenericParserConfig{Format: FormatJSONLines, Mappings: []model.FieldMapping{
+				{From: "host", To: "asset.ip"},
+				{From: "port", To: "asset.port"},
+				{From: "scheme", To: "asset.protocol"},
+				{From: "title", To: "asset.title"},
+				{From: "tech", To: "asset.technologies"},
+				{From: "input", To: "domain.fqdn"},
+			}},
+			file:    "httpx.jsonl",
+			assets:  []string{"192.0.2.50:443/tcp", "[2001:db8::50]:8080/tcp"},
+			domains: []string{"www.example.com", "api.example.com"},
+			rels:    []string{"192.0.2.50:443/tcp www.example.com"},
+		},
+		{
+			name: "csv 按表头列名映射",
+			config: model.GenericParserConfig{Format: FormatCSV, HasHeader: true, Mappings: []model.FieldMapping{
+				{From: "ip_str", To: "asset.ip"},
+				{From: "port", To: "asset.port"},
+				{From: "transport", To: "asset.transport"},
+				{From: "product", To: "asset.product"},
+				{From: "3", To: "domain.fqdn"},
+			}},
+			file:    "shodan.csv",
+			assets:  []string{"192.0.2.60:22/tcp", "192.0.2.60:161/udp", "192.0.2.62:443/tcp"},
+			domains: []string{"ssh.example.com", "bad-port.example.com", "www.example.com"},
+			rels:    []string{"192.0.2.60:22/tcp ssh.example.com", "192.0.2.62:443/tcp www.example.com"},
+		},
+		{
+			name: "regex 使用命名分组和分组序号",
+			config: model.GenericParserConfig{Format: FormatRegex, Pattern: `^Open (?P<ip>[0-9.]+):(\d+)$`, Mappings: []model.FieldMapping{
+				{From: "ip", To: "asset.ip"},
+				{From: "2", To: "asset.port"},
+			}},
+			file:   "rustscan.txt",
+			assets: []string{"192.0.2.70:22/tcp", "192.0.2.70:80/tcp", "192.0.2.71:3306/tcp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewGenericParser(&tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := p.Parse(readTestdata(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := assetKeys(result); !equalStrings(got, tt.assets) {
+				t.Errorf("assets = %v, want %v", got, tt.assets)
+			}
+			var domains []string
+			for _, d := range result.Domains {
+				domains = append(domains, d.FQDN)
+			}
+			if !equalStrings(domains, tt.domains) {
+				t.Errorf("domains = %v, want %v", domains, tt.domains)
+			}
+			var endpoints []string
+			for _, e := range result.Endpoints {
+				endpoints = append(endpoints, fmt.Sprintf("%s %s %d", e.Method, e.URL, e.StatusCode))
+			}
+			if !equalStrings(endpoints, tt.endpoints) {
+				t.Errorf("endpoints = %v, want %v", endpoints, tt.endpoints)
+			}
+			var rels []string
+			for _, rel := range result.Relationships {
+				rels = append(rels, rel.From.Key+" "+rel.To.Key)
+			}
+			if !equalStrings(rels, tt.rels) {
+				t.Errorf("relationships = %v, want %v", rels, tt.rels)
+			}
+		})
+	}
+}
+
+func TestNewGenericParserRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *model.GenericParserConfig
+		want   string
+	}{
+		{"缺少配置", nil, "缺少 parser_config"},
+		{"未知格式", &model.GenericParserConfig{Format: "xml"}, "不支持的格式"},
+		{"无映射", &model.GenericParserConfig{Format: FormatJSONLines}, "至少需要一个字段映射"},
+		{"未知目标", &model.GenericParserConfig{Format: FormatJSONLines, Mappings: []model.FieldMapping{{From: "host", To: "asset.hostname"}}}, "不支持的目标属性"},
+		{"重复映射", &model.GenericParserConfig{Format: FormatJSONLines, Mappings: []model.FieldMapping{{From: "a", To: "domain.fqdn"}, {From: "b", To: "domain.fqdn"}}}, "被重复映射"},
+		{"缺少必需属性", &model.GenericParserConfig{Format: FormatJSONLines, Mappings: []model.FieldMapping{{From: "ip", To: "asset.ip"}}}, "缺少必需的 'asset.port'"},
+		{"JSON路径无效", &model.GenericParserConfig{Format: FormatJSONLines, Mappings: []model.FieldMapping{{From: "ports[x]", To: "domain.fqdn"}}}, "JSON路径无效"},
+		{"CSV无表头时使用列名", &model.GenericParserConfig{Format: FormatCSV, Mappings: []model.FieldMapping{{From: "host", To: "domain.fqdn"}}}, "has_header"},
+		{"CSV分隔符过长", &model.GenericParserConfig{Format: FormatCSV, Delimiter: "||", Mappings: []model.FieldMapping{{From: "0", To: "domain.fqdn"}}}, "单个字符"},
+		{"正则缺少 pattern", &model.GenericParserConfig{Format: FormatRegex, Mappings: []model.FieldMapping{{From: "1", To: "domain.fqdn"}}}, "必须提供 pattern"},
+		{"正则分组不存在", &model.GenericParserConfig{Format: FormatRegex, Pattern: `(?P<host>\S+)`, Mappings: []model.FieldMapping{{From: "name", To: "domain.fqdn"}}}, "不存在分组 'name'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenericParser(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want 包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericParserJSONArrayRootNotArray(t *testing.T) {
+	p, err := NewGenericParser(&model.GenericParserConfig{Format: FormatJSONArray, Root: "config", Mappings: []model.FieldMapping{{From: "url", To: "endpoint.url"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Parse(readTestdata(t, "ffuf.json")); err == nil {
+		t.Error("root 不是数组时应返回错误")
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"host":  "www.example.com",
+		"ports": []interface{}{map[string]interface{}{"port": float64(443)}, map[string]interface{}{"port": float64(80)}},
+		"tags":  []interface{}{"cdn", float64(1), true, []interface{}{"nested"}},
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"host", []string{"www.example.com"}},
+		{"$.host", []string{"www.example.com"}},
+		{"ports[1].port", []string{"80"}},
+		{"ports[2].port", nil},
+		{"host[0]", nil},
+		{"tags", []string{"cdn", "1", "true"}},
+		{"missing.key", nil},
+	}
+	for _, tt := range tests {
+		path, err := parsePath(tt.path)
+		if err != nil {
+			t.Fatalf("parsePath(%q): %v", tt.path, err)
+		}
+		if got := scalarStrings(lookupPath(doc, path)); !equalStrings(got, tt.want) {
+			t.Errorf("lookupPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GospiderParser 负责解析 gospider 的输出，支持JSON行 (--json) 和默认的纯文本格式
+type GospiderParser struct{}
+
+func init() {
+	Register("gospider_output", &GospiderParser{}, Info{
+		Description: "gospider 爬虫发现的URL，规范化并按参数名去重",
+		InputFormat: "JSON行 (gospider --json) 或默认的纯文本输出",
+		Entities:    []string{EntityEndpoint},
+	})
+}
+
+// gospiderOutputLine 对应 --json 输出的一行
+type gospiderOutputLine struct {
+	Output string `json:"output"`
+	Type   string `json:"type"`
+	Stat   int    `json:"stat"`
+	Length int64  `json:"length"`
+}
+
+// gospiderCode 匹配纯文本输出中的状态码，e.g., "[url] - [code-200] - https://..."
+var gospiderCode = regexp.MustCompile(`\[code-(\d{3})\]`)
+
+// Parse 实现了 Parser 接口。subdomains、aws-s3 等非URL类型的条目会被忽略。
+func (p *GospiderParser) Parse(output []byte) (*ParseResult, error) {
+	set := newEndpointSet()
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			var record gospiderOutputLine
+			if err := json.Unmarshal([]byte(line), &record); err != nil || !gospiderURLType(record.Type) {
+				continue
+			}
+			if e, ok := newEndpoint(record.Output, "GET", "gospider"); ok {
+				e.StatusCode = record.Stat
+				e.ContentLength = record.Length
+				set.add(e)
+			}
+			continue
+		}
+
+		// 纯文本格式: "[类型] - [code-200] - URL"，URL 是最后一段
+		parts := strings.Split(line, " - ")
+		kind := strings.Trim(parts[0], "[]")
+		if !gospiderURLType(kind) {
+			continue
+		}
+		e, ok := newEndpoint(parts[len(parts)-1], "GET", "gospider")
+		if !ok {
+			continue
+		}
+		if m := gospiderCode.FindStringSubmatch(line); m != nil {
+			e.StatusCode, _ = strconv.Atoi(m[1])
+		}
+		set.add(e)
+	}
+	return &ParseResult{Endpoints: set.endpoints}, scanner.Err()
+}
+
+func gospiderURLType(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "url", "href", "form", "javascript", "linkfinder", "robots", "sitemap", "other-sources":
+		return true
+	}
+	return false
+}
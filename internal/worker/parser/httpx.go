@@ -4,16 +4,22 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"github.com/src-hunter/internal/cdn"
 	"github.com/src-hunter/internal/model"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // HttpxParser 负责解析 httpx 的输出
 type HttpxParser struct{}
 
 func init() {
-	Register("httpx_json_list", &HttpxParser{})
+	Register("httpx_json_list", &HttpxParser{}, Info{
+		Description: "httpx Web探测结果，每个解析到的IP生成一条带标题、Web服务器、技术栈和CDN标记的资产",
+		InputFormat: "JSON行 (httpx -json)",
+		Entities:    []string{EntityAsset},
+	})
 }
 
 // httpxOutputLine 结构体与 httpx 的实际输出完全匹配
@@ -25,12 +31,28 @@ type httpxOutputLine struct {
 	A         []string `json:"a"`    // IPv4 地址列表
 	Aaaa      []string `json:"aaaa"` // IPv6 地址列表
 	Port      string   `json:"port"`
+	// 以下字段需要 httpx 开启 -cdn 和 -irh (包含响应头)
+	CDN     bool              `json:"cdn"`
+	CDNName string            `json:"cdn_name"`
+	CDNType string            `json:"cdn_type"` // cdn, waf, cloud
+	Header  map[string]string `json:"header"`
+}
+
+// cdnProvider 返回响应经过的CDN/WAF服务商：优先使用 httpx 的识别结果，其次按响应头识别。
+// httpx 识别为普通云服务商 (cloud) 的不算CDN。
+func (l *httpxOutputLine) cdnProvider() string {
+	if l.CDN && l.CDNName != "" && l.CDNType != "cloud" {
+		return strings.ToLower(l.CDNName)
+	}
+	return cdn.MatchHeaders(l.Header)
 }
 
 // Parse 实现了 Parser 接口，现在会为每个IP创建一条资产记录
 func (p *HttpxParser) Parse(output []byte) (*ParseResult, error) {
 	var assets []model.Asset
 	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// 响应头较多或带有 -include-response 时，单行可能远超默认的 64KB
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
 
 	for scanner.Scan() {
 		var line httpxOutputLine
@@ -44,6 +66,7 @@ func (p *HttpxParser) Parse(output []byte) (*ParseResult, error) {
 		}
 		protocol := parsedURL.Scheme
 		portInt, _ := strconv.Atoi(line.Port)
+		provider := line.cdnProvider()
 
 		// 将所有发现的IP地址收集到一个切片中
 		allIPs := []string{}
@@ -66,6 +89,10 @@ func (p *HttpxParser) Parse(output []byte) (*ParseResult, error) {
 				Title:        line.Title,
 				WebServer:    line.WebServer,
 				Technologies: line.Tech,
+				CDNProvider:  provider,
+			}
+			if asset.CDNProvider == "" {
+				asset.CDNProvider = cdn.MatchIP(ip)
 			}
 			assets = append(assets, asset)
 		}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// KatanaParser 负责解析 katana 的输出，支持JSON行 (-jsonl) 和每行一个URL的纯文本输出
+type KatanaParser struct{}
+
+func init() {
+	Register("katana_jsonl", &KatanaParser{}, Info{
+		Description: "katana 爬虫发现的URL及响应信息，规范化并按参数名去重",
+		InputFormat: "JSON行 (katana -jsonl) 或每行一个URL",
+		Entities:    []string{EntityEndpoint},
+	})
+}
+
+// katanaOutputLine 只映射了解析需要的字段
+type katanaOutputLine struct {
+	Request struct {
+		Method   string `json:"method"`
+		Endpoint string `json:"endpoint"`
+	} `json:"request"`
+	Response struct {
+		StatusCode    int               `json:"status_code"`
+		Headers       map[string]string `json:"headers"`
+		ContentLength int64             `json:"content_length"`
+	} `json:"response"`
+}
+
+// Parse 实现了 Parser 接口
+func (p *KatanaParser) Parse(output []byte) (*ParseResult, error) {
+	set := newEndpointSet()
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// 响应中可能带有完整的页面内容
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' {
+			if e, ok := newEndpoint(string(line), "GET", "katana"); ok {
+				set.add(e)
+			}
+			continue
+		}
+
+		var record katanaOutputLine
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		e, ok := newEndpoint(record.Request.Endpoint, record.Request.Method, "katana")
+		if !ok {
+			continue
+		}
+		e.StatusCode = record.Response.StatusCode
+		e.ContentLength = record.Response.ContentLength
+		e.ContentType = headerValue(record.Response.Headers, "content-type")
+		set.add(e)
+	}
+	return &ParseResult{Endpoints: set.endpoints}, scanner.Err()
+}
+
+// headerValue 不区分大小写地查找响应头，兼容 Content-Type 和 content_type 两种写法
+func headerValue(headers map[string]string, name string) string {
+	want := strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	for k, v := range headers {
+		if strings.ReplaceAll(strings.ToLower(k), "-", "_") == want {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/src-hunter/internal/model"
+	"strconv"
+	"strings"
+)
+
+// MasscanParser 负责解析 masscan 的输出，同时支持JSON (-oJ) 和列表 (-oL) 两种格式
+type MasscanParser struct{}
+
+func init() {
+	Register("masscan_json", &MasscanParser{}, Info{
+		Description: "masscan 端口扫描结果",
+		InputFormat: "JSON (masscan -oJ)",
+		Entities:    []string{EntityAsset},
+	})
+	Register("masscan_list", &MasscanParser{}, Info{
+		Description: "masscan 端口扫描结果",
+		InputFormat: "列表 (masscan -oL)",
+		Entities:    []string{EntityAsset},
+	})
+}
+
+// masscanRecord 对应 -oJ 输出中的一个对象
+type masscanRecord struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port   int    `json:"port"`
+		Proto  string `json:"proto"`
+		Status string `json:"status"`
+	} `json:"ports"`
+}
+
+// Parse 实现了 Parser 接口。
+// masscan 的JSON输出每行一个对象，旧版本还会留下多余的逗号，因此逐行解析而不是整体反序列化。
+func (p *MasscanParser) Parse(output []byte) (*ParseResult, error) {
+	result := &ParseResult{}
+	seen := make(map[string]bool)
+	add := func(ip string, port int, transport string) {
+		ip = normalizeIP(ip)
+		key := AssetKey(ip, port, transport)
+		if ip == "" || port <= 0 || seen[key] {
+			return
+		}
+		seen[key] = true
+		result.Assets = append(result.Assets, model.Asset{
+			IP:        ip,
+			Port:      port,
+			Transport: transport,
+			Source:    "masscan",
+		})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "[" || line == "]" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			var record masscanRecord
+			if err := json.Unmarshal([]byte(strings.TrimSuffix(line, ",")), &record); err != nil {
+				continue
+			}
+			for _, port := range record.Ports {
+				if port.Status == "" || port.Status == "open" {
+					add(record.IP, port.Port, port.Proto)
+				}
+			}
+			continue
+		}
+
+		// 列表格式: open tcp 80 1.2.3.4 1700000000
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] != "open" {
+			continue
+		}
+		port, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		add(fields[3], port, fields[1])
+	}
+
+	return result, scanner.Err()
+}
@@ -0,0 +1,38 @@
+package parser
+
+import "testing"
+
+func TestMasscanParser(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "列表格式",
+			file: "masscan.list",
+			want: []string{"192.0.2.10:80/tcp", "192.0.2.10:443/tcp", "192.0.2.10:53/udp", "[2001:db8::10]:22/tcp"},
+		},
+		{
+			name: "JSON格式",
+			file: "masscan.json",
+			want: []string{"192.0.2.20:443/tcp", "192.0.2.20:53/udp", "192.0.2.22:8443/tcp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := (&MasscanParser{}).Parse(readTestdata(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := assetKeys(result); !equalStrings(got, tt.want) {
+				t.Errorf("assets = %v, want %v", got, tt.want)
+			}
+			for _, a := range result.Assets {
+				if a.Source != "masscan" {
+					t.Errorf("Source = %q", a.Source)
+				}
+			}
+		})
+	}
+}
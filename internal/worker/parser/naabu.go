@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/src-hunter/internal/model"
+	"strings"
+)
+
+// NaabuParser 负责解析 naabu 的JSON行输出 (-json)
+type NaabuParser struct{}
+
+func init() {
+	Register("naabu_json_list", &NaabuParser{}, Info{
+		Description: "naabu 端口扫描结果；扫描目标为域名时同时生成域名并与资产关联",
+		InputFormat: "JSON行 (naabu -json)",
+		Entities:    []string{EntityAsset, EntityDomain, EntityRelationship},
+	})
+}
+
+// naabuOutputLine 对应 naabu 的一行输出。
+// 新版本的 port 是数字并带有 protocol 字段，旧版本的 port 是包含端口和协议的对象，两种都兼容。
+type naabuOutputLine struct {
+	Host     string          `json:"host"`
+	IP       string          `json:"ip"`
+	Port     json.RawMessage `json:"port"`
+	Protocol string          `json:"protocol"`
+}
+
+// naabuLegacyPort 的 Protocol 在旧版本中是枚举值 (0: tcp, 1: udp)
+type naabuLegacyPort struct {
+	Port     int `json:"Port"`
+	Protocol int `json:"Protocol"`
+}
+
+// Parse 实现了 Parser 接口：每行生成一条资产；扫描目标为域名时同时生成域名并与资产关联
+func (p *NaabuParser) Parse(output []byte) (*ParseResult, error) {
+	result := &ParseResult{}
+	seenAssets := make(map[string]bool)
+	seenDomains := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var line naabuOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		ip := normalizeIP(line.IP)
+		if ip == "" {
+			ip = normalizeIP(line.Host)
+		}
+		port, transport := parseNaabuPort(line.Port, line.Protocol)
+		if ip == "" || port <= 0 {
+			continue
+		}
+
+		key := AssetKey(ip, port, transport)
+		if !seenAssets[key] {
+			seenAssets[key] = true
+			result.Assets = append(result.Assets, model.Asset{
+				IP:        ip,
+				Port:      port,
+				Transport: transport,
+				Source:    "naabu",
+			})
+		}
+
+		host := normalizeDomain(line.Host)
+		if host == "" {
+			continue
+		}
+		if !seenDomains[host] {
+			seenDomains[host] = true
+			result.Domains = append(result.Domains, model.Domain{FQDN: host, Source: "naabu"})
+		}
+		result.Relate(AssetRef(ip, port, transport), DomainRef(host))
+	}
+
+	return result, scanner.Err()
+}
+
+// parseNaabuPort 兼容新旧两种 port 字段，返回端口号和传输层协议 (默认为 tcp)
+func parseNaabuPort(raw json.RawMessage, protocol string) (int, string) {
+	var port int
+	if err := json.Unmarshal(raw, &port); err != nil {
+		var legacy naabuLegacyPort
+		if err := json.Unmarshal(raw, &legacy); err != nil {
+			return 0, ""
+		}
+		port = legacy.Port
+		if protocol == "" && legacy.Protocol == 1 {
+			protocol = "udp"
+		}
+	}
+	protocol = strings.ToLower(protocol)
+	if protocol != "udp" {
+		protocol = "tcp"
+	}
+	return port, protocol
+}
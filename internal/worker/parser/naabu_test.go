@@ -0,0 +1,62 @@
+package parser
+
+import "testing"
+
+func TestNaabuParser(t *testing.T) {
+	result, err := (&NaabuParser{}).Parse(readTestdata(t, "naabu.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantAssets := []string{"192.0.2.30:443/tcp", "192.0.2.30:80/tcp", "192.0.2.31:53/udp", "192.0.2.32:8080/tcp"}
+	if got := assetKeys(result); !equalStrings(got, wantAssets) {
+		t.Errorf("assets = %v, want %v", got, wantAssets)
+	}
+
+	var domains []string
+	for _, d := range result.Domains {
+		domains = append(domains, d.FQDN)
+	}
+	if want := []string{"www.example.com", "legacy.example.com"}; !equalStrings(domains, want) {
+		t.Errorf("domains = %v, want %v", domains, want)
+	}
+
+	tests := []struct {
+		from, to string
+	}{
+		{"192.0.2.30:443/tcp", "www.example.com"},
+		{"192.0.2.30:80/tcp", "www.example.com"},
+		{"192.0.2.32:8080/tcp", "legacy.example.com"},
+	}
+	if len(result.Relationships) != len(tests) {
+		t.Fatalf("relationships = %v", result.Relationships)
+	}
+	for i, tt := range tests {
+		rel := result.Relationships[i]
+		if rel.From != (EntityRef{Type: EntityAsset, Key: tt.from}) || rel.To != DomainRef(tt.to) {
+			t.Errorf("relationship[%d] = %+v, want %s -> %s", i, rel, tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseNaabuPort(t *testing.T) {
+	tests := []struct {
+		raw       string
+		protocol  string
+		port      int
+		transport string
+	}{
+		{`443`, "tcp", 443, "tcp"},
+		{`53`, "UDP", 53, "udp"},
+		{`80`, "", 80, "tcp"},
+		{`{"Port":161,"Protocol":1,"TLS":false}`, "", 161, "udp"},
+		{`{"Port":8080,"Protocol":0,"TLS":false}`, "", 8080, "tcp"},
+		{`"http"`, "", 0, ""},
+	}
+	for _, tt := range tests {
+		port, transport := parseNaabuPort([]byte(tt.raw), tt.protocol)
+		if port != tt.port || transport != tt.transport {
+			t.Errorf("parseNaabuPort(%s, %q) = %d, %q, want %d, %q", tt.raw, tt.protocol, port, transport, tt.port, tt.transport)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"slices"
+)
+
+// NmapParser 负责解析 nmap 的XML输出 (-oX -)
+type NmapParser struct{}
+
+func init() {
+	Register("nmap_xml", &NmapParser{}, Info{
+		Description: "nmap 端口和服务识别结果，开放端口生成资产，主机名生成域名并与资产关联",
+		InputFormat: "XML (nmap -oX -)",
+		Entities:    []string{EntityAsset, EntityDomain, EntityRelationship},
+	})
+}
+
+// nmapRun 只映射了解析需要的部分XML结构
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Status    nmapStatus     `xml:"status"`
+	Addresses []nmapAddress  `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+type nmapStatus struct {
+	State string `xml:"state,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"` // ipv4, ipv6, mac
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"` // user (命令行指定), PTR (反向解析)
+}
+
+type nmapPort struct {
+	Protocol string      `xml:"protocol,attr"`
+	PortID   int         `xml:"portid,attr"`
+	State    nmapStatus  `xml:"state"`
+	Service  nmapService `xml:"service"`
+}
+
+type nmapService struct {
+	Name    string `xml:"name,attr"`
+	Product string `xml:"product,attr"`
+	Version string `xml:"version,attr"`
+}
+
+// Parse 实现了 Parser 接口：每个主机的每个开放端口生成一条资产，
+// 主机名 (用户指定或PTR) 生成域名并与该主机的开放端口关联
+func (p *NmapParser) Parse(output []byte) (*ParseResult, error) {
+	var run nmapRun
+	if err := xml.NewDecoder(bytes.NewReader(output)).Decode(&run); err != nil {
+		return nil, fmt.Errorf("解析nmap XML输出失败: %w", err)
+	}
+
+	result := &ParseResult{}
+	seenDomains := make(map[string]bool)
+	for _, host := range run.Hosts {
+		var ips []string
+		for _, addr := range host.Addresses {
+			// MAC 地址不是资产，跳过
+			if addr.AddrType == "ipv4" || addr.AddrType == "ipv6" {
+				ips = append(ips, addr.Addr)
+			}
+		}
+		if len(ips) == 0 {
+			continue
+		}
+
+		// 用户指定的主机名和PTR记录规范化后可能相同，每个主机只关联一次
+		var hostnames []string
+		for _, h := range host.Hostnames {
+			name := normalizeDomain(h.Name)
+			if name == "" || slices.Contains(hostnames, name) {
+				continue
+			}
+			hostnames = append(hostnames, name)
+			if !seenDomains[name] {
+				seenDomains[name] = true
+				result.Domains = append(result.Domains, model.Domain{FQDN: name, Source: "nmap"})
+			}
+		}
+
+		for _, port := range host.Ports {
+			if port.State.State != "open" {
+				continue
+			}
+			for _, ip := range ips {
+				result.Assets = append(result.Assets, model.Asset{
+					IP:        ip,
+					Port:      port.PortID,
+					Protocol:  port.Service.Name,
+					Transport: port.Protocol,
+					Product:   port.Service.Product,
+					Version:   port.Service.Version,
+					HostState: host.Status.State,
+					Source:    "nmap",
+				})
+				for _, name := range hostnames {
+					result.Relate(AssetRef(ip, port.PortID, port.Protocol), DomainRef(name))
+				}
+			}
+		}
+	}
+	return result, nil
+}
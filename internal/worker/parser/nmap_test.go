@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/src-hunter/internal/model"
+)
+
+func TestNmapParser(t *testing.T) {
+	result, err := (&NmapParser{}).Parse(readTestdata(t, "nmap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []model.Asset{
+		{IP: "192.0.2.40", Port: 22, Protocol: "ssh", Transport: "tcp", Product: "OpenSSH", Version: "9.6p1 Ubuntu 3ubuntu13", HostState: "up", Source: "nmap"},
+		{IP: "192.0.2.40", Port: 53, Protocol: "domain", Transport: "tcp", Product: "ISC BIND", Version: "9.18.24", HostState: "up", Source: "nmap"},
+		{IP: "192.0.2.40", Port: 53, Protocol: "domain", Transport: "udp", Product: "ISC BIND", Version: "9.18.24", HostState: "up", Source: "nmap"},
+		{IP: "192.0.2.41", Port: 80, Protocol: "http", Transport: "tcp", Product: "nginx", Version: "1.24.0", HostState: "up", Source: "nmap"},
+	}
+	if len(result.Assets) != len(want) {
+		t.Fatalf("assets = %v, want %d 个开放端口", assetKeys(result), len(want))
+	}
+	for i, w := range want {
+		a := result.Assets[i]
+		if a.IP != w.IP || a.Port != w.Port || a.Protocol != w.Protocol || a.Transport != w.Transport ||
+			a.Product != w.Product || a.Version != w.Version || a.HostState != w.HostState || a.Source != w.Source {
+			t.Errorf("assets[%d] = %+v, want %+v", i, a, w)
+		}
+	}
+
+	// 用户指定和PTR的主机名规范化后是同一个域名
+	if len(result.Domains) != 1 || result.Domains[0].FQDN != "ns1.example.com" {
+		t.Errorf("domains = %+v, want [ns1.example.com]", result.Domains)
+	}
+	var related []string
+	for _, rel := range result.Relationships {
+		if rel.To != DomainRef("ns1.example.com") {
+			t.Errorf("relationship %+v 应指向 ns1.example.com", rel)
+		}
+		related = append(related, rel.From.Key)
+	}
+	wantRelated := []string{"192.0.2.40:22/tcp", "192.0.2.40:53/tcp", "192.0.2.40:53/udp"}
+	if !equalStrings(related, wantRelated) {
+		t.Errorf("related assets = %v, want %v", related, wantRelated)
+	}
+}
+
+func TestNmapParserInvalidXML(t *testing.T) {
+	if _, err := (&NmapParser{}).Parse([]byte("Starting Nmap 7.94 ( https://nmap.org )")); err == nil {
+		t.Error("非XML输出应返回错误")
+	}
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/src-hunter/internal/model"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxEvidenceSize 限制单条请求/响应证据的长度，避免大响应体撑爆数据库
+const maxEvidenceSize = 64 * 1024
+
+// NucleiParser 负责解析 nuclei 的JSON行输出 (-jsonl)
+type NucleiParser struct{}
+
+func init() {
+	Register("nuclei_jsonl", &NucleiParser{}, Info{
+		Description: "nuclei 漏洞扫描结果，按模板、匹配器、匹配位置和提取结果生成去重指纹",
+		InputFormat: "JSON行 (nuclei -jsonl)",
+		Entities:    []string{EntityFinding},
+	})
+}
+
+// nucleiOutputLine 对应 nuclei 的一行输出，port 在不同版本中可能是字符串或数字
+type nucleiOutputLine struct {
+	TemplateID string `json:"template-id"`
+	Info       struct {
+		Name     string `json:"name"`
+		Severity string `json:"severity"`
+	} `json:"info"`
+	Type             string          `json:"type"`
+	Host             string          `json:"host"`
+	IP               string          `json:"ip"`
+	Port             json.RawMessage `json:"port"`
+	MatchedAt        string          `json:"matched-at"`
+	MatcherName      string          `json:"matcher-name"`
+	ExtractedResults []string        `json:"extracted-results"`
+	Request          string          `json:"request"`
+	Response         string          `json:"response"`
+}
+
+// Parse 实现了 Parser 接口，每行生成一条漏洞发现，同一批次内指纹相同的只保留一条
+func (p *NucleiParser) Parse(output []byte) (*ParseResult, error) {
+	result := &ParseResult{}
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// nuclei 的输出行带有完整的请求和响应，可能远超默认的 64KB
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line nucleiOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.TemplateID == "" {
+			continue
+		}
+
+		host, port := nucleiTarget(line.Host, line.MatchedAt)
+		if n, err := strconv.Atoi(strings.Trim(string(line.Port), `"`)); err == nil && n > 0 {
+			port = n
+		}
+		ip := line.IP
+		if ip == "" && net.ParseIP(host) != nil {
+			ip = host
+		}
+
+		finding := model.Finding{
+			Fingerprint:      FindingFingerprint(line.TemplateID, line.MatcherName, line.MatchedAt, line.ExtractedResults),
+			TemplateID:       line.TemplateID,
+			Name:             line.Info.Name,
+			Severity:         strings.ToLower(line.Info.Severity),
+			Type:             line.Type,
+			Host:             host,
+			IP:               ip,
+			Port:             port,
+			MatchedAt:        line.MatchedAt,
+			MatcherName:      line.MatcherName,
+			ExtractedResults: line.ExtractedResults,
+			Request:          truncateEvidence(line.Request),
+			Response:         truncateEvidence(line.Response),
+		}
+		if seen[finding.Fingerprint] {
+			continue
+		}
+		seen[finding.Fingerprint] = true
+		result.Findings = append(result.Findings, finding)
+	}
+
+	return result, scanner.Err()
+}
+
+// FindingFingerprint 计算漏洞发现的去重指纹。
+// 只使用跨运行稳定的字段，请求/响应等每次都会变化的证据不参与计算。
+func FindingFingerprint(templateID, matcherName, matchedAt string, extracted []string) string {
+	sorted := append([]string(nil), extracted...)
+	sort.Strings(sorted)
+	h := sha256.New()
+	for _, part := range append([]string{templateID, matcherName, matchedAt}, sorted...) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// nucleiTarget 从 host 或 matched-at (URL 或 host:port) 中取出小写的主机名和端口
+func nucleiTarget(host, matchedAt string) (string, int) {
+	for _, raw := range []string{host, matchedAt} {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if strings.Contains(raw, "://") {
+			u, err := url.Parse(raw)
+			if err != nil || u.Hostname() == "" {
+				continue
+			}
+			port, _ := strconv.Atoi(u.Port())
+			if port == 0 {
+				switch u.Scheme {
+				case "https":
+					port = 443
+				case "http":
+					port = 80
+				}
+			}
+			return normalizeHost(u.Hostname()), port
+		}
+		if h, p, err := net.SplitHostPort(raw); err == nil {
+			port, _ := strconv.Atoi(p)
+			return normalizeHost(h), port
+		}
+		return normalizeHost(raw), 0
+	}
+	return "", 0
+}
+
+// truncateEvidence 截断过长的证据，并去掉 PostgreSQL 文本列不接受的NUL字节和非法UTF-8
+func truncateEvidence(s string) string {
+	if len(s) > maxEvidenceSize {
+		s = s[:maxEvidenceSize] + "\n...[truncated]"
+	}
+	return strings.ToValidUTF8(strings.ReplaceAll(s, "\x00", ""), "")
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNucleiParser(t *testing.T) {
+	result, err := (&NucleiParser{}).Parse(readTestdata(t, "nuclei.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		templateID string
+		severity   string
+		host       string
+		ip         string
+		port       int
+		extracted  int
+	}{
+		{"tech-detect", "info", "www.example.com", "192.0.2.50", 443, 0},
+		{"openssh-detect", "info", "192.0.2.51", "192.0.2.51", 22, 1},
+		{"git-config", "medium", "app.example.com", "192.0.2.52", 80, 0},
+	}
+	// 重复扫描得到的同一发现 (指纹相同) 只保留一条
+	if len(result.Findings) != len(tests) {
+		t.Fatalf("findings = %d, want %d", len(result.Findings), len(tests))
+	}
+	for i, tt := range tests {
+		f := result.Findings[i]
+		if f.TemplateID != tt.templateID || f.Severity != tt.severity || f.Host != tt.host ||
+			f.IP != tt.ip || f.Port != tt.port || len(f.ExtractedResults) != tt.extracted {
+			t.Errorf("findings[%d] = %+v, want %+v", i, f, tt)
+		}
+		if f.Fingerprint != FindingFingerprint(f.TemplateID, f.MatcherName, f.MatchedAt, f.ExtractedResults) {
+			t.Errorf("findings[%d] 的指纹与 FindingFingerprint 不一致", i)
+		}
+	}
+	if !strings.HasPrefix(result.Findings[0].Request, "GET / HTTP/1.1") {
+		t.Errorf("Request = %q", result.Findings[0].Request)
+	}
+}
+
+func TestFindingFingerprint(t *testing.T) {
+	base := FindingFingerprint("tech-detect", "nginx", "https://a.example.com", []string{"a", "b"})
+	tests := []struct {
+		name  string
+		other string
+		same  bool
+	}{
+		{"提取结果顺序无关", FindingFingerprint("tech-detect", "nginx", "https://a.example.com", []string{"b", "a"}), true},
+		{"匹配器不同", FindingFingerprint("tech-detect", "apache", "https://a.example.com", []string{"a", "b"}), false},
+		{"匹配位置不同", FindingFingerprint("tech-detect", "nginx", "https://b.example.com", []string{"a", "b"}), false},
+		{"字段边界不混淆", FindingFingerprint("tech-detectn", "ginx", "https://a.example.com", []string{"a", "b"}), false},
+	}
+	for _, tt := range tests {
+		if (tt.other == base) != tt.same {
+			t.Errorf("%s: same = %v, want %v", tt.name, tt.other == base, tt.same)
+		}
+	}
+}
+
+func TestNucleiTarget(t *testing.T) {
+	tests := []struct {
+		host, matchedAt string
+		wantHost        string
+		wantPort        int
+	}{
+		{"https://Example.com:8443", "", "example.com", 8443},
+		{"https://example.com", "", "example.com", 443},
+		{"", "http://example.com/login", "example.com", 80},
+		{"192.0.2.1:22", "", "192.0.2.1", 22},
+		{"[2001:db8::1]:443", "", "2001:db8::1", 443},
+		{"example.com", "", "example.com", 0},
+		{"", "", "", 0},
+	}
+	for _, tt := range tests {
+		host, port := nucleiTarget(tt.host, tt.matchedAt)
+		if host != tt.wantHost || port != tt.wantPort {
+			t.Errorf("nucleiTarget(%q, %q) = %q, %d, want %q, %d", tt.host, tt.matchedAt, host, port, tt.wantHost, tt.wantPort)
+		}
+	}
+}
+
+func TestTruncateEvidence(t *testing.T) {
+	long := strings.Repeat("a", maxEvidenceSize+10)
+	if got := truncateEvidence(long); len(got) != maxEvidenceSize+len("\n...[truncated]") {
+		t.Errorf("截断后长度 = %d", len(got))
+	}
+	if got := truncateEvidence("a\x00b\xffc"); got != "abc" {
+		t.Errorf("truncateEvidence = %q, want %q", got, "abc")
+	}
+}
@@ -1,15 +1,94 @@
 package parser
 
-import "github.com/src-hunter/internal/model"
+import (
+	"github.com/src-hunter/internal/model"
+	"net"
+	"strconv"
+	"strings"
+)
 
-// ParseResult 封装了解析后的标准化数据
+// ParseResult 封装了解析后的标准化数据：按类型分组的实体，以及实体之间的关系。
+// 实体由 ingest 包中按类型注册的持久化器保存，新增实体类型只需增加字段和对应的持久化器。
 type ParseResult struct {
-	Domains []model.Domain // 解析出的域名
-	Assets  []model.Asset  // 解析出的资产 (IP+端口)
+	Domains       []model.Domain      // 解析出的域名
+	Assets        []model.Asset       // 解析出的资产 (IP+端口)
+	Findings      []model.Finding     // 解析出的漏洞发现
+	Endpoints     []model.Endpoint    // 解析出的Web端点
+	DNSRecords    []model.DNSRecord   // 解析出的DNS记录
+	Certificates  []model.Certificate // 解析出的TLS证书
+	Relationships []Relationship      // 解析出的实体之间的关系
+}
+
+// EntityRef 通过实体类型和业务键引用一个实体，持久化时再解析为数据库ID
+type EntityRef struct {
+	Type string // 实体类型，e.g., EntityDomain
+	Key  string // 业务键：域名为 FQDN，资产为 IP:端口/传输层协议
+}
+
+// DomainRef 返回域名的引用
+func DomainRef(fqdn string) EntityRef {
+	return EntityRef{Type: EntityDomain, Key: fqdn}
+}
+
+// AssetRef 返回资产的引用，IPv6 地址会加上方括号
+func AssetRef(ip string, port int, transport string) EntityRef {
+	return EntityRef{Type: EntityAsset, Key: AssetKey(ip, port, transport)}
+}
+
+// NormalizeTransport 返回小写的传输层协议，未识别到时视为 tcp
+func NormalizeTransport(transport string) string {
+	transport = strings.ToLower(strings.TrimSpace(transport))
+	if transport == "" {
+		return model.TransportTCP
+	}
+	return transport
+}
+
+// AssetKey 返回资产的业务键 IP:端口/传输层协议，同一端口的 tcp 和 udp 是不同的资产
+func AssetKey(ip string, port int, transport string) string {
+	return net.JoinHostPort(ip, strconv.Itoa(port)) + "/" + NormalizeTransport(transport)
+}
+
+// SplitAssetKey 把 AssetKey 拆分回 IP、端口和传输层协议
+func SplitAssetKey(key string) (string, int, string, bool) {
+	hostPort, transport, ok := strings.Cut(key, "/")
+	if !ok {
+		return "", 0, "", false
+	}
+	ip, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", 0, "", false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, "", false
+	}
+	return ip, port, transport, true
+}
+
+// normalizeIP 去掉IP两侧的空白和方括号，不是合法的IP地址时返回空字符串。
+// 资产的IP会在搜索时转换为 inet 类型比较，非法的值不能入库。
+func normalizeIP(s string) string {
+	ip := strings.Trim(strings.TrimSpace(s), "[]")
+	if net.ParseIP(ip) == nil {
+		return ""
+	}
+	return ip
+}
+
+// Relationship 描述两个实体之间的关系 (如 nmap 的 PTR 记录把资产关联到域名)
+type Relationship struct {
+	From EntityRef
+	To   EntityRef
+}
+
+// Relate 记录一条实体之间的关系
+func (r *ParseResult) Relate(from, to EntityRef) {
+	r.Relationships = append(r.Relationships, Relationship{From: from, To: to})
 }
 
 // Parser 是所有输出解析器都必须实现的接口
 type Parser interface {
-	// Parse 接受命令的原始输出，返回一个标准化的ParseResult
+	// Parse 接受命令的原始标准输出，返回一个标准化的ParseResult
 	Parse(output []byte) (*ParseResult, error)
 }
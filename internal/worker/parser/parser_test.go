@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readTestdata 读取 testdata 目录下的工具输出样例
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// assetKeys 返回解析出的资产的业务键，便于整体比较
+func assetKeys(result *ParseResult) []string {
+	keys := make([]string, 0, len(result.Assets))
+	for _, a := range result.Assets {
+		keys = append(keys, AssetKey(a.IP, a.Port, a.Transport))
+	}
+	return keys
+}
+
+// equalStrings 按顺序比较两个字符串切片
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAssetKey(t *testing.T) {
+	tests := []struct {
+		ip        string
+		port      int
+		transport string
+		want      string
+	}{
+		{"192.0.2.1", 80, "tcp", "192.0.2.1:80/tcp"},
+		{"192.0.2.1", 53, "UDP", "192.0.2.1:53/udp"},
+		{"192.0.2.1", 443, "", "192.0.2.1:443/tcp"},
+		{"2001:db8::1", 443, "tcp", "[2001:db8::1]:443/tcp"},
+	}
+	for _, tt := range tests {
+		key := AssetKey(tt.ip, tt.port, tt.transport)
+		if key != tt.want {
+			t.Errorf("AssetKey(%q, %d, %q) = %q, want %q", tt.ip, tt.port, tt.transport, key, tt.want)
+		}
+		ip, port, transport, ok := SplitAssetKey(key)
+		if !ok || ip != tt.ip || port != tt.port || transport != NormalizeTransport(tt.transport) {
+			t.Errorf("SplitAssetKey(%q) = %q, %d, %q, %v", key, ip, port, transport, ok)
+		}
+	}
+	for _, key := range []string{"192.0.2.1:80", "192.0.2.1/tcp", "192.0.2.1:http/tcp"} {
+		if _, _, _, ok := SplitAssetKey(key); ok {
+			t.Errorf("SplitAssetKey(%q) 应失败", key)
+		}
+	}
+}
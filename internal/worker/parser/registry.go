@@ -1,24 +1,108 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"sort"
+)
+
+// 解析器可以产出的实体类型
+const (
+	EntityDomain       = "domain"
+	EntityAsset        = "asset"
+	EntityRelationship = "relationship"
+	EntityFinding      = "finding"
+	EntityEndpoint     = "endpoint"
+	EntityDNSRecord    = "dns_record"
+	EntityCertificate  = "certificate"
+)
+
+// Info 描述一个解析器，供构建扫描模板时查阅
+type Info struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	InputFormat string   `json:"inputFormat"` // 期望的工具输出格式
+	Entities    []string `json:"entities"`    // 可能产出的实体类型
+	// Configurable 表示解析器需要步骤提供 parser_config
+	Configurable bool `json:"configurable"`
+}
+
+// genericInfo 描述通用解析器，它不在注册表中，而是按步骤的配置创建
+var genericInfo = Info{
+	Name:         GenericParserType,
+	Description:  "通用解析器，按步骤的 parser_config 把输出中的字段映射为域名、资产和端点",
+	InputFormat:  "JSON数组、JSON行、CSV 或逐行正则匹配，由 parser_config.format 指定",
+	Entities:     []string{EntityDomain, EntityAsset, EntityRelationship, EntityEndpoint},
+	Configurable: true,
+}
+
+type registration struct {
+	parser Parser
+	info   Info
+}
 
 // registry 是一个全局的map，用于存储所有已注册的解析器
-var registry = make(map[string]Parser)
+var registry = make(map[string]registration)
 
 // Register 用于向注册表注册一个新的解析器
-func Register(name string, p Parser) {
-	if _, exists := registry[name]; exists {
+func Register(name string, p Parser, info Info) {
+	if _, exists := registry[name]; exists || name == GenericParserType {
 		// 防止重复注册
 		panic(fmt.Sprintf("解析器名称 '%s' 已被注册", name))
 	}
-	registry[name] = p
+	info.Name = name
+	registry[name] = registration{parser: p, info: info}
+}
+
+// ForStep 返回工作流步骤使用的解析器：通用解析器按步骤的配置创建，其余从注册表中获取
+func ForStep(step *model.WorkflowStep) (Parser, error) {
+	if step.OutputParserType == GenericParserType {
+		if step.ParserConfig != nil && step.ParserConfig.Source == "" {
+			config := *step.ParserConfig
+			config.Source = step.Name
+			return NewGenericParser(&config)
+		}
+		return NewGenericParser(step.ParserConfig)
+	}
+	return Get(step.OutputParserType)
+}
+
+// ValidateStep 在保存扫描模板时校验步骤的解析器配置，未指定解析器的步骤不做校验
+func ValidateStep(step *model.WorkflowStep) error {
+	if step.OutputParserType == "" {
+		return nil
+	}
+	if _, err := ForStep(step); err != nil {
+		return fmt.Errorf("步骤 '%s' 的解析器配置无效: %w", step.Name, err)
+	}
+	return nil
 }
 
 // Get 根据名称从注册表中获取一个解析器实例
 func Get(name string) (Parser, error) {
-	p, exists := registry[name]
+	r, exists := registry[name]
 	if !exists {
 		return nil, fmt.Errorf("未找到名为 '%s' 的解析器", name)
 	}
-	return p, nil
+	return r.parser, nil
+}
+
+// List 返回所有可用解析器 (包括通用解析器) 的描述，按名称排序
+func List() []Info {
+	infos := make([]Info, 0, len(registry)+1)
+	infos = append(infos, genericInfo)
+	for _, r := range registry {
+		infos = append(infos, r.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Lookup 返回指定解析器的描述
+func Lookup(name string) (Info, bool) {
+	if name == GenericParserType {
+		return genericInfo, true
+	}
+	r, exists := registry[name]
+	return r.info, exists
 }
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"github.com/src-hunter/internal/model"
 )
@@ -10,7 +12,11 @@ type SubfinderParser struct{}
 
 func init() {
 	// 对应 ScanProfile 中的 "output_parser_type"
-	Register("subfinder_json_list", &SubfinderParser{})
+	Register("subfinder_json_list", &SubfinderParser{}, Info{
+		Description: "subfinder 子域名枚举结果",
+		InputFormat: "JSON行 (subfinder -json) 或JSON数组",
+		Entities:    []string{EntityDomain},
+	})
 }
 
 // subfinderOutputLine 是 subfinder -json 输出的每一行结构
@@ -19,19 +25,33 @@ type subfinderOutputLine struct {
 	Source string `json:"source"`
 }
 
-// Parse 实现了 Parser 接口，现在可以直接解析一个包含多个对象的JSON数组
+// Parse 实现了 Parser 接口，支持 subfinder -json 的逐行输出，也兼容整理成JSON数组的输出
 func (p *SubfinderParser) Parse(output []byte) (*ParseResult, error) {
 	var lines []subfinderOutputLine
-	// --- 核心修正点：直接将整个JSON数组反序列化到一个结构体切片中 ---
-	if err := json.Unmarshal(output, &lines); err != nil {
-		return nil, err
+	trimmed := bytes.TrimSpace(output)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &lines); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			var line subfinderOutputLine
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
 	}
 
 	var domains []model.Domain
 	for _, line := range lines {
-		if line.Host != "" {
+		if host := normalizeDomain(line.Host); host != "" {
 			domains = append(domains, model.Domain{
-				FQDN:   line.Host,
+				FQDN:   host,
 				Source: line.Source,
 			})
 		}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/src-hunter/internal/model"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TlsxParser 负责解析 tlsx 的JSON行输出 (-json)
+type TlsxParser struct{}
+
+func init() {
+	Register("tlsx_json", &TlsxParser{}, Info{
+		Description: "tlsx 证书信息，生成证书和提供证书的资产，SAN 中的主机名生成域名",
+		InputFormat: "JSON行 (tlsx -json)",
+		Entities:    []string{EntityCertificate, EntityAsset, EntityDomain, EntityRelationship},
+	})
+}
+
+// tlsxOutputLine 对应 tlsx 的一行输出，port 在不同版本中可能是字符串或数字
+type tlsxOutputLine struct {
+	Host            string          `json:"host"`
+	IP              string          `json:"ip"`
+	Port            json.RawMessage `json:"port"`
+	ProbeStatus     *bool           `json:"probe_status"`
+	SNI             string          `json:"sni"`
+	SubjectCN       string          `json:"subject_cn"`
+	SubjectDN       string          `json:"subject_dn"`
+	SubjectAN       []string        `json:"subject_an"`
+	IssuerCN        string          `json:"issuer_cn"`
+	IssuerDN        string          `json:"issuer_dn"`
+	Serial          string          `json:"serial"`
+	NotBefore       time.Time       `json:"not_before"`
+	NotAfter        time.Time       `json:"not_after"`
+	SelfSigned      bool            `json:"self_signed"`
+	Mismatched      bool            `json:"mismatched"`
+	FingerprintHash struct {
+		SHA256 string `json:"sha256"`
+	} `json:"fingerprint_hash"`
+}
+
+// Parse 实现了 Parser 接口：每行生成一条证书和提供证书的资产；
+// SAN 和 CN 中的主机名生成规范化的域名 (通配符去掉 "*." 前缀)，是否入库由项目范围决定。
+func (p *TlsxParser) Parse(output []byte) (*ParseResult, error) {
+	result := &ParseResult{}
+	seenDomains := make(map[string]bool)
+	seenAssets := make(map[string]bool)
+	addDomain := func(name string) {
+		name = normalizeDomain(name)
+		if name == "" || seenDomains[name] {
+			return
+		}
+		seenDomains[name] = true
+		result.Domains = append(result.Domains, model.Domain{FQDN: name, Source: "tlsx"})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line tlsxOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		if line.ProbeStatus != nil && !*line.ProbeStatus {
+			continue
+		}
+		port, _ := strconv.Atoi(strings.Trim(string(line.Port), `"`))
+		ip := normalizeIP(line.IP)
+		if ip == "" {
+			ip = normalizeIP(line.Host)
+		}
+		if ip == "" || port <= 0 || line.FingerprintHash.SHA256 == "" {
+			continue
+		}
+
+		// 优先使用 SNI 作为证书应匹配的主机名
+		host := normalizeDNSName(line.SNI)
+		if host == "" {
+			host = normalizeDNSName(line.Host)
+		}
+		names := append([]string{line.SubjectCN}, line.SubjectAN...)
+		mismatched := line.Mismatched
+		if host != "" && net.ParseIP(host) == nil && !certificateMatches(host, names) {
+			mismatched = true
+		}
+
+		result.Certificates = append(result.Certificates, model.Certificate{
+			IP:                ip,
+			Port:              port,
+			FingerprintSHA256: strings.ToLower(line.FingerprintHash.SHA256),
+			Host:              host,
+			SubjectCN:         line.SubjectCN,
+			SubjectDN:         line.SubjectDN,
+			SANs:              line.SubjectAN,
+			IssuerCN:          line.IssuerCN,
+			IssuerDN:          line.IssuerDN,
+			Serial:            line.Serial,
+			NotBefore:         line.NotBefore,
+			NotAfter:          line.NotAfter,
+			IsSelfSigned:      line.SelfSigned || (line.IssuerDN != "" && line.IssuerDN == line.SubjectDN),
+			IsMismatched:      mismatched,
+		})
+
+		key := AssetKey(ip, port, model.TransportTCP)
+		if !seenAssets[key] {
+			seenAssets[key] = true
+			result.Assets = append(result.Assets, model.Asset{IP: ip, Port: port, Transport: model.TransportTCP, Source: "tlsx"})
+		}
+		for _, name := range names {
+			addDomain(name)
+		}
+		if domain := normalizeDomain(host); domain != "" {
+			addDomain(domain)
+			result.Relate(AssetRef(ip, port, model.TransportTCP), DomainRef(domain))
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// certificateMatches 判断主机名是否匹配证书中的某个名称，通配符只匹配一级标签
+func certificateMatches(host string, names []string) bool {
+	for _, name := range names {
+		name = normalizeDNSName(name)
+		if name == host {
+			return true
+		}
+		if strings.HasPrefix(name, "*.") {
+			suffix := name[1:]
+			if strings.HasSuffix(host, suffix) && !strings.Contains(strings.TrimSuffix(host, suffix), ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package parser
+
+import "testing"
+
+func TestTlsxParser(t *testing.T) {
+	result, err := (&TlsxParser{}).Parse(readTestdata(t, "tlsx.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"192.0.2.40:443/tcp", "192.0.2.41:8443/tcp"}; !equalStrings(assetKeys(result), want) {
+		t.Errorf("assets = %v, want %v", assetKeys(result), want)
+	}
+
+	var domains []string
+	for _, d := range result.Domains {
+		domains = append(domains, d.FQDN)
+	}
+	if want := []string{"example.com", "www.example.com", "deep.api.example.com"}; !equalStrings(domains, want) {
+		t.Errorf("domains = %v, want %v", domains, want)
+	}
+
+	tests := []struct {
+		ip          string
+		port        int
+		host        string
+		fingerprint string
+		selfSigned  bool
+		mismatched  bool
+	}{
+		{"192.0.2.40", 443, "www.example.com", "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789", false, false},
+		// 通配符只匹配一级标签
+		{"192.0.2.40", 443, "deep.api.example.com", "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789", false, true},
+		// 按IP访问时不判断主机名是否匹配
+		{"192.0.2.41", 8443, "192.0.2.41", "1111111111111111111111111111111111111111111111111111111111111111", true, false},
+	}
+	if len(result.Certificates) != len(tests) {
+		t.Fatalf("certificates = %+v", result.Certificates)
+	}
+	for i, tt := range tests {
+		c := result.Certificates[i]
+		if c.IP != tt.ip || c.Port != tt.port || c.Host != tt.host || c.FingerprintSHA256 != tt.fingerprint ||
+			c.IsSelfSigned != tt.selfSigned || c.IsMismatched != tt.mismatched {
+			t.Errorf("certificate[%d] = %s:%d host=%s fp=%s self=%v mismatched=%v, want %+v", i,
+				c.IP, c.Port, c.Host, c.FingerprintSHA256, c.IsSelfSigned, c.IsMismatched, tt)
+		}
+	}
+
+	rels := []string{"192.0.2.40:443/tcp www.example.com", "192.0.2.40:443/tcp deep.api.example.com"}
+	if len(result.Relationships) != len(rels) {
+		t.Fatalf("relationships = %+v", result.Relationships)
+	}
+	for i, rel := range result.Relationships {
+		if got := rel.From.Key + " " + rel.To.Key; got != rels[i] {
+			t.Errorf("relationship[%d] = %s, want %s", i, got, rels[i])
+		}
+	}
+}
+
+func TestCertificateMatches(t *testing.T) {
+	names := []string{"example.com", "*.example.com", "WWW.Example.org."}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true},
+		{"a.b.example.com", false},
+		{"www.example.org", true},
+		{"example.org", false},
+		{"notexample.com", false},
+	}
+	for _, tt := range tests {
+		if got := certificateMatches(tt.host, names); got != tt.want {
+			t.Errorf("certificateMatches(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
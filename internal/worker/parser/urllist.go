@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// URLListParser 负责解析每行一个URL的输出，适用于 gau、waybackurls 等历史URL工具
+type URLListParser struct {
+	Source string // 写入端点的发现来源
+}
+
+func init() {
+	Register("gau_list", &URLListParser{Source: "gau"}, Info{
+		Description: "gau 收集的历史URL，规范化并按参数名去重",
+		InputFormat: "每行一个URL",
+		Entities:    []string{EntityEndpoint},
+	})
+	Register("waybackurls_list", &URLListParser{Source: "waybackurls"}, Info{
+		Description: "waybackurls 收集的历史URL，规范化并按参数名去重",
+		InputFormat: "每行一个URL",
+		Entities:    []string{EntityEndpoint},
+	})
+}
+
+// Parse 实现了 Parser 接口，无效的行和非 http(s) 的URL会被忽略
+func (p *URLListParser) Parse(output []byte) (*ParseResult, error) {
+	set := newEndpointSet()
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if e, ok := newEndpoint(scanner.Text(), "GET", p.Source); ok {
+			set.add(e)
+		}
+	}
+	return &ParseResult{Endpoints: set.endpoints}, scanner.Err()
+}
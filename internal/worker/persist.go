@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/worker/parser"
+)
+
+// normalizeOutput 将命令的原始输出整理为可以存入 jsonb 列的合法JSON：
+// 本身是合法JSON时原样保存；每个非空行都是JSON时 (如 -json 逐行输出) 合并为JSON数组；
+// 其他格式 (如 nmap 的XML) 保存为JSON字符串。
+func normalizeOutput(stdout []byte) model.JSONB {
+	trimmed := bytes.TrimSpace(stdout)
+	if len(trimmed) == 0 {
+		return model.JSONB("[]")
+	}
+	if json.Valid(trimmed) {
+		return model.JSONB(trimmed)
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	allJSON := true
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			allJSON = false
+			break
+		}
+		lines = append(lines, append([]byte(nil), line...))
+	}
+	if allJSON && scanner.Err() == nil {
+		joined := bytes.Join(lines, []byte(","))
+		return model.JSONB(append(append([]byte{'['}, joined...), ']'))
+	}
+
+	raw, _ := json.Marshal(string(stdout))
+	return model.JSONB(raw)
+}
+
+// outputEntities 生成包含已入库域名和资产 (带ID) 的输出：域名在前，资产在后。
+// 按域名扇出时资产条目没有 FQDN 会被跳过，按 IP:端口 扇出时域名条目同理。
+func outputEntities(result *parser.ParseResult) model.JSONB {
+	entities := make([]interface{}, 0, len(result.Domains)+len(result.Assets))
+	for _, d := range result.Domains {
+		entities = append(entities, d)
+	}
+	for _, a := range result.Assets {
+		entities = append(entities, a)
+	}
+	data, _ := json.Marshal(entities)
+	return model.JSONB(data)
+}
@@ -1,12 +1,16 @@
 package worker
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/enrich"
+	"github.com/src-hunter/internal/ingest"
 	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/notify"
+	"github.com/src-hunter/internal/scope"
+	"github.com/src-hunter/internal/tagging"
 	"github.com/src-hunter/internal/worker/parser"
 	"github.com/src-hunter/pkg/logger"
 	"go.uber.org/zap"
@@ -21,6 +25,10 @@ type TaskProcessor struct {
 	DB          *gorm.DB
 	AsynqClient *asynq.Client
 	Executor    Executor
+	Notifier    *notify.Publisher
+	Ingest      *ingest.Service
+	// Enricher 为新保存的资产补充IP归属信息，为空时跳过
+	Enricher *enrich.Enricher
 }
 
 func NewTaskProcessor(db *gorm.DB, client *asynq.Client) *TaskProcessor {
@@ -28,6 +36,8 @@ func NewTaskProcessor(db *gorm.DB, client *asynq.Client) *TaskProcessor {
 		DB:          db,
 		AsynqClient: client,
 		Executor:    NewLocalExecutor(),
+		Notifier:    notify.NewPublisher(db, client),
+		Ingest:      ingest.NewService(db),
 	}
 }
 
@@ -38,6 +48,8 @@ type Payload struct {
 	ScanProfileID   uint   `json:"scan_profile_id"`
 	CurrentStepName string `json:"current_step_name"`
 	Input           string `json:"input"`
+	// TargetID 是该输入最初来源的项目目标ID，会沿工作流向下传递，用于按目标归因结果
+	TargetID uint `json:"target_id,omitempty"`
 }
 
 func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) error {
@@ -55,6 +67,16 @@ func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) e
 		return fmt.Errorf("解析任务载荷失败: %v", err)
 	}
 
+	// 项目已被删除或正在删除时，丢弃剩余的工作流任务，避免重新写入数据
+	runnable, err := p.isProjectRunnable(payload.ProjectID)
+	if err != nil {
+		return fmt.Errorf("查找项目ID %d 失败: %w", payload.ProjectID, err)
+	}
+	if !runnable {
+		logger.Logger.Warn("项目不存在或正在删除，跳过任务", zap.Uint("project_id", payload.ProjectID))
+		return nil
+	}
+
 	var profile model.ScanProfile
 	if err := p.DB.First(&profile, payload.ScanProfileID).Error; err != nil {
 		return fmt.Errorf("查找扫描模板ID %d 失败: %w", payload.ScanProfileID, err)
@@ -65,13 +87,14 @@ func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) e
 	}
 
 	childTask := model.Task{
-		ProjectID:     payload.ProjectID,
-		ParentTaskID:  payload.ParentTaskID,
-		ScanProfileID: payload.ScanProfileID,
-		Status:        "running",
-		Type:          t.Type(),
-		WorkflowStep:  payload.CurrentStepName,
-		StartedAt:     time.Now(),
+		ProjectID:       payload.ProjectID,
+		ParentTaskID:    payload.ParentTaskID,
+		ScanProfileID:   payload.ScanProfileID,
+		Status:          "running",
+		Type:            t.Type(),
+		WorkflowStep:    payload.CurrentStepName,
+		ProjectTargetID: payload.TargetID,
+		StartedAt:       time.Now(),
 	}
 	if err := p.DB.Create(&childTask).Error; err != nil {
 		return fmt.Errorf("创建子任务数据库记录失败: %w", err)
@@ -98,25 +121,12 @@ func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) e
 		return p.failTask(&childTask, errorMsg)
 	}
 
-	// 准备输出记录，但先不保存
+	// 准备输出记录，但先不保存。输出列为 jsonb，需要先整理为合法的JSON
 	outputRecord := model.TaskOutput{
 		TaskID:       childTask.ID,
 		ParentTaskID: payload.ParentTaskID,
 		OutputType:   step.OutputParserType,
-		Data:         model.JSONB(cmdResult.Stdout), // 默认使用原始输出
-	}
-
-	// 如果是 subfinder，将其输出格式化为合法的 JSON 数组
-	if step.OutputParserType == "subfinder_json_list" {
-		lines := bytes.Split(bytes.TrimSpace(cmdResult.Stdout), []byte("\n"))
-		var nonEmptyLines [][]byte
-		for _, line := range lines {
-			if len(bytes.TrimSpace(line)) > 0 {
-				nonEmptyLines = append(nonEmptyLines, line)
-			}
-		}
-		joined := bytes.Join(nonEmptyLines, []byte(","))
-		outputRecord.Data = model.JSONB(append(append([]byte{'['}, joined...), ']'))
+		Data:         normalizeOutput(cmdResult.Stdout),
 	}
 
 	// 保存格式化后的输出结果
@@ -125,97 +135,70 @@ func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) e
 	}
 
 	if step.OutputParserType != "" {
-		registeredParser, err := parser.Get(step.OutputParserType)
+		registeredParser, err := parser.ForStep(&step)
 		if err != nil {
-			p.DB.Model(&childTask).Update("result", fmt.Sprintf("警告：找不到解析器 %s", step.OutputParserType))
+			p.DB.Model(&childTask).Update("result", fmt.Sprintf("警告：无法使用解析器 %s: %v", step.OutputParserType, err))
 		} else {
-			//确保解析器处理的是格式化后的数据
-			parseResult, err := registeredParser.Parse(outputRecord.Data)
+			// 解析器处理命令的原始输出 (JSON行、XML等)，而不是为存储整理过的数据
+			parseResult, err := registeredParser.Parse(cmdResult.Stdout)
 			if err != nil {
 				return p.failTask(&childTask, fmt.Sprintf("使用解析器 '%s' 解析输出失败: %v", step.OutputParserType, err))
 			}
 
-			// --- 数据持久化逻辑 ---
+			// --- 范围检查：持久化前移除超出项目范围的域名和资产 ---
+			projectScope, err := scope.Load(p.DB, childTask.ProjectID)
+			if err != nil {
+				return p.failTask(&childTask, fmt.Sprintf("加载项目范围失败: %v", err))
+			}
+			viaDomain := ""
+			if payload.DomainID != 0 {
+				viaDomain = payload.Input
+			}
+			p.filterParseResult(&childTask, projectScope, parseResult, viaDomain)
+			p.filterWildcardDomains(&childTask, parseResult)
 
-			// 1. 处理域名 (Domains)
-			if len(parseResult.Domains) > 0 {
-				for i := range parseResult.Domains {
-					parseResult.Domains[i].ProjectID = childTask.ProjectID
-					parseResult.Domains[i].LastSeenAt = time.Now()
-					if step.InputFrom == "initial" {
-						parseResult.Domains[i].RootDomain = payload.Input
-					}
-				}
-				// 为了获取新创建域名的ID，我们需要先将它们插入数据库
-				p.DB.Clauses(clause.OnConflict{
-					Columns:   []clause.Column{{Name: "project_id"}, {Name: "fqdn"}},
-					DoNothing: true,
-				}).Create(&parseResult.Domains)
-
-				// 重新查询，以确保GORM将数据库生成的ID填充回模型切片中
-				var fqdns []string
-				for _, d := range parseResult.Domains {
-					fqdns = append(fqdns, d.FQDN)
-				}
-				p.DB.Where("project_id = ? AND fqdn IN ?", childTask.ProjectID, fqdns).Find(&parseResult.Domains)
+			// --- 数据持久化逻辑：在一个事务中保存全部实体，失败时整体回滚 ---
+			opts := ingest.Options{ViaDomainID: payload.DomainID}
+			summary, err := p.Ingest.Ingest(&childTask, opts, parseResult)
+			if err != nil {
+				return p.failTask(&childTask, fmt.Sprintf("保存解析结果失败: %v", err))
+			}
+			childTask.Result = summary.String()
 
-				// 将带有ID的域名列表重新序列化，作为下一步的输入
-				updatedDataBytes, _ := json.Marshal(parseResult.Domains)
-				outputRecord.Data = updatedDataBytes // 覆盖旧的输出
+			// 补充资产IP的归属信息，失败不影响工作流
+			if err := p.Enricher.EnrichAssets(parseResult.Assets); err != nil {
+				logger.Logger.Warn("补充资产IP信息失败", zap.Uint("task_id", childTask.ID), zap.Error(err))
 			}
 
-			// 2. 处理资产 (Assets)
-			if len(parseResult.Assets) > 0 {
-				for i := range parseResult.Assets {
-					parseResult.Assets[i].ProjectID = childTask.ProjectID
-					parseResult.Assets[i].LastSeenAt = time.Now()
-				}
-				// 批量插入/更新资产
-				conflictColumns := []clause.Column{{Name: "project_id"}, {Name: "ip"}, {Name: "port"}}
-				updateColumns := []string{"last_seen_at", "title", "web_server", "technologies", "updated_at"}
-				if err := p.DB.Clauses(clause.OnConflict{
-					Columns:   conflictColumns,
-					DoUpdates: clause.AssignmentColumns(updateColumns),
-				}).Create(&parseResult.Assets).Error; err != nil {
-					logger.Logger.Error("批量保存资产记录失败", zap.Error(err))
-				}
+			// 按项目的自动打标签规则为本次入库的实体打标签，失败不影响工作流
+			if tagged, err := tagging.Apply(p.DB, childTask.ProjectID, parseResult); err != nil {
+				logger.Logger.Warn("自动打标签失败", zap.Uint("task_id", childTask.ID), zap.Error(err))
+			} else if tagged > 0 {
+				logger.Logger.Info("已自动打标签", zap.Uint("task_id", childTask.ID), zap.Int64("count", tagged))
+			}
 
-				// 3. 处理资产与域名的关联 (AssetDomainMapping)
-				if payload.DomainID != 0 {
-					// 重新查询刚创建/更新的资产，以获取它们的ID
-					var createdOrUpdatedAssets []model.Asset
-					var ips []string
-					for _, a := range parseResult.Assets {
-						ips = append(ips, a.IP)
-					}
-					p.DB.Where("project_id = ? AND ip IN ?", childTask.ProjectID, ips).Find(&createdOrUpdatedAssets)
-
-					// 批量创建关联
-					var mappings []model.AssetDomainMapping
-					for _, asset := range createdOrUpdatedAssets {
-						mappings = append(mappings, model.AssetDomainMapping{
-							AssetID:  asset.ID,
-							DomainID: payload.DomainID,
-						})
-					}
-					if len(mappings) > 0 {
-						p.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mappings)
-					}
-				}
+			// 将带有ID的域名和资产作为下一步的输入，覆盖原始输出
+			if len(parseResult.Domains) > 0 || len(parseResult.Assets) > 0 {
+				outputRecord.Data = outputEntities(parseResult)
 			}
+
+			// 记录本次运行的观测快照，用于运行间的变化检测
+			p.recordObservations(&childTask, parseResult)
 		}
 	}
 
-	fannedOut, err := p.triggerNextStep(&childTask, &payload, &profile, &outputRecord)
+	dispatched, err := p.triggerNextStep(&childTask, &payload, &profile, &outputRecord)
 	if err != nil {
 		return p.failTask(&childTask, fmt.Sprintf("触发下一步任务失败: %v", err))
 	}
 
-	if fannedOut {
-		childTask.Status = "success"
-		childTask.Result = "已成功派发所有并行子任务"
-		childTask.FinishedAt = time.Now()
-		return p.DB.Save(&childTask).Error
+	// 已派发后续任务时不递减父任务的计数，由后续任务接替当前分支。
+	// 只更新状态相关的列：并行子任务可能已经开始递减当前任务的 pending_subtasks，整行保存会覆盖它
+	switch dispatched {
+	case dispatchFanOut:
+		return p.markTaskSucceeded(&childTask, withIngestSummary("已成功派发所有并行子任务", childTask.Result))
+	case dispatchLinear:
+		return p.markTaskSucceeded(&childTask, withIngestSummary("步骤成功完成，已派发下一步", childTask.Result))
 	}
 
 	if err := p.finalizeParallelSubtask(&childTask); err != nil {
@@ -225,97 +208,123 @@ func (p *TaskProcessor) HandleWorkflowTask(ctx context.Context, t *asynq.Task) e
 	return nil
 }
 
+// markTaskSucceeded 把任务标记为成功，不覆盖其他列
+func (p *TaskProcessor) markTaskSucceeded(task *model.Task, result string) error {
+	task.Status = "success"
+	task.Result = result
+	task.FinishedAt = time.Now()
+	return p.DB.Model(task).Updates(map[string]interface{}{
+		"status":      task.Status,
+		"result":      task.Result,
+		"finished_at": task.FinishedAt,
+	}).Error
+}
+
+// withIngestSummary 在任务结果后附上本次入库的新增/更新汇总
+func withIngestSummary(message, summary string) string {
+	if summary == "" {
+		return message
+	}
+	return message + "；" + summary
+}
+
 func (p *TaskProcessor) failTask(task *model.Task, reason string) error {
 	task.Status = "failed"
 	task.Result = reason
 	task.FinishedAt = time.Now()
 	p.DB.Save(task)
+	p.failWorkflow(task, reason)
 	return fmt.Errorf("task failed: %s", reason)
 }
 
+// finalizeParallelSubtask 在一个分支结束 (当前任务没有再派发后续任务) 时把任务标记为成功，并释放它在父任务中的计数
 func (p *TaskProcessor) finalizeParallelSubtask(childTask *model.Task) error {
 	if childTask.ParentTaskID == 0 {
-		// 对于没有父任务的线性任务，如果能走到这里，说明它可能是最后一步
-		// 它的状态将由 triggerNextStep 在判断无下一步时处理
+		// 不属于任何工作流的独立任务，没有需要释放的计数
 		return nil
 	}
+	if err := p.markTaskSucceeded(childTask, withIngestSummary("并行子任务成功完成", childTask.Result)); err != nil {
+		return err
+	}
+	return p.releaseParent(childTask)
+}
 
+// releaseParent 递减父任务的待完成计数。计数归零时，父任务是工作流顶级任务则完成工作流，
+// 否则父任务是一个扇出任务，对它执行扇入
+func (p *TaskProcessor) releaseParent(task *model.Task) error {
 	var parentTask model.Task
-	var remaining int64
+	var remaining int
+	decremented := false
 
 	err := p.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parentTask, childTask.ParentTaskID).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parentTask, task.ParentTaskID).Error; err != nil {
 			return err
 		}
 		if parentTask.PendingSubtasks <= 0 {
+			// 计数已经归零 (如重复投递的任务)，扇入已经发生过，不能再次触发
 			return nil
 		}
-		parentTask.PendingSubtasks--
-		remaining = int64(parentTask.PendingSubtasks)
-		return tx.Save(&parentTask).Error
+		remaining = parentTask.PendingSubtasks - 1
+		decremented = true
+		return tx.Model(&parentTask).Update("pending_subtasks", remaining).Error
 	})
-
-	if err != nil {
+	if err != nil || !decremented || remaining > 0 {
 		return err
 	}
 
-	childTask.Status = "success"
-	childTask.Result = "并行子任务成功完成"
-	childTask.FinishedAt = time.Now()
-	p.DB.Save(childTask)
-
-	if remaining == 0 {
-		logger.Logger.Info("所有并行子任务已全部完成", zap.Uint("fanOutTaskId", parentTask.ID))
-		parentTask.Status = "success"
-		parentTask.FinishedAt = time.Now()
-		p.DB.Save(&parentTask)
+	if parentTask.ParentTaskID == 0 {
+		// 计数归零的是工作流顶级任务，说明所有分支都已结束
+		return p.completeWorkflow(&parentTask)
+	}
+	return p.fanIn(&parentTask, task.WorkflowStep)
+}
 
-		var profile model.ScanProfile
-		if err := p.DB.First(&profile, parentTask.ScanProfileID).Error; err != nil {
-			return p.failTask(&parentTask, fmt.Sprintf("扇入失败：找不到扫描模板ID %d", parentTask.ScanProfileID))
-		}
+// fanIn 在扇出任务的所有子任务完成后，继续执行被扇出步骤 (fannedStep) 之后的步骤。
+// 派发了后续任务时由它接替扇出任务在其父任务中的计数，否则这个分支到此结束
+func (p *TaskProcessor) fanIn(fanOutTask *model.Task, fannedStep string) error {
+	logger.Logger.Info("所有并行子任务已全部完成", zap.Uint("fanOutTaskId", fanOutTask.ID))
 
-		// 检查这个已完成的扇出任务之后是否还有步骤
-		_, ok := findNextStep(profile.WorkflowSteps, parentTask.WorkflowStep)
-		if !ok {
-			// 如果没有下一步，说明工作流的这个分支已经结束，需要检查是否整个工作流都完成了
-			var ultimateParentTask = parentTask
-			for ultimateParentTask.ParentTaskID != 0 {
-				if err := p.DB.First(&ultimateParentTask, ultimateParentTask.ParentTaskID).Error; err != nil {
-					return p.failTask(&parentTask, fmt.Sprintf("扇入失败：查找顶级父任务时出错: %v", err))
-				}
-			}
-			logger.Logger.Info("工作流已成功完成", zap.Uint("workflowTaskId", ultimateParentTask.ID))
-			ultimateParentTask.Status = "completed"
-			ultimateParentTask.Result = "工作流成功完成"
-			ultimateParentTask.FinishedAt = time.Now()
-			return p.DB.Save(&ultimateParentTask).Error
-		}
+	var profile model.ScanProfile
+	if err := p.DB.First(&profile, fanOutTask.ScanProfileID).Error; err != nil {
+		return p.failTask(fanOutTask, fmt.Sprintf("扇入失败：找不到扫描模板ID %d", fanOutTask.ScanProfileID))
+	}
 
-		// 如果还有下一步，则触发
-		parentPayload := Payload{
-			ProjectID:       parentTask.ProjectID,
-			ParentTaskID:    parentTask.ParentTaskID,
-			ScanProfileID:   parentTask.ScanProfileID,
-			CurrentStepName: parentTask.WorkflowStep,
-		}
+	payload := Payload{
+		ProjectID:       fanOutTask.ProjectID,
+		ParentTaskID:    fanOutTask.ParentTaskID,
+		ScanProfileID:   fanOutTask.ScanProfileID,
+		CurrentStepName: fannedStep,
+		TargetID:        fanOutTask.ProjectTargetID,
+	}
 
-		// 扇入后的下一步，其输入是所有子任务结果的聚合，这是一个复杂逻辑
-		// 目前我们用一个伪输出触发，意味着下一步必须从数据库自行拉取所有结果
-		var pseudoOutput model.TaskOutput
-		_, err := p.triggerNextStep(&parentTask, &parentPayload, &profile, &pseudoOutput)
+	// 扇入后的下一步，其输入是所有子任务结果的聚合，这是一个复杂逻辑
+	// 目前我们用一个伪输出触发，意味着下一步必须从数据库自行拉取所有结果
+	var pseudoOutput model.TaskOutput
+	dispatched, err := p.triggerNextStep(fanOutTask, &payload, &profile, &pseudoOutput)
+	if err != nil {
 		return err
 	}
-
-	return nil
+	if dispatched != dispatchNone {
+		return nil
+	}
+	return p.releaseParent(fanOutTask)
 }
 
-func (p *TaskProcessor) triggerNextStep(currentTask *model.Task, currentPayload *Payload, profile *model.ScanProfile, taskOutput *model.TaskOutput) (bool, error) {
+// dispatch 表示 triggerNextStep 为当前任务派发的后续任务
+type dispatch int
+
+const (
+	dispatchNone   dispatch = iota // 没有后续任务，当前分支到此结束
+	dispatchLinear                 // 派发了线性的下一步
+	dispatchFanOut                 // 扇出了并行子任务
+)
+
+func (p *TaskProcessor) triggerNextStep(currentTask *model.Task, currentPayload *Payload, profile *model.ScanProfile, taskOutput *model.TaskOutput) (dispatch, error) {
 	nextStep, ok := findNextStep(profile.WorkflowSteps, currentPayload.CurrentStepName)
 	if !ok {
 		// 没有下一步，这意味着当前任务是工作流的终点
-		// 我们将在 finalizeParallelSubtask 或 HandleWorkflowTask 的主逻辑中处理它的最终状态
-		return false, nil
+		// 我们将在 finalizeParallelSubtask 中释放它在父任务中的计数
+		return dispatchNone, nil
 	}
 
 	// 模式一：下一步是并行（扇出）
@@ -323,40 +332,67 @@ func (p *TaskProcessor) triggerNextStep(currentTask *model.Task, currentPayload
 		var results []map[string]interface{}
 		if taskOutput != nil && taskOutput.Data != nil {
 			if err := json.Unmarshal(taskOutput.Data, &results); err == nil && len(results) > 0 {
-				p.DB.Model(currentTask).Update("pending_subtasks", len(results))
+				projectScope, err := scope.Load(p.DB, currentPayload.ProjectID)
+				if err != nil {
+					return dispatchNone, err
+				}
 
+				// 先筛选出有效且在范围内的条目，扇出数量以筛选后的为准
+				var items []fanOutItem
+				var dropped []model.OutOfScopeItem
+				seen := make(map[string]bool)
 				for _, itemMap := range results {
-					// --- 核心修正点在这里 ---
-					// 使用与 model.Domain 序列化后一致的、正确的字段名 "FQDN" 和 "ID"
-					host, _ := itemMap["FQDN"].(string)
-					domainID := uint(0)
-					if idVal, ok := itemMap["ID"].(float64); ok { // JSON 数字默认为 float64
-						domainID = uint(idVal)
+					item, decision, ok := extractFanOutItem(itemMap, nextStep.FanOutKey, projectScope)
+					if !ok || seen[item.host] {
+						continue
 					}
-					// --- 修正结束 ---
-
-					if host == "" {
+					if !decision.InScope {
+						dropped = append(dropped, newOutOfScopeItem(currentTask, "input", item.host, decision.Reason))
 						continue
 					}
+					seen[item.host] = true
+					items = append(items, item)
+				}
+				p.recordOutOfScope(dropped)
+				if nextStep.SkipCDN && len(items) > 0 {
+					total := len(items)
+					if items, err = p.filterCDNItems(currentPayload.ProjectID, items); err != nil {
+						return dispatchNone, err
+					}
+					if skipped := total - len(items); skipped > 0 {
+						logger.Logger.Info("已跳过接入CDN的主机",
+							zap.Uint("task_id", currentTask.ID),
+							zap.String("next_step", nextStep.Name),
+							zap.Int("count", skipped),
+						)
+					}
+				}
+				if len(items) == 0 {
+					return dispatchNone, nil // 没有可供扇出的结果
+				}
 
+				p.DB.Model(currentTask).Update("pending_subtasks", len(items))
+
+				for _, item := range items {
 					nextPayloadBytes, _ := json.Marshal(Payload{
 						ProjectID:       currentPayload.ProjectID,
-						DomainID:        domainID,
+						DomainID:        item.domainID,
 						ParentTaskID:    currentTask.ID,
 						ScanProfileID:   profile.ID,
 						CurrentStepName: nextStep.Name,
-						Input:           host,
+						Input:           item.host,
+						TargetID:        currentPayload.TargetID,
 					})
 
 					task := asynq.NewTask(nextStep.TaskType, nextPayloadBytes)
 					if _, err := p.AsynqClient.Enqueue(task); err != nil {
-						return true, err
+						return dispatchFanOut, err
 					}
 				}
-				return true, nil // 成功扇出
+				return dispatchFanOut, nil // 成功扇出
 			}
 		}
-		return false, nil // 没有可供扇出的结果
+		return dispatchNone, nil // 没有可供扇出的结果
 	}
 
 	// 模式二：下一步是线性任务
@@ -366,11 +402,14 @@ func (p *TaskProcessor) triggerNextStep(currentTask *model.Task, currentPayload
 		ScanProfileID:   profile.ID,
 		CurrentStepName: nextStep.Name,
 		Input:           "", // 线性任务的输入由下一步的 getInputForTask 从数据库获取
+		TargetID:        currentPayload.TargetID,
 	})
 
 	task := asynq.NewTask(nextStep.TaskType, nextPayloadBytes)
-	_, err := p.AsynqClient.Enqueue(task)
-	return false, err
+	if _, err := p.AsynqClient.Enqueue(task); err != nil {
+		return dispatchNone, err
+	}
+	return dispatchLinear, nil
 }
 
 func (p *TaskProcessor) getInputForTask(payload *Payload, step *model.WorkflowStep) (interface{}, error) {
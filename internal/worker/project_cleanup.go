@@ -0,0 +1,223 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// deleteBatchSize 是级联删除时每批删除的行数，避免长时间锁表
+const deleteBatchSize = 1000
+
+// ProjectDeletePayload 是项目级联删除任务的载荷
+type ProjectDeletePayload struct {
+	ProjectID uint `json:"project_id"`
+	TaskID    uint `json:"task_id"` // 用于记录进度的 Task 记录ID
+}
+
+// projectDeleteProgress 会被序列化到 Task.Result 中，供 API 查询进度
+type projectDeleteProgress struct {
+	Stage   string           `json:"stage"`
+	Deleted map[string]int64 `json:"deleted"`
+}
+
+// cleanupStage 描述了级联删除中的一个阶段，按顺序执行以保证不会残留孤儿数据
+type cleanupStage struct {
+	name string
+	// sql 每次至多删除 @batch 行，可用命名参数 @project、@task、@batch
+	sql string
+}
+
+var projectCleanupStages = []cleanupStage{
+	{
+		name: "asset_domain_mappings",
+		sql: `DELETE FROM asset_domain_mappings WHERE (asset_id, domain_id) IN (
+			SELECT m.asset_id, m.domain_id FROM asset_domain_mappings m
+			JOIN assets a ON a.id = m.asset_id WHERE a.project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "task_outputs",
+		sql: `DELETE FROM task_outputs WHERE id IN (
+			SELECT o.id FROM task_outputs o
+			JOIN tasks t ON t.id = o.task_id WHERE t.project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "certificates",
+		sql:  `DELETE FROM certificates WHERE id IN (SELECT id FROM certificates WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "dns_records",
+		sql:  `DELETE FROM dns_records WHERE id IN (SELECT id FROM dns_records WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "endpoints",
+		sql:  `DELETE FROM endpoints WHERE id IN (SELECT id FROM endpoints WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "findings",
+		sql:  `DELETE FROM findings WHERE id IN (SELECT id FROM findings WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "observations",
+		sql:  `DELETE FROM observations WHERE id IN (SELECT id FROM observations WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "taggings",
+		sql:  `DELETE FROM taggings WHERE id IN (SELECT id FROM taggings WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "tag_rules",
+		sql:  `DELETE FROM tag_rules WHERE id IN (SELECT id FROM tag_rules WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "tags",
+		sql:  `DELETE FROM tags WHERE id IN (SELECT id FROM tags WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "notes",
+		sql:  `DELETE FROM notes WHERE id IN (SELECT id FROM notes WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "tasks",
+		sql:  `DELETE FROM tasks WHERE id IN (SELECT id FROM tasks WHERE project_id = @project AND id <> @task LIMIT @batch)`,
+	},
+	{
+		name: "assets",
+		sql:  `DELETE FROM assets WHERE id IN (SELECT id FROM assets WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "domains",
+		sql:  `DELETE FROM domains WHERE id IN (SELECT id FROM domains WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "webhook_deliveries",
+		sql:  `DELETE FROM webhook_deliveries WHERE id IN (SELECT id FROM webhook_deliveries WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "webhook_subscriptions",
+		sql:  `DELETE FROM webhook_subscriptions WHERE id IN (SELECT id FROM webhook_subscriptions WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "chat_digest_entries",
+		sql:  `DELETE FROM chat_digest_entries WHERE id IN (SELECT id FROM chat_digest_entries WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "chat_channels",
+		sql:  `DELETE FROM chat_channels WHERE id IN (SELECT id FROM chat_channels WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "scan_schedules",
+		sql:  `DELETE FROM scan_schedules WHERE id IN (SELECT id FROM scan_schedules WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "out_of_scope_items",
+		sql:  `DELETE FROM out_of_scope_items WHERE id IN (SELECT id FROM out_of_scope_items WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "scope_rules",
+		sql:  `DELETE FROM scope_rules WHERE id IN (SELECT id FROM scope_rules WHERE project_id = @project LIMIT @batch)`,
+	},
+	{
+		name: "project_targets",
+		sql:  `DELETE FROM project_targets WHERE id IN (SELECT id FROM project_targets WHERE project_id = @project LIMIT @batch)`,
+	},
+}
+
+// HandleProjectDeleteTask 分批级联删除一个项目及其所有目标、域名、资产、关联、任务和输出
+func (p *TaskProcessor) HandleProjectDeleteTask(ctx context.Context, t *asynq.Task) error {
+	var payload ProjectDeletePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("解析任务载荷失败: %v: %w", err, asynq.SkipRetry)
+	}
+	logger.Logger.Info("开始级联删除项目", zap.Uint("project_id", payload.ProjectID), zap.Uint("task_id", payload.TaskID))
+
+	var task model.Task
+	if err := p.DB.First(&task, payload.TaskID).Error; err != nil {
+		return fmt.Errorf("查找删除任务记录 %d 失败: %w", payload.TaskID, err)
+	}
+
+	// 只删除仍处于删除中的项目。项目已不存在说明上一次执行已删除项目但未能记录结果
+	var project model.Project
+	if err := p.DB.Select("id", "status").First(&project, payload.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			p.DB.Model(&task).Updates(map[string]interface{}{"status": "success", "finished_at": time.Now()})
+			return nil
+		}
+		return fmt.Errorf("查找项目 %d 失败: %w", payload.ProjectID, err)
+	}
+	if project.Status != model.ProjectStatusDeleting {
+		err := p.failTask(&task, fmt.Sprintf("项目状态为 %s，不再处于删除中", project.Status))
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+
+	task.Status = "running"
+	task.StartedAt = time.Now()
+	p.DB.Save(&task)
+
+	progress := projectDeleteProgress{Deleted: make(map[string]int64)}
+	for _, stage := range projectCleanupStages {
+		progress.Stage = stage.name
+		for {
+			if err := ctx.Err(); err != nil {
+				// 任务被取消或超时，已删除的部分不会恢复，重试时会从剩余数据继续。
+				// 先记为失败，重试次数用尽后可以重新发起删除
+				p.failTask(&task, fmt.Sprintf("删除 %s 时中断: %v", stage.name, err))
+				return err
+			}
+			result := p.DB.Exec(stage.sql, map[string]interface{}{
+				"project": payload.ProjectID,
+				"task":    payload.TaskID,
+				"batch":   deleteBatchSize,
+			})
+			if result.Error != nil {
+				return p.failTask(&task, fmt.Sprintf("删除 %s 失败: %v", stage.name, result.Error))
+			}
+			progress.Deleted[stage.name] += result.RowsAffected
+			p.saveDeleteProgress(&task, progress)
+			if result.RowsAffected < deleteBatchSize {
+				break
+			}
+		}
+	}
+
+	// 最后删除项目本身
+	progress.Stage = "project"
+	if err := p.DB.Unscoped().Delete(&model.Project{}, payload.ProjectID).Error; err != nil {
+		return p.failTask(&task, fmt.Sprintf("删除项目失败: %v", err))
+	}
+	progress.Stage = "done"
+	p.saveDeleteProgress(&task, progress)
+
+	task.Status = "success"
+	task.FinishedAt = time.Now()
+	p.DB.Model(&task).Updates(map[string]interface{}{"status": task.Status, "finished_at": task.FinishedAt})
+	logger.Logger.Info("项目级联删除完成", zap.Uint("project_id", payload.ProjectID), zap.Any("deleted", progress.Deleted))
+	return nil
+}
+
+func (p *TaskProcessor) saveDeleteProgress(task *model.Task, progress projectDeleteProgress) {
+	data, _ := json.Marshal(progress)
+	task.Result = string(data)
+	if err := p.DB.Model(task).Update("result", task.Result).Error; err != nil {
+		logger.Logger.Warn("更新删除进度失败", zap.Uint("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// isProjectRunnable 判断项目是否仍然可以继续执行工作流 (项目存在且不在删除中)
+func (p *TaskProcessor) isProjectRunnable(projectID uint) (bool, error) {
+	var project model.Project
+	if err := p.DB.Select("id", "status").First(&project, projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return project.Status != model.ProjectStatusDeleting, nil
+}
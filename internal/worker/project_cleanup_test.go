@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/testutil"
+	"gorm.io/gorm"
+)
+
+// newDeleteTask 创建一个指定状态的项目及其删除任务记录，返回对应的 asynq 任务
+func newDeleteTask(t *testing.T, db *gorm.DB, status string) (model.Project, model.Task, *asynq.Task) {
+	t.Helper()
+	project := model.Project{Name: "cleanup-test-" + strconv.FormatInt(time.Now().UnixNano(), 10), Status: status}
+	if err := db.Create(&project).Error; err != nil {
+		t.Fatal(err)
+	}
+	task := model.Task{ProjectID: project.ID, Type: "maintenance:project:delete", Status: "pending"}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(ProjectDeletePayload{ProjectID: project.ID, TaskID: task.ID})
+	return project, task, asynq.NewTask(task.Type, payload)
+}
+
+func TestProjectDeleteRemovesDeletingProject(t *testing.T) {
+	db := testutil.DB(t)
+	project, task, asynqTask := newDeleteTask(t, db, model.ProjectStatusDeleting)
+	if err := db.Create(&model.ProjectTarget{ProjectID: project.ID, Value: "example.com", Type: "domain"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	p := &TaskProcessor{DB: db}
+	if err := p.HandleProjectDeleteTask(context.Background(), asynqTask); err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	db.Unscoped().Model(&model.Project{}).Where("id = ?", project.ID).Count(&n)
+	if n != 0 {
+		t.Error("项目应已被删除")
+	}
+	db.Unscoped().Model(&model.ProjectTarget{}).Where("project_id = ?", project.ID).Count(&n)
+	if n != 0 {
+		t.Errorf("残留目标 %d 个", n)
+	}
+	db.First(&task, task.ID)
+	if task.Status != "success" {
+		t.Errorf("删除任务状态 = %s, want success", task.Status)
+	}
+
+	// 项目已删除后的重复执行直接记为成功
+	if err := p.HandleProjectDeleteTask(context.Background(), asynqTask); err != nil {
+		t.Fatalf("重复执行: %v", err)
+	}
+}
+
+func TestProjectDeleteSkipsProjectNotDeleting(t *testing.T) {
+	db := testutil.DB(t)
+	project, task, asynqTask := newDeleteTask(t, db, model.ProjectStatusActive)
+	if err := db.Create(&model.ProjectTarget{ProjectID: project.ID, Value: "example.com", Type: "domain"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	p := &TaskProcessor{DB: db}
+	err := p.HandleProjectDeleteTask(context.Background(), asynqTask)
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Fatalf("err = %v, want SkipRetry", err)
+	}
+	var n int64
+	db.Model(&model.ProjectTarget{}).Where("project_id = ?", project.ID).Count(&n)
+	if n != 1 {
+		t.Error("项目不在删除中时不应删除任何数据")
+	}
+	db.First(&task, task.ID)
+	if task.Status != "failed" {
+		t.Errorf("删除任务状态 = %s, want failed", task.Status)
+	}
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"fmt"
+	"github.com/src-hunter/internal/model"
+	"github.com/src-hunter/internal/scope"
+	"github.com/src-hunter/internal/worker/parser"
+	"github.com/src-hunter/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// filterParseResult 从解析结果中移除超出项目范围的域名、资产、漏洞发现、Web端点和证书，并记录被拦截的条目。
+// viaDomain 为本任务输入的域名 (如 httpx 对某个域名的探测)，用于判定经由范围内域名解析到的IP。
+func (p *TaskProcessor) filterParseResult(task *model.Task, sc *scope.Scope, result *parser.ParseResult, viaDomain string) {
+	if !sc.Enabled() {
+		return
+	}
+	var dropped []model.OutOfScopeItem
+
+	domains := result.Domains[:0]
+	for _, d := range result.Domains {
+		if decision := sc.CheckHost(d.FQDN); !decision.InScope {
+			dropped = append(dropped, newOutOfScopeItem(task, "domain", d.FQDN, decision.Reason))
+			continue
+		}
+		domains = append(domains, d)
+	}
+	result.Domains = domains
+
+	// 域名被拦截时，其解析记录一并丢弃
+	if len(result.DNSRecords) > 0 {
+		kept := make(map[string]bool, len(domains))
+		for _, d := range domains {
+			kept[d.FQDN] = true
+		}
+		records := result.DNSRecords[:0]
+		for _, r := range result.DNSRecords {
+			if kept[r.FQDN] {
+				records = append(records, r)
+			}
+		}
+		result.DNSRecords = records
+	}
+
+	assets := result.Assets[:0]
+	for _, a := range result.Assets {
+		if decision := sc.CheckAsset(a.IP, a.Port, viaDomain); !decision.InScope {
+			dropped = append(dropped, newOutOfScopeItem(task, "asset", fmt.Sprintf("%s:%d", a.IP, a.Port), decision.Reason))
+			continue
+		}
+		assets = append(assets, a)
+	}
+	result.Assets = assets
+
+	findings := result.Findings[:0]
+	for _, f := range result.Findings {
+		// 目标是域名时，经由该域名解析到的IP视为在范围内
+		decision := sc.CheckHost(f.Host)
+		if f.IP != "" {
+			via := viaDomain
+			if f.Host != f.IP {
+				via = f.Host
+			}
+			decision = sc.CheckAsset(f.IP, f.Port, via)
+		}
+		if !decision.InScope {
+			dropped = append(dropped, newOutOfScopeItem(task, "finding", f.MatchedAt, decision.Reason))
+			continue
+		}
+		findings = append(findings, f)
+	}
+	result.Findings = findings
+
+	endpoints := result.Endpoints[:0]
+	for _, e := range result.Endpoints {
+		if decision := sc.CheckHost(e.Host); !decision.InScope {
+			dropped = append(dropped, newOutOfScopeItem(task, "endpoint", e.URL, decision.Reason))
+			continue
+		}
+		endpoints = append(endpoints, e)
+	}
+	result.Endpoints = endpoints
+
+	certificates := result.Certificates[:0]
+	for _, cert := range result.Certificates {
+		if decision := sc.CheckAsset(cert.IP, cert.Port, viaDomain); !decision.InScope {
+			dropped = append(dropped, newOutOfScopeItem(task, "certificate", fmt.Sprintf("%s:%d", cert.IP, cert.Port), decision.Reason))
+			continue
+		}
+		certificates = append(certificates, cert)
+	}
+	result.Certificates = certificates
+
+	p.recordOutOfScope(dropped)
+}
+
+func newOutOfScopeItem(task *model.Task, kind, value, reason string) model.OutOfScopeItem {
+	return model.OutOfScopeItem{
+		ProjectID:    task.ProjectID,
+		TaskID:       task.ID,
+		ParentTaskID: task.ParentTaskID,
+		Kind:         kind,
+		Value:        value,
+		Reason:       reason,
+	}
+}
+
+// recordOutOfScope 保存被拦截的条目，保存失败只记录日志，不影响工作流
+func (p *TaskProcessor) recordOutOfScope(items []model.OutOfScopeItem) {
+	if len(items) == 0 {
+		return
+	}
+	logger.Logger.Info("已拦截超出项目范围的条目",
+		zap.Uint("project_id", items[0].ProjectID),
+		zap.Uint("task_id", items[0].TaskID),
+		zap.Int("count", len(items)),
+	)
+	if err := p.DB.CreateInBatches(&items, 500).Error; err != nil {
+		logger.Logger.Error("保存范围外条目失败", zap.Error(err))
+	}
+}
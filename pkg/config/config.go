@@ -6,10 +6,12 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Logger   LoggerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
+	Server    ServerConfig
+	Logger    LoggerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	Scheduler SchedulerConfig
+	Enrich    EnrichConfig
 }
 
 type ServerConfig struct {
@@ -41,6 +43,19 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+type SchedulerConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	PollInterval int  `mapstructure:"poll_interval"` // 检查到期计划的间隔 (秒)
+}
+
+// EnrichConfig 是资产IP信息补充的配置，数据库文件不存在时跳过对应的补充
+type EnrichConfig struct {
+	ASNDatabase string `mapstructure:"asn_database"` // MaxMind 格式的ASN数据库 (e.g., GeoLite2-ASN.mmdb)
+	GeoDatabase string `mapstructure:"geo_database"` // MaxMind 格式的城市或国家数据库 (e.g., GeoLite2-City.mmdb)
+	IPRanges    string `mapstructure:"ip_ranges"`    // 本地CDN/云服务商IP段列表
+	TTLHours    int    `mapstructure:"ttl_hours"`    // 已补充信息的有效期 (小时)
+}
+
 // 全局配置变量
 var Cfg *Config
 